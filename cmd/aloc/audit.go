@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/modern-tooling/aloc/internal/model"
+	"github.com/modern-tooling/aloc/internal/renderer"
+	jsonrenderer "github.com/modern-tooling/aloc/internal/renderer/json"
+	"github.com/modern-tooling/aloc/internal/renderer/tui"
+	"github.com/modern-tooling/aloc/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditThresholdFlag   float32
+	auditInteractiveFlag bool
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit [path]",
+	Short: "List uncertain classifications and propose overrides",
+	Long: `audit lists every file whose confidence is below --threshold, or whose
+top two roles were nearly tied, grouped by directory. Each group comes with an
+overrides: snippet for aloc.yaml that pins its files to a suggested role: the
+file's current role, or the role of a clear majority of confidently
+classified siblings when the file had no evidence or nearly tied with it.
+
+With --interactive, each suggestion is accepted or rejected in turn and the
+accepted ones are written into the config file (-c, or aloc.yaml in the
+scanned root).`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runAudit,
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.Flags().Float32Var(&auditThresholdFlag, "threshold", 0.80, "List files below this confidence")
+	auditCmd.Flags().BoolVarP(&auditInteractiveFlag, "interactive", "i", false, "Accept or reject each suggestion and write accepted ones to the config file")
+	auditCmd.Flags().StringVarP(&formatFlag, "format", "f", "tui", "Output format (tui, json)")
+	auditCmd.Flags().BoolVar(&noColorFlag, "no-color", false, "Disable colors")
+	auditCmd.Flags().BoolVar(&prettyFlag, "pretty", false, "Pretty-print JSON output")
+	addScanFlags(auditCmd)
+}
+
+func runAudit(cmd *cobra.Command, args []string) error {
+	if auditInteractiveFlag && formatFlag == "json" {
+		return fmt.Errorf("--interactive needs the tui format")
+	}
+
+	root := "."
+	if len(args) > 0 {
+		root = args[0]
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+
+	files, _, engine, err := loadAndScan(context.Background(), absRoot)
+	if err != nil {
+		return err
+	}
+	audit := engine.Audit(files, auditThresholdFlag)
+	warnUnmatchedOverrides(engine)
+
+	opts := renderer.Options{
+		Writer:  os.Stdout,
+		NoColor: noColorFlag || renderer.ShouldDisableColor(),
+		Pretty:  prettyFlag,
+	}
+	if formatFlag == "json" {
+		return jsonrenderer.NewJSONRenderer(opts).RenderAudit(audit)
+	}
+
+	theme := renderer.NewDefaultTheme()
+	if opts.NoColor {
+		theme = renderer.NewNoColorTheme()
+	}
+	fmt.Fprint(opts.Writer, tui.RenderAuditSummary(audit, theme))
+
+	if auditInteractiveFlag {
+		return reviewAudit(audit, absRoot, os.Stdin, opts.Writer, theme)
+	}
+	for _, group := range audit.Groups {
+		fmt.Fprintln(opts.Writer)
+		fmt.Fprint(opts.Writer, tui.RenderAuditGroup(group, theme))
+		fmt.Fprint(opts.Writer, theme.Dim.Render(config.OverridesSnippet(suggestedOverrides(group.Suggestions...))))
+	}
+	return nil
+}
+
+// reviewAudit asks about each suggestion in turn and writes the accepted
+// ones into the config file
+func reviewAudit(audit *model.Audit, absRoot string, in io.Reader, out io.Writer, theme *renderer.Theme) error {
+	var accepted []model.OverrideSuggestion
+	answers := bufio.NewScanner(in)
+
+review:
+	for _, group := range audit.Groups {
+		fmt.Fprintln(out)
+		fmt.Fprint(out, tui.RenderAuditGroup(group, theme))
+		for _, s := range group.Suggestions {
+			fmt.Fprint(out, theme.Dim.Render(config.OverridesSnippet(suggestedOverrides(s))))
+			role := string(s.Role)
+			if s.SubRole != "" {
+				role += "/" + string(s.SubRole)
+			}
+			fmt.Fprintf(out, "Accept %s for %s (%s)? [y/N/q] ", role, pluralize(s.Files, "1 file", fmt.Sprintf("%d files", s.Files)), s.Basis)
+			if !answers.Scan() {
+				fmt.Fprintln(out)
+				break review
+			}
+			switch strings.ToLower(strings.TrimSpace(answers.Text())) {
+			case "y", "yes":
+				accepted = append(accepted, s)
+			case "q", "quit":
+				break review
+			}
+		}
+	}
+
+	if len(accepted) == 0 {
+		fmt.Fprintln(out, "No overrides accepted.")
+		return nil
+	}
+	path := configFlag
+	if path == "" {
+		path = config.Find(absRoot)
+	}
+	if path == "" {
+		path = filepath.Join(absRoot, "aloc.yaml")
+	}
+	if err := config.AddOverrides(path, suggestedOverrides(accepted...)); err != nil {
+		return fmt.Errorf("config error: %w", err)
+	}
+	fmt.Fprintf(out, "Wrote %s to %s\n", pluralize(len(accepted), "1 override", fmt.Sprintf("%d overrides", len(accepted))), path)
+	return nil
+}
+
+// suggestedOverrides turns suggestions into config override entries
+func suggestedOverrides(suggestions ...model.OverrideSuggestion) []config.Override {
+	var overrides []config.Override
+	for _, s := range suggestions {
+		for _, p := range s.Patterns {
+			overrides = append(overrides, config.Override{Pattern: p, Role: s.Role, SubRole: s.SubRole})
+		}
+	}
+	return overrides
+}
+
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
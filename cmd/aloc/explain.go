@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/modern-tooling/aloc/internal/renderer"
+	jsonrenderer "github.com/modern-tooling/aloc/internal/renderer/json"
+	"github.com/modern-tooling/aloc/internal/renderer/tui"
+	"github.com/spf13/cobra"
+)
+
+var explainRootFlag string
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <path>...",
+	Short: "Show why files were classified into their roles",
+	Long: `explain traces the classification of one or more files: every rule
+that fired with its weight, the evidence per role, the ambiguity penalty and
+agreement factor, override and neighborhood effects, and the final role and
+confidence.
+
+The repository root (--root) is scanned in full so that paths, overrides and
+neighborhood inference match a regular run.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runExplain,
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+	explainCmd.Flags().StringVar(&explainRootFlag, "root", ".", "Repository root the paths belong to")
+	explainCmd.Flags().StringVarP(&formatFlag, "format", "f", "tui", "Output format (tui, json)")
+	explainCmd.Flags().BoolVar(&noColorFlag, "no-color", false, "Disable colors")
+	explainCmd.Flags().BoolVar(&prettyFlag, "pretty", false, "Pretty-print JSON output")
+	addScanFlags(explainCmd)
+}
+
+func runExplain(cmd *cobra.Command, args []string) error {
+	absRoot, err := filepath.Abs(explainRootFlag)
+	if err != nil {
+		return fmt.Errorf("invalid root: %w", err)
+	}
+
+	// Paths are relative to the working directory, like any other CLI path
+	paths := make([]string, len(args))
+	for i, arg := range args {
+		abs, err := filepath.Abs(arg)
+		if err != nil {
+			return fmt.Errorf("invalid path: %w", err)
+		}
+		rel, err := filepath.Rel(absRoot, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("%s is outside the root %s (set --root)", arg, absRoot)
+		}
+		paths[i] = rel
+	}
+
+	files, _, engine, err := loadAndScan(context.Background(), absRoot)
+	if err != nil {
+		return err
+	}
+
+	explanations := engine.Explain(files, paths)
+	if len(explanations) < len(paths) {
+		found := make(map[string]bool, len(explanations))
+		for _, ex := range explanations {
+			found[ex.Path] = true
+		}
+		var missing []string
+		for _, p := range paths {
+			if !found[p] {
+				missing = append(missing, p)
+			}
+		}
+		return fmt.Errorf("not scanned (excluded, ignored, unknown type or over the size limit): %s",
+			strings.Join(missing, ", "))
+	}
+
+	opts := renderer.Options{
+		Writer:  os.Stdout,
+		NoColor: noColorFlag || renderer.ShouldDisableColor(),
+		Pretty:  prettyFlag,
+	}
+
+	if formatFlag == "json" {
+		return jsonrenderer.NewJSONRenderer(opts).RenderExplanations(explanations)
+	}
+
+	theme := renderer.NewDefaultTheme()
+	if opts.NoColor {
+		theme = renderer.NewNoColorTheme()
+	}
+	sections := make([]string, len(explanations))
+	for i, ex := range explanations {
+		sections[i] = tui.RenderExplanation(ex, theme)
+	}
+	_, err = fmt.Fprintln(opts.Writer, strings.Join(sections, "\n"))
+	return err
+}
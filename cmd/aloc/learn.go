@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/modern-tooling/aloc/internal/inference"
+	"github.com/modern-tooling/aloc/internal/model"
+	"github.com/modern-tooling/aloc/internal/renderer"
+	"github.com/modern-tooling/aloc/internal/renderer/tui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	learnLabelsFlag        string
+	learnAuditFlag         string
+	learnFromOverridesFlag bool
+	learnOutFlag           string
+)
+
+var learnCmd = &cobra.Command{
+	Use:   "learn [path]",
+	Short: "Fit rule weights to labeled files",
+	Long: `learn fits the weights of the built-in rules to files whose role you know,
+and proposes directory rules for conventions the built-ins miss (tests in
+checks/, say). Labels come from any mix of:
+
+  --from-overrides  the role each config override assigns
+  --audit FILE      the suggested roles in aloc audit -f json output
+  --labels FILE     a CSV of path,role (slash-separated paths, relative to the root)
+
+Later sources win for a path listed more than once. The fit is a logistic
+regression over the rules that fire on each labeled file; overrides are
+ignored while fitting. The weights are written to --out, or the config's
+options.weights, or aloc-weights.json in the scanned root. Load them with
+options.weights in aloc.yaml or --weights.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runLearn,
+}
+
+func init() {
+	rootCmd.AddCommand(learnCmd)
+	learnCmd.Flags().StringVar(&learnLabelsFlag, "labels", "", "CSV file of path,role labels")
+	learnCmd.Flags().StringVar(&learnAuditFlag, "audit", "", "aloc audit JSON output; each file's suggested role is its label")
+	learnCmd.Flags().BoolVar(&learnFromOverridesFlag, "from-overrides", false, "Label files with the role of the override that matches them")
+	learnCmd.Flags().StringVarP(&learnOutFlag, "out", "o", "", "Weights file to write (default: options.weights or aloc-weights.json)")
+	learnCmd.Flags().BoolVar(&noColorFlag, "no-color", false, "Disable colors")
+	addScanFlags(learnCmd)
+	learnCmd.Flags().Lookup("weights").Usage = "Weights to start from (default: options.weights)"
+}
+
+func runLearn(cmd *cobra.Command, args []string) error {
+	if !learnFromOverridesFlag && learnAuditFlag == "" && learnLabelsFlag == "" {
+		return fmt.Errorf("no labels: use --from-overrides, --audit or --labels")
+	}
+
+	root := "."
+	if len(args) > 0 {
+		root = args[0]
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+
+	files, _, engine, err := loadAndScan(context.Background(), absRoot)
+	if err != nil {
+		return err
+	}
+
+	labels := make(map[string]model.Role)
+	if learnFromOverridesFlag {
+		for path, role := range engine.OverrideLabels(files) {
+			labels[path] = role
+		}
+	}
+	if learnAuditFlag != "" {
+		if err := readAuditLabels(learnAuditFlag, labels); err != nil {
+			return fmt.Errorf("audit labels: %w", err)
+		}
+	}
+	if learnLabelsFlag != "" {
+		f, err := os.Open(learnLabelsFlag)
+		if err != nil {
+			return fmt.Errorf("labels: %w", err)
+		}
+		err = readCSVLabels(f, labels)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("labels %s: %w", learnLabelsFlag, err)
+		}
+	}
+
+	weights, summary, err := engine.Learn(files, labels, inference.DefaultLearnOptions())
+	if err != nil {
+		return err
+	}
+
+	out := learnOutFlag
+	if out == "" {
+		out = learnedWeightsPath(absRoot)
+	}
+	if err := weights.Write(out); err != nil {
+		return fmt.Errorf("writing weights: %w", err)
+	}
+
+	theme := renderer.NewDefaultTheme()
+	if noColorFlag || renderer.ShouldDisableColor() {
+		theme = renderer.NewNoColorTheme()
+	}
+	fmt.Fprint(os.Stdout, tui.RenderLearnSummary(summary, theme))
+	fmt.Fprintf(os.Stdout, "\nWrote %s\n", out)
+	if skipped := len(labels) - summary.Examples; skipped > 0 {
+		fmt.Fprintf(os.Stderr, "warning: %s not found in the scan\n", pluralize(skipped, "1 labeled path was", fmt.Sprintf("%d labeled paths were", skipped)))
+	}
+	return nil
+}
+
+// learnedWeightsPath is where learn writes by default: the weights file in
+// use, else aloc-weights.json in the scanned root
+func learnedWeightsPath(absRoot string) string {
+	var configured string
+	if cfg, err := loadConfig(absRoot); err == nil {
+		configured = cfg.Options.Weights
+	}
+	if path := weightsPath(absRoot, configured); path != "" {
+		return path
+	}
+	return filepath.Join(absRoot, "aloc-weights.json")
+}
+
+// readAuditLabels labels each file of an aloc audit JSON report with its
+// suggested role
+func readAuditLabels(path string, labels map[string]model.Role) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var audit model.Audit
+	if err := json.Unmarshal(data, &audit); err != nil {
+		return err
+	}
+	for _, group := range audit.Groups {
+		for _, f := range group.Files {
+			role := f.Suggested
+			if role == "" {
+				role = f.Role
+			}
+			labels[filepath.FromSlash(f.Path)] = role
+		}
+	}
+	return nil
+}
+
+// readCSVLabels reads path,role rows. A header row, blank lines and lines
+// starting with # are skipped; a role may carry a sub-role ("test/e2e"),
+// which is ignored.
+func readCSVLabels(r io.Reader, labels map[string]model.Role) error {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = 2
+	cr.TrimLeadingSpace = true
+	for first := true; ; first = false {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		path := strings.TrimSpace(record[0])
+		roleName, _, _ := strings.Cut(strings.TrimSpace(record[1]), "/")
+		role := model.Role(strings.ToLower(roleName))
+		if first && strings.EqualFold(path, "path") && role == "role" {
+			continue
+		}
+		if !slices.Contains(model.AllRoles, role) {
+			line, _ := cr.FieldPos(1)
+			return fmt.Errorf("line %d: unknown role %q", line, record[1])
+		}
+		labels[filepath.Clean(filepath.FromSlash(path))] = role
+	}
+}
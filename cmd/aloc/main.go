@@ -6,8 +6,11 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"sort"
 
 	"github.com/modern-tooling/aloc/internal/aggregator"
+	"github.com/modern-tooling/aloc/internal/coverage"
 	"github.com/modern-tooling/aloc/internal/effort"
 	"github.com/modern-tooling/aloc/internal/git"
 	"github.com/modern-tooling/aloc/internal/inference"
@@ -44,10 +47,17 @@ var (
 	gitFlag            bool
 	gitMonthsFlag      int
 	gitSmoothFlag      bool
+	staleMonthsFlag    int
+	inactiveMonthsFlag int
 	modelConfigFlag    string
 	profileFlag        string
 	engineerFlag       bool
 	engineerMonthsFlag int
+	maxFileSizeFlag    int64
+	goPreciseFlag      bool
+	vendorFlag         bool
+	weightsFlag        string
+	coverageFlag       string
 )
 
 var rootCmd = &cobra.Command{
@@ -58,6 +68,7 @@ var rootCmd = &cobra.Command{
 
 Quick mode (default): Scans only files with known source extensions.
 Deep mode (--deep): Also analyzes extensionless files and probes headers.
+Go files are parsed only with --go-precise.
 
 Output includes responsibility breakdown, key ratios, and
 language composition with Tufte-inspired visualization.`,
@@ -83,9 +94,7 @@ func init() {
 	rootCmd.Flags().BoolVar(&noColorFlag, "no-color", false, "Disable colors")
 	rootCmd.Flags().BoolVar(&filesFlag, "files", false, "Include file-level details in output")
 	rootCmd.Flags().BoolVar(&prettyFlag, "pretty", false, "Pretty-print JSON output")
-	rootCmd.Flags().BoolVar(&headerProbeFlag, "header-probe", false, "Enable header content probing")
-	rootCmd.Flags().BoolVar(&deepFlag, "deep", false, "Enable expensive analysis (header probing, extensionless files)")
-	rootCmd.Flags().StringVarP(&configFlag, "config", "c", "", "Config file path")
+	addScanFlags(rootCmd)
 	rootCmd.Flags().BoolVar(&effortFlag, "effort", true, "Include effort estimates (human and AI cost)")
 	rootCmd.Flags().BoolVar(&noEffortFlag, "no-effort", false, "Disable effort estimates")
 	rootCmd.Flags().StringVar(&aiModelFlag, "ai-model", "sonnet", "AI model for cost estimation (sonnet, opus, haiku)")
@@ -94,10 +103,24 @@ func init() {
 	rootCmd.Flags().BoolVar(&gitFlag, "git", false, "Enable git history analysis for churn and stability signals")
 	rootCmd.Flags().IntVar(&gitMonthsFlag, "git-months", 6, "Months of history for sparklines")
 	rootCmd.Flags().BoolVar(&gitSmoothFlag, "git-smooth", false, "Use bi-weekly buckets instead of weekly for smoother sparklines")
+	rootCmd.Flags().IntVar(&staleMonthsFlag, "stale-months", 12, "Months without a commit after which files count as abandoned (with --git)")
+	rootCmd.Flags().IntVar(&inactiveMonthsFlag, "inactive-months", 6, "Months without a commit after which a file's only author counts as inactive (with --git)")
 	rootCmd.Flags().StringVar(&modelConfigFlag, "model-config", "", "Path to JSON file with effort model configuration overrides")
 	rootCmd.Flags().StringVar(&profileFlag, "profile", "faang", "Effort estimation profile (faang)")
 	rootCmd.Flags().BoolVar(&engineerFlag, "engineer", false, "Show engineer throughput analysis (replaces standard output)")
 	rootCmd.Flags().IntVar(&engineerMonthsFlag, "engineer-months", 6, "Months of history for engineer analysis")
+	rootCmd.Flags().StringVar(&coverageFlag, "coverage", "", "Coverage report to join with the classification (Go coverprofile, LCOV, Cobertura or JaCoCo XML)")
+}
+
+// addScanFlags registers the flags loadAndScan reads on a command that scans
+func addScanFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&headerProbeFlag, "header-probe", false, "Enable header content probing")
+	cmd.Flags().BoolVar(&deepFlag, "deep", false, "Enable expensive analysis (header probing, extensionless files)")
+	cmd.Flags().BoolVar(&goPreciseFlag, "go-precise", false, "Parse Go files for generated markers, build tags and test function kinds")
+	cmd.Flags().StringVarP(&configFlag, "config", "c", "", "Config file path")
+	cmd.Flags().StringVar(&weightsFlag, "weights", "", "Rule weights file written by aloc learn (overrides options.weights)")
+	cmd.Flags().Int64Var(&maxFileSizeFlag, "max-file-size", 0, "Skip files larger than this many bytes (0 = use config, no limit by default)")
+	cmd.Flags().BoolVar(&vendorFlag, "vendor", false, "Count vendor directories as vendored code instead of skipping them")
 }
 
 func main() {
@@ -141,54 +164,23 @@ func run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid path: %w", err)
 	}
 
-	// Load config
-	var cfg *config.Config
-	if configFlag != "" {
-		cfg, err = config.Load(configFlag)
-	} else {
-		cfg, err = config.LoadFromDir(absRoot)
-	}
-	if err != nil {
-		return fmt.Errorf("config error: %w", err)
+	// Read the coverage report before scanning so a bad path fails fast
+	var coverageProfile *coverage.Profile
+	if coverageFlag != "" {
+		coverageProfile, err = coverage.Load(coverageFlag)
+		if err != nil {
+			return fmt.Errorf("coverage error: %w", err)
+		}
 	}
 
-	// Create scanner
-	s, err := scanner.NewScanner(absRoot, scanner.Options{
-		NumWorkers: runtime.NumCPU() * 2,
-		Exclude:    cfg.Exclude,
-		DeepMode:   deepFlag,
-	})
+	files, diagnostics, engine, err := loadAndScan(ctx, absRoot)
 	if err != nil {
-		return fmt.Errorf("scanner error: %w", err)
-	}
-
-	// Scan files
-	rawFiles, errs := s.Scan(ctx)
-
-	// Collect files
-	var files []*model.RawFile
-	for f := range rawFiles {
-		files = append(files, f)
-	}
-
-	// Log errors (non-fatal)
-	for err := range errs {
-		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
-	}
-
-	if len(files) == 0 {
-		return fmt.Errorf("no files found in %s", absRoot)
+		return err
 	}
 
-	// Create inference engine
-	engine := inference.NewEngine(inference.Options{
-		HeaderProbe:  deepFlag || headerProbeFlag || cfg.Options.HeaderProbe,
-		Neighborhood: cfg.Options.Neighborhood,
-		Overrides:    cfg.Overrides,
-	})
-
 	// Infer roles
 	records := engine.InferBatch(files)
+	warnUnmatchedOverrides(engine)
 
 	// Determine if effort should be included (default true, unless --no-effort)
 	includeEffort := effortFlag && !noEffortFlag
@@ -216,10 +208,16 @@ func run(cmd *cobra.Command, args []string) error {
 			StabilityMonths: 18,
 			Smooth:          gitSmoothFlag,
 		},
+		StaleOpts: git.StaleOptions{
+			StaleMonths:    staleMonthsFlag,
+			InactiveMonths: inactiveMonthsFlag,
+		},
 		EngineerAnalysis: engineerFlag,
 		EngineerOpts: git.EngineerOptions{
 			PeriodMonths: engineerMonthsFlag,
 		},
+		Diagnostics: diagnostics,
+		Coverage:    coverageProfile,
 	})
 
 	// Select renderer
@@ -246,6 +244,331 @@ func run(cmd *cobra.Command, args []string) error {
 	return r.Render(report)
 }
 
+// loadAndScan loads the config for absRoot, registers its languages, scans
+// the tree and builds the inference engine
+func loadAndScan(ctx context.Context, absRoot string) ([]*model.RawFile, []model.Diagnostic, *inference.Engine, error) {
+	cfg, err := loadConfig(absRoot)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("config error: %w", err)
+	}
+
+	// Register user-defined languages before scanning so detection and
+	// quick-mode filtering see them
+	if err := registerLanguages(cfg.Languages); err != nil {
+		return nil, nil, nil, fmt.Errorf("config error: %w", err)
+	}
+
+	// Register custom roles before overrides and rules that assign them
+	if err := registerRoles(cfg.Roles); err != nil {
+		return nil, nil, nil, fmt.Errorf("config error: %w", err)
+	}
+
+	overrides, err := buildOverrides(cfg.Overrides)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("config error: %w", err)
+	}
+
+	// Flag takes precedence over config for the size limit
+	maxFileSize := cfg.Options.MaxFileSize
+	if maxFileSizeFlag > 0 {
+		maxFileSize = maxFileSizeFlag
+	}
+
+	customRules, disabledRules, err := buildRules(cfg.Rules)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("config error: %w", err)
+	}
+	testPairs, err := buildTestPairs(cfg.TestPairs)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("config error: %w", err)
+	}
+	headerProbe := deepFlag || headerProbeFlag || cfg.Options.HeaderProbe
+	goPrecise := goPreciseFlag || cfg.Options.GoPrecise
+
+	// Keep file headers from the counting pass when inference reads content,
+	// so files are opened once
+	headerSize := 0
+	if headerProbe || goPrecise || len(cfg.Rules.Header) > 0 {
+		headerSize = inference.HeaderBytes
+	}
+
+	// vendor/ is excluded by default; scanning it counts it as vendored code
+	scanVendor := vendorFlag || cfg.Options.ScanVendor
+	exclude := cfg.Exclude
+	if scanVendor {
+		exclude = slices.DeleteFunc(slices.Clone(exclude), func(p string) bool { return p == "vendor/**" })
+	}
+
+	// Create scanner
+	s, err := scanner.NewScanner(absRoot, scanner.Options{
+		NumWorkers:  runtime.NumCPU() * 2,
+		Exclude:     exclude,
+		DeepMode:    deepFlag,
+		MaxFileSize: maxFileSize,
+		HeaderSize:  headerSize,
+		ScanVendor:  scanVendor,
+		LanguageFor: inference.NewOverrides(overrides).Language,
+
+		DataHeaderSize: inference.DataHeaderBytes,
+	})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("scanner error: %w", err)
+	}
+
+	// Scan files
+	rawFiles, diagCh := s.Scan(ctx)
+
+	// Collect diagnostics concurrently so a full channel never stalls workers
+	var diagnostics []model.Diagnostic
+	diagDone := make(chan struct{})
+	go func() {
+		defer close(diagDone)
+		for d := range diagCh {
+			diagnostics = append(diagnostics, d)
+		}
+	}()
+
+	// Collect files
+	var files []*model.RawFile
+	for f := range rawFiles {
+		files = append(files, f)
+	}
+	<-diagDone
+
+	if len(files) == 0 {
+		return nil, nil, nil, fmt.Errorf("no files found in %s", absRoot)
+	}
+
+	// Create inference engine
+	neighborhood, err := buildNeighborhoodWeights(cfg.Options.NeighborhoodWeights)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("config error: %w", err)
+	}
+	weights, err := loadWeights(absRoot, cfg.Options.Weights)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("config error: %w", err)
+	}
+	engine := inference.NewEngine(inference.Options{
+		HeaderProbe:         headerProbe,
+		Neighborhood:        cfg.Options.Neighborhood,
+		NeighborhoodWeights: &neighborhood,
+		Overrides:           overrides,
+		Rules:               customRules,
+		DisabledRules:       disabledRules,
+		GoPrecise:           goPrecise,
+		Root:                absRoot,
+		Weights:             weights,
+		TestPairs:           testPairs,
+	})
+
+	return files, diagnostics, engine, nil
+}
+
+// loadConfig loads --config, or the config file in absRoot if there is one
+func loadConfig(absRoot string) (*config.Config, error) {
+	if configFlag != "" {
+		return config.Load(configFlag)
+	}
+	return config.LoadFromDir(absRoot)
+}
+
+// loadWeights loads the learned rule weights named by --weights or the
+// config, resolved against the scanned root; nil if neither is set
+func loadWeights(absRoot, configured string) (*inference.Weights, error) {
+	path := weightsPath(absRoot, configured)
+	if path == "" {
+		return nil, nil
+	}
+	return inference.LoadWeights(path)
+}
+
+// weightsPath returns the weights file in use: --weights, else the config's
+// options.weights relative to the scanned root
+func weightsPath(absRoot, configured string) string {
+	switch {
+	case weightsFlag != "":
+		return weightsFlag
+	case configured == "":
+		return ""
+	case filepath.IsAbs(configured):
+		return configured
+	}
+	return filepath.Join(absRoot, configured)
+}
+
+// registerLanguages merges the config's languages into the scanner's language
+// table in name order, so overlapping extensions resolve deterministically
+func registerLanguages(langs map[string]config.Language) error {
+	names := make([]string, 0, len(langs))
+	for name := range langs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		l := langs[name]
+		err := scanner.RegisterLanguage(scanner.LanguageConfig{
+			Name:              name,
+			Extensions:        l.Extensions,
+			Filenames:         l.Filenames,
+			LineComment:       l.LineComment,
+			MultiLineComments: l.BlockComments,
+			Category:          l.Category,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// registerRoles adds the config's custom roles in name order
+func registerRoles(roles map[string]config.Role) error {
+	names := make([]string, 0, len(roles))
+	for name := range roles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		r := roles[name]
+		info := model.RoleInfo{
+			Role:     model.Role(name),
+			Name:     r.Name,
+			Priority: r.Priority,
+			Effort:   r.Effort == nil || *r.Effort,
+		}
+		if r.Color != "" {
+			color, ok := model.ParseSemanticColor(r.Color)
+			if !ok {
+				return fmt.Errorf("role %q: unknown color %q (want primary, safety, operational, knowledge, fragility, low_emphasis, external or warning)", name, r.Color)
+			}
+			info.Color = color
+		}
+		if r.RatioToCore != nil {
+			info.Ratio = &model.RatioTarget{Min: r.RatioToCore.Min, Max: r.RatioToCore.Max}
+		}
+		if err := model.RegisterRole(info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildRules validates the config's custom rules and disabled built-ins
+func buildRules(cfg config.Rules) ([]inference.CustomRule, []inference.DisabledRule, error) {
+	kinds := []struct {
+		kind  inference.RuleKind
+		rules []config.Rule
+	}{
+		{inference.RuleKindPath, cfg.Path},
+		{inference.RuleKindFilename, cfg.Filename},
+		{inference.RuleKindExtension, cfg.Extension},
+		{inference.RuleKindHeader, cfg.Header},
+	}
+
+	var rules []inference.CustomRule
+	for _, k := range kinds {
+		for _, r := range k.rules {
+			rule, err := inference.NewCustomRule(k.kind, r.Pattern, r.Match, r.Regex, r.Role, r.SubRole, r.Weight)
+			if err != nil {
+				return nil, nil, err
+			}
+			rules = append(rules, rule)
+		}
+	}
+
+	var disabled []inference.DisabledRule
+	for _, s := range cfg.Disable {
+		d, err := inference.ParseDisabledRule(s)
+		if err != nil {
+			return nil, nil, err
+		}
+		disabled = append(disabled, d)
+	}
+	return rules, disabled, nil
+}
+
+// buildTestPairs validates the config's test-to-source mappings
+func buildTestPairs(cfg []config.TestPair) ([]inference.TestPair, error) {
+	pairs := make([]inference.TestPair, 0, len(cfg))
+	for _, p := range cfg {
+		pair, err := inference.NewTestPair(p.Source, p.Test)
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs, nil
+}
+
+// buildOverrides validates the config's overrides; languages must be known
+// to the scanner, including those registered from the config
+func buildOverrides(cfg config.Overrides) ([]inference.Override, error) {
+	overrides := make([]inference.Override, 0, len(cfg))
+	for _, o := range cfg {
+		override, err := inference.NewOverride(o.Pattern, o.Role, o.SubRole, o.Language, o.Confidence, o.Priority)
+		if err != nil {
+			return nil, err
+		}
+		if o.Language != "" {
+			if _, ok := scanner.GetLanguageConfig(o.Language); !ok {
+				return nil, fmt.Errorf("override %q: unknown language %q", o.Pattern, o.Language)
+			}
+		}
+		overrides = append(overrides, override)
+	}
+	return overrides, nil
+}
+
+// buildNeighborhoodWeights applies the configured neighborhood weights over
+// the defaults
+func buildNeighborhoodWeights(cfg config.NeighborhoodWeights) (inference.NeighborhoodWeights, error) {
+	w := inference.DefaultNeighborhoodWeights()
+	if cfg.Decay != nil {
+		w.Decay = *cfg.Decay
+	}
+	if cfg.Levels != nil {
+		w.Levels = *cfg.Levels
+	}
+	if cfg.Depth != nil {
+		w.Depth = *cfg.Depth
+	}
+	if cfg.MinSupport != nil {
+		w.MinSupport = *cfg.MinSupport
+	}
+	if cfg.Dominance != nil {
+		w.Dominance = *cfg.Dominance
+	}
+	if cfg.Boost != nil {
+		w.Boost = *cfg.Boost
+	}
+
+	switch {
+	case w.Decay <= 0 || w.Decay > 1:
+		return w, fmt.Errorf("neighborhood_weights: decay must be in (0, 1], got %v", w.Decay)
+	case w.Levels < 0:
+		return w, fmt.Errorf("neighborhood_weights: levels must not be negative, got %d", w.Levels)
+	case w.Depth < 0:
+		return w, fmt.Errorf("neighborhood_weights: depth must not be negative, got %d", w.Depth)
+	case w.MinSupport < 0:
+		return w, fmt.Errorf("neighborhood_weights: min_support must not be negative, got %v", w.MinSupport)
+	case w.Dominance <= 0 || w.Dominance > 1:
+		return w, fmt.Errorf("neighborhood_weights: dominance must be in (0, 1], got %v", w.Dominance)
+	case w.Boost < 0 || w.Boost > 1:
+		return w, fmt.Errorf("neighborhood_weights: boost must be in [0, 1], got %v", w.Boost)
+	}
+	return w, nil
+}
+
+// warnUnmatchedOverrides reports override patterns that matched no file,
+// usually a typo or a path that has since moved
+func warnUnmatchedOverrides(engine *inference.Engine) {
+	for _, o := range engine.UnmatchedOverrides() {
+		fmt.Fprintf(os.Stderr, "warning: override %q (%s) matched no files\n", o.Pattern, o.Role)
+	}
+}
+
 // renderEngineerMode renders only the engineer throughput analysis
 func renderEngineerMode(report *model.Report, opts renderer.Options, format string) error {
 	if report.Engineer == nil {
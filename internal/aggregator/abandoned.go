@@ -0,0 +1,72 @@
+package aggregator
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/modern-tooling/aloc/internal/git"
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+// ComputeAbandoned totals the abandoned files by role and reason and marks
+// each of them with why it looks abandoned, for --files output
+func ComputeAbandoned(records []*model.FileRecord, abandoned *git.Abandoned) *model.AbandonedSurface {
+	if abandoned == nil {
+		return nil
+	}
+
+	surface := &model.AbandonedSurface{
+		StaleMonths:    abandoned.StaleMonths,
+		InactiveMonths: abandoned.InactiveMonths,
+		ByRole:         []model.AbandonedRole{},
+		ByReason:       make(map[model.StaleReason]int),
+	}
+
+	byPath := make(map[string]*model.FileRecord, len(records))
+	for _, r := range records {
+		byPath[r.Path] = r
+	}
+
+	byRole := make(map[model.Role]*model.AbandonedRole)
+	for _, f := range abandoned.Files {
+		r, ok := byPath[f.Path]
+		if !ok {
+			continue
+		}
+		r.Stale = &model.StaleInfo{LastModified: f.LastModified, Reasons: f.Reasons}
+
+		surface.Files++
+		surface.LOC += r.LOC
+		for _, reason := range f.Reasons {
+			surface.ByReason[reason] += r.LOC
+		}
+		rs, ok := byRole[r.Role]
+		if !ok {
+			rs = &model.AbandonedRole{Role: r.Role}
+			byRole[r.Role] = rs
+		}
+		rs.Files++
+		rs.LOC += r.LOC
+	}
+
+	for _, rs := range byRole {
+		surface.ByRole = append(surface.ByRole, *rs)
+	}
+	slices.SortFunc(surface.ByRole, func(a, b model.AbandonedRole) int {
+		if c := cmp.Compare(b.LOC, a.LOC); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Role, b.Role)
+	})
+
+	for _, d := range abandoned.Dirs {
+		surface.Directories = append(surface.Directories, model.StaleDir{
+			Path:         d.Path,
+			Files:        d.Files,
+			LOC:          d.LOC,
+			LastModified: d.LastModified,
+		})
+	}
+
+	return surface
+}
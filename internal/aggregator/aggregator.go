@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"log"
 	"slices"
+	"strings"
 	"time"
 
+	"github.com/modern-tooling/aloc/internal/coverage"
 	"github.com/modern-tooling/aloc/internal/git"
 	"github.com/modern-tooling/aloc/internal/model"
 )
@@ -19,11 +21,18 @@ type Options struct {
 	EffortOpts       EffortOptions
 	GitAnalysis      bool
 	GitOpts          git.Options
+	StaleOpts        git.StaleOptions
 	EngineerAnalysis bool
 	EngineerOpts     git.EngineerOptions
+	Diagnostics      []model.Diagnostic
+	Coverage         *coverage.Profile // from --coverage; nil if none
 }
 
 func Compute(records []*model.FileRecord, opts Options) *model.Report {
+	// binary assets carry no LOC; they are reported in their own section
+	allRecords := records
+	records, assets := splitAssets(records)
+
 	responsibilities := ComputeResponsibilities(records)
 
 	report := &model.Report{
@@ -39,11 +48,26 @@ func Compute(records []*model.FileRecord, opts Options) *model.Report {
 		Ratios:           ComputeRatios(responsibilities),
 		Languages:        ComputeLanguageBreakdown(records),
 		Confidence:       computeConfidenceInfo(records),
+		Assets:           ComputeAssets(assets),
+		TestFrameworks:   ComputeTestFrameworks(records),
+		Vendored:         ComputeVendored(records),
+		Untested:         ComputeUntested(records),
+		Roles:            model.CustomRoles(),
+		Diagnostics:      sortDiagnostics(opts.Diagnostics),
+	}
+
+	if opts.Coverage != nil {
+		root := ""
+		if opts.RepoInfo != nil {
+			root = opts.RepoInfo.Root
+		}
+		report.Coverage = ComputeCoverage(records, opts.Coverage, root)
+		report.Ratios.CoveredCoreToCore = coveredCoreRatio(report.Coverage)
 	}
 
 	if opts.IncludeEffort {
 		report.Effort = ComputeEffortWithResponsibilities(
-			report.Summary.LOCTotal,
+			effortLOCByRole(records),
 			report.Summary.Lines,
 			responsibilities,
 			report.Ratios,
@@ -52,7 +76,7 @@ func Compute(records []*model.FileRecord, opts Options) *model.Report {
 	}
 
 	if opts.IncludeFiles {
-		report.Files = records
+		report.Files = allRecords
 	}
 
 	// git analysis (optional)
@@ -68,6 +92,14 @@ func Compute(records []*model.FileRecord, opts Options) *model.Report {
 				applyGitAdjustments(report.Effort, gitMetrics.NetAdjustment)
 			}
 		}
+
+		// abandoned code reads the whole history, not just the window
+		abandoned, err := git.AnalyzeAbandoned(opts.RepoInfo.Root, records, opts.StaleOpts)
+		if err != nil {
+			log.Printf("abandoned code analysis: %v", err)
+		} else {
+			report.Abandoned = ComputeAbandoned(records, abandoned)
+		}
 	} else if opts.RepoInfo != nil && opts.RepoInfo.Root != "" {
 		// detect git repo for hint (lightweight)
 		hint, err := git.DetectRepo(opts.RepoInfo.Root)
@@ -286,6 +318,18 @@ func applyGitAdjustments(effort *model.EffortEstimates, netAdjustment float64) {
 	}
 }
 
+// sortDiagnostics orders diagnostics by path so output is stable across runs
+func sortDiagnostics(diags []model.Diagnostic) []model.Diagnostic {
+	if len(diags) == 0 {
+		return nil
+	}
+	sorted := slices.Clone(diags)
+	slices.SortFunc(sorted, func(a, b model.Diagnostic) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return sorted
+}
+
 func computeConfidenceInfo(records []*model.FileRecord) model.ConfidenceInfo {
 	var totalLOC int
 	var highConfLOC int
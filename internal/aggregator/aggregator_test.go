@@ -1,8 +1,13 @@
 package aggregator
 
 import (
+	"math"
 	"testing"
+	"time"
 
+	"github.com/modern-tooling/aloc/internal/coverage"
+	"github.com/modern-tooling/aloc/internal/effort"
+	"github.com/modern-tooling/aloc/internal/git"
 	"github.com/modern-tooling/aloc/internal/model"
 )
 
@@ -26,6 +31,98 @@ func TestComputeSummary(t *testing.T) {
 	}
 }
 
+func TestEffortLOC_Logical(t *testing.T) {
+	records := []*model.FileRecord{
+		{Path: "a.go", LOC: 100, Lines: model.LineMetrics{Code: 100, Logical: 60}},
+		{Path: "b.rb", LOC: 50, Lines: model.LineMetrics{Code: 50}},
+	}
+
+	effort.ResetModelConfig()
+	defer effort.ResetModelConfig()
+
+	if got := effortLOC(records); got != 150 {
+		t.Errorf("effortLOC (physical) = %v, want 150", got)
+	}
+
+	cfg := effort.DefaultModelConfig()
+	cfg.UseLogicalLOC = true
+	effort.SetModelConfig(cfg)
+
+	// unsupported languages fall back to physical LOC
+	if got := effortLOC(records); got != 110 {
+		t.Errorf("effortLOC (logical) = %v, want 110", got)
+	}
+}
+
+func TestHybridBreakdown_Logical(t *testing.T) {
+	records := []*model.FileRecord{
+		{Path: "a.go", LOC: 100, Role: model.RoleCore, Lines: model.LineMetrics{Code: 100, Logical: 40}},
+		{Path: "a_test.rb", LOC: 100, Role: model.RoleTest, Lines: model.LineMetrics{Code: 100}},
+		// half the file is inline tests: they take half its logical lines
+		{Path: "lib.rs", LOC: 60, Role: model.RoleCore, Lines: model.LineMetrics{Code: 60, Logical: 20},
+			Split: []model.RoleLOC{{Role: model.RoleTest, SubRole: model.TestUnit, LOC: 30}}},
+	}
+
+	cfg := effort.DefaultModelConfig()
+	cfg.UseLogicalLOC = true
+	effort.SetModelConfig(cfg)
+	defer effort.ResetModelConfig()
+
+	sizing := effortLOCByRole(records)
+	if sizing[model.RoleCore] != 50 || sizing[model.RoleTest] != 110 || effortLOC(records) != 160 {
+		t.Fatalf("sizing = %v (total %d), want core 50, test 110", sizing, effortLOC(records))
+	}
+
+	breakdown := ComputeHybridBreakdown(sizing, 1600)
+	saved := make(map[model.Role]float64)
+	for _, h := range breakdown {
+		saved[h.Role] = h.DollarsSaved
+	}
+	// the cost splits 500 core / 1100 test, by the same lines as the total
+	if math.Abs(saved[model.RoleCore]-500*0.20) > 1e-9 || math.Abs(saved[model.RoleTest]-1100*0.30) > 1e-9 {
+		t.Errorf("saved = %v, want core 100 and test 330", saved)
+	}
+	if breakdown[0].Role != model.RoleTest {
+		t.Errorf("breakdown = %+v, want the largest role first", breakdown)
+	}
+}
+
+func TestCustomRoles(t *testing.T) {
+	defer model.ResetRoles()
+	if err := model.RegisterRole(model.RoleInfo{Role: "seeds", Ratio: &model.RatioTarget{Max: 0.1}, Effort: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := model.RegisterRole(model.RoleInfo{Role: "fixtures", Effort: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := model.RegisterRole(model.RoleInfo{Role: "assets", Effort: false}); err != nil {
+		t.Fatal(err)
+	}
+	records := []*model.FileRecord{
+		{Path: "a.go", LOC: 100, Role: model.RoleCore},
+		{Path: "db/seeds.sql", LOC: 20, Role: "seeds"},
+		{Path: "ui/icons.svg", LOC: 30, Role: "assets"},
+	}
+
+	report := Compute(records, Options{})
+	if got := report.Ratios.Roles; len(got) != 1 || got[0].Role != "seeds" || got[0].ToCore != 0.2 {
+		t.Errorf("Ratios.Roles = %+v, want seeds at 0.2", got)
+	}
+	if len(report.Roles) != 3 {
+		t.Errorf("Roles = %+v, want the 3 custom roles", report.Roles)
+	}
+	var found bool
+	for _, r := range report.Responsibilities {
+		found = found || r.Role == "seeds" && r.LOC == 20
+	}
+	if !found {
+		t.Errorf("Responsibilities = %+v, want seeds with 20 LOC", report.Responsibilities)
+	}
+	if got := effortLOC(records); got != 120 {
+		t.Errorf("effortLOC = %v, want 120 without the assets role", got)
+	}
+}
+
 func TestComputeSummary_ExcludesUnknown(t *testing.T) {
 	records := []*model.FileRecord{
 		{Path: "a.go", LOC: 100, Language: "Go"},
@@ -82,6 +179,55 @@ func TestComputeResponsibilities_TestBreakdown(t *testing.T) {
 	}
 }
 
+func TestComputeResponsibilities_FamilyBreakdown(t *testing.T) {
+	records := []*model.FileRecord{
+		{Path: "db/migration/V1__init.sql", LOC: 30, Role: model.RoleMigrations, SubRole: model.MigrationFlyway},
+		{Path: "db/migration/V2__users.sql", LOC: 30, Role: model.RoleMigrations, SubRole: model.MigrationFlyway},
+		{Path: "migrations/tools.go", LOC: 40, Role: model.RoleMigrations},
+		{Path: "main.go", LOC: 100, Role: model.RoleCore},
+	}
+
+	byRole := make(map[model.Role]model.Responsibility)
+	for _, r := range ComputeResponsibilities(records) {
+		byRole[r.Role] = r
+	}
+	if got := byRole[model.RoleMigrations].Breakdown; len(got) != 1 || got[model.MigrationFlyway] != 0.6 {
+		t.Errorf("migrations breakdown = %v, want flyway 0.6", got)
+	}
+	if got := byRole[model.RoleCore].Breakdown; got != nil {
+		t.Errorf("core breakdown = %v, want none", got)
+	}
+}
+
+func TestComputeResponsibilities_SplitInlineTests(t *testing.T) {
+	records := []*model.FileRecord{
+		{Path: "src/lib.rs", LOC: 300, Role: model.RoleCore, Confidence: 0.5,
+			Split: []model.RoleLOC{{Role: model.RoleTest, SubRole: model.TestUnit, LOC: 120}}},
+		{Path: "tests/it.rs", LOC: 80, Role: model.RoleTest, SubRole: model.TestIntegration, Confidence: 0.9},
+	}
+
+	byRole := make(map[model.Role]model.Responsibility)
+	for _, r := range ComputeResponsibilities(records) {
+		byRole[r.Role] = r
+	}
+
+	core, test := byRole[model.RoleCore], byRole[model.RoleTest]
+	if core.LOC != 180 || core.Files != 1 {
+		t.Errorf("core = %d LOC in %d files, want 180 in 1", core.LOC, core.Files)
+	}
+	if test.LOC != 200 || test.Files != 1 {
+		t.Errorf("test = %d LOC in %d files, want 200 in 1", test.LOC, test.Files)
+	}
+	if unit := test.Breakdown[model.TestUnit]; unit != 0.6 {
+		t.Errorf("unit share = %v, want 0.6", unit)
+	}
+
+	ratios := ComputeRatios(ComputeResponsibilities(records))
+	if ratios.TestToCore < 1.11 || ratios.TestToCore > 1.12 {
+		t.Errorf("TestToCore = %v, want 200/180", ratios.TestToCore)
+	}
+}
+
 func TestComputeRatios(t *testing.T) {
 	resp := []model.Responsibility{
 		{Role: model.RoleCore, LOC: 1000},
@@ -112,6 +258,27 @@ func TestComputeRatios_ZeroProd(t *testing.T) {
 	}
 }
 
+func TestComputeTestFrameworks(t *testing.T) {
+	records := []*model.FileRecord{
+		{Path: "a.test.ts", LOC: 40, Role: model.RoleTest, TestFramework: "Jest"},
+		{Path: "b.test.ts", LOC: 20, Role: model.RoleTest, TestFramework: "Jest"},
+		{Path: "e2e/login.spec.ts", LOC: 80, Role: model.RoleTest, TestFramework: "Playwright"},
+		{Path: "c.ts", LOC: 500, Role: model.RoleCore},
+	}
+
+	stats := ComputeTestFrameworks(records)
+
+	if len(stats) != 2 {
+		t.Fatalf("got %d frameworks, want 2", len(stats))
+	}
+	if stats[0].Name != "Playwright" || stats[0].LOC != 80 {
+		t.Errorf("stats[0] = %+v, want Playwright with 80 LOC", stats[0])
+	}
+	if stats[1].Name != "Jest" || stats[1].Files != 2 || stats[1].LOC != 60 {
+		t.Errorf("stats[1] = %+v, want Jest with 2 files and 60 LOC", stats[1])
+	}
+}
+
 func TestComputeLanguageBreakdown(t *testing.T) {
 	records := []*model.FileRecord{
 		{Path: "a.go", LOC: 100, Lines: model.LineMetrics{Code: 100}, Language: "Go", Role: model.RoleCore},
@@ -182,3 +349,207 @@ func TestCompute_ExcludeFiles(t *testing.T) {
 		t.Error("Files should be nil when IncludeFiles is false")
 	}
 }
+
+func TestCompute_Assets(t *testing.T) {
+	records := []*model.FileRecord{
+		{Path: "main.go", LOC: 100, Language: "Go", Role: model.RoleCore},
+		{Path: "docs/a.png", Language: "unknown", Role: model.RoleDocs, Bytes: 3000,
+			Asset: &model.AssetInfo{Type: "image"}},
+		{Path: "web/b.png", Language: "unknown", Role: model.RoleCore, Bytes: 1000,
+			Asset: &model.AssetInfo{Type: "image"}},
+		{Path: "models/w.onnx", Language: "unknown", Role: model.RoleCore, Bytes: 130,
+			Asset: &model.AssetInfo{Type: "model", LFS: true, LFSSize: 50000}},
+	}
+
+	report := Compute(records, Options{IncludeFiles: true})
+
+	// assets do not count as source files
+	if report.Summary.Files != 1 {
+		t.Errorf("Summary.Files = %v, want 1", report.Summary.Files)
+	}
+	if len(report.Files) != 4 {
+		t.Errorf("len(Files) = %v, want 4", len(report.Files))
+	}
+
+	a := report.Assets
+	if a == nil {
+		t.Fatal("Assets should be set")
+	}
+	// the LFS object counts at its own size, as in the breakdowns
+	if a.Files != 3 || a.Bytes != 54000 {
+		t.Errorf("Assets = %d files / %d bytes, want 3 / 54000", a.Files, a.Bytes)
+	}
+	if a.LFSPointers != 1 || a.LFSBytes != 50000 || a.LFSPointerBytes != 130 {
+		t.Errorf("LFS = %d / %d (pointers %d), want 1 / 50000 (pointers 130)", a.LFSPointers, a.LFSBytes, a.LFSPointerBytes)
+	}
+	if len(a.ByType) != 2 || a.ByType[0].Name != "model" || a.ByType[1].Bytes != 4000 {
+		t.Errorf("ByType = %+v, want model then image (4000 bytes)", a.ByType)
+	}
+	if len(a.ByRole) != 2 || a.ByRole[0].Name != "core" || a.ByRole[0].Bytes != 51000 {
+		t.Errorf("ByRole = %+v, want core first (51000 bytes)", a.ByRole)
+	}
+	if len(a.Largest) != 3 || a.Largest[0].Path != "models/w.onnx" || !a.Largest[0].LFS {
+		t.Errorf("Largest = %+v, want models/w.onnx first", a.Largest)
+	}
+}
+
+func TestCompute_NoAssets(t *testing.T) {
+	records := []*model.FileRecord{
+		{Path: "main.go", LOC: 100, Language: "Go", Role: model.RoleCore},
+	}
+
+	if report := Compute(records, Options{}); report.Assets != nil {
+		t.Errorf("Assets = %+v, want nil", report.Assets)
+	}
+}
+
+func TestComputeVendored(t *testing.T) {
+	records := []*model.FileRecord{
+		{Path: "lib/zlib/inflate.c", LOC: 900, Role: model.RoleVendor, Upstream: "zlib"},
+		{Path: "lib/zlib/deflate.c", LOC: 1100, Role: model.RoleVendor, Upstream: "zlib"},
+		{Path: "static/jquery-3.6.0.min.js", LOC: 2, Role: model.RoleVendor, Upstream: "jquery 3.6.0"},
+		{Path: "third_party/x.c", LOC: 50, Role: model.RoleVendor},
+		{Path: "main.c", LOC: 500, Role: model.RoleCore},
+	}
+
+	stats := ComputeVendored(records)
+
+	if len(stats) != 2 {
+		t.Fatalf("got %d upstreams, want 2", len(stats))
+	}
+	if stats[0].Name != "zlib" || stats[0].Files != 2 || stats[0].LOC != 2000 {
+		t.Errorf("stats[0] = %+v, want zlib with 2 files and 2000 LOC", stats[0])
+	}
+	if stats[1].Name != "jquery 3.6.0" {
+		t.Errorf("stats[1] = %+v, want jquery 3.6.0", stats[1])
+	}
+}
+
+func TestComputeAbandoned(t *testing.T) {
+	old := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	records := []*model.FileRecord{
+		{Path: "legacy/export.go", LOC: 300, Role: model.RoleCore},
+		{Path: "legacy/export_test.go", LOC: 100, Role: model.RoleTest},
+		{Path: "api/client.go", LOC: 50, Role: model.RoleCore},
+		{Path: "api/handler.go", LOC: 80, Role: model.RoleCore},
+	}
+	abandoned := &git.Abandoned{
+		Files: []git.StaleFile{
+			{Path: "api/client.go", LastModified: old, Reasons: []model.StaleReason{model.StaleInactiveAuthor, model.StaleDeprecated}},
+			{Path: "legacy/export.go", LastModified: old, Reasons: []model.StaleReason{model.StaleUntouched}},
+			{Path: "legacy/export_test.go", LastModified: old, Reasons: []model.StaleReason{model.StaleUntouched}},
+		},
+		Dirs:           []git.StaleDir{{Path: "legacy", Files: 2, LOC: 400, LastModified: old}},
+		StaleMonths:    12,
+		InactiveMonths: 6,
+	}
+
+	surface := ComputeAbandoned(records, abandoned)
+
+	if surface.Files != 3 || surface.LOC != 450 {
+		t.Errorf("surface = %d files, %d LOC; want 3 files, 450 LOC", surface.Files, surface.LOC)
+	}
+	if len(surface.ByRole) != 2 || surface.ByRole[0].Role != model.RoleCore || surface.ByRole[0].LOC != 350 {
+		t.Errorf("ByRole = %+v, want core 350 first", surface.ByRole)
+	}
+	if surface.ByReason[model.StaleUntouched] != 400 || surface.ByReason[model.StaleDeprecated] != 50 {
+		t.Errorf("ByReason = %v, want untouched 400, deprecated 50", surface.ByReason)
+	}
+	if len(surface.Directories) != 1 || surface.Directories[0].Path != "legacy" {
+		t.Errorf("Directories = %+v, want legacy", surface.Directories)
+	}
+	if s := records[2].Stale; s == nil || len(s.Reasons) != 2 || !s.LastModified.Equal(old) {
+		t.Errorf("api/client.go Stale = %+v, want both reasons", s)
+	}
+	if records[3].Stale != nil {
+		t.Errorf("api/handler.go Stale = %+v, want nil", records[3].Stale)
+	}
+}
+
+func TestComputeUntested(t *testing.T) {
+	records := []*model.FileRecord{
+		{Path: "api/server.go", LOC: 400, Language: "Go", Role: model.RoleCore, TestedBy: []string{"api/server_test.go"}},
+		{Path: "api/routes.go", LOC: 100, Language: "Go", Role: model.RoleCore},
+		{Path: "api/server_test.go", LOC: 300, Language: "Go", Role: model.RoleTest},
+		{Path: "store/db.go", LOC: 250, Language: "Go", Role: model.RoleCore},
+		{Path: "store/cache.go", LOC: 50, Language: "Go", Role: model.RoleCore},
+		{Path: "parser/lex.rs", LOC: 200, Language: "Rust", Role: model.RoleCore,
+			Split: []model.RoleLOC{{Role: model.RoleTest, SubRole: model.TestUnit, LOC: 40}}},
+		{Path: "docs/guide.md", LOC: 80, Language: "Markdown", Role: model.RoleDocs},
+		{Path: "notes/idea.txt", LOC: 900, Language: "Plain Text", Role: model.RoleCore},
+	}
+
+	report := ComputeUntested(records)
+
+	if report.CoreFiles != 5 || report.CoreLOC != 1000 {
+		t.Errorf("core = %d files, %d LOC; want 5 files, 1000 LOC (plain text is not counted)", report.CoreFiles, report.CoreLOC)
+	}
+	if report.UntestedFiles != 3 || report.UntestedLOC != 400 {
+		t.Errorf("untested = %d files, %d LOC; want 3 files, 400 LOC (inline tests count)", report.UntestedFiles, report.UntestedLOC)
+	}
+	if len(report.Directories) != 3 {
+		t.Fatalf("got %d directories, want 3", len(report.Directories))
+	}
+	if d := report.Directories[0]; d.Path != "store" || d.UntestedFiles != 2 || d.UntestedLOC != 300 {
+		t.Errorf("Directories[0] = %+v, want store with 2 files, 300 LOC", d)
+	}
+	if d := report.Directories[1]; d.Path != "api" || d.CoreLOC != 500 || d.UntestedLOC != 100 {
+		t.Errorf("Directories[1] = %+v, want api with 100 of 500 LOC", d)
+	}
+	if len(report.Largest) != 3 || report.Largest[0].Path != "store/db.go" {
+		t.Errorf("Largest = %+v, want store/db.go first", report.Largest)
+	}
+
+	if ComputeUntested([]*model.FileRecord{{Path: "README.md", LOC: 10, Role: model.RoleDocs}}) != nil {
+		t.Error("report without core files, want nil")
+	}
+}
+
+func TestComputeCoverage(t *testing.T) {
+	records := []*model.FileRecord{
+		{Path: "internal/cart/cart.go", LOC: 200, Language: "Go", Role: model.RoleCore},
+		{Path: "internal/cart/tax.go", LOC: 100, Language: "Go", Role: model.RoleCore},
+		{Path: "internal/cart/cart_test.go", LOC: 150, Language: "Go", Role: model.RoleTest},
+		{Path: "web/app.ts", LOC: 100, Language: "TypeScript", Role: model.RoleCore},
+		{Path: "scripts/seed.py", LOC: 40, Language: "Python", Role: model.RoleScripts},
+	}
+	profile := &coverage.Profile{
+		Source: "cover.out",
+		Format: coverage.FormatGo,
+		Files: map[string]*coverage.FileCoverage{
+			"github.com/acme/shop/internal/cart/cart.go": {Measured: 40, Covered: 30},
+			"github.com/acme/shop/internal/cart/tax.go":  {Measured: 10, Covered: 0},
+			"github.com/acme/shop/internal/gone/old.go":  {Measured: 5, Covered: 5},
+		},
+	}
+
+	report := ComputeCoverage(records, profile, "/src/shop")
+
+	if report.Files != 2 || report.Unmatched != 1 || report.Format != "go" {
+		t.Errorf("report = %d files, %d unmatched, format %q; want 2, 1, go", report.Files, report.Unmatched, report.Format)
+	}
+	if c := records[0].Coverage; c == nil || c.Ratio != 0.75 || c.CoveredLOC != 150 {
+		t.Errorf("cart.go Coverage = %+v, want 0.75 and 150 covered LOC", c)
+	}
+	if records[3].Coverage != nil {
+		t.Errorf("app.ts Coverage = %+v, want nil for an unmeasured file", records[3].Coverage)
+	}
+
+	// core counts the unmeasured app.ts as uncovered; test and scripts
+	// have no measured files and are left out
+	if len(report.ByRole) != 1 || report.ByRole[0].Name != "core" {
+		t.Fatalf("ByRole = %+v, want core only", report.ByRole)
+	}
+	if core := report.ByRole[0]; core.LOC != 400 || core.CoveredLOC != 150 || core.Coverage != 0.375 || core.MeasuredFiles != 2 {
+		t.Errorf("core = %+v, want 150 of 400 LOC over 2 measured files", core)
+	}
+	if len(report.ByLanguage) != 1 || report.ByLanguage[0].Name != "Go" || report.ByLanguage[0].Coverage != 0.5 {
+		t.Errorf("ByLanguage = %+v, want Go at 0.5", report.ByLanguage)
+	}
+	if len(report.ByDirectory) != 2 || report.ByDirectory[0].Name != "internal/cart" || report.ByDirectory[1].Coverage != 0 {
+		t.Errorf("ByDirectory = %+v, want internal/cart then web at 0", report.ByDirectory)
+	}
+	if r := coveredCoreRatio(report); r == nil || *r != 0.375 {
+		t.Errorf("coveredCoreRatio = %v, want 0.375", r)
+	}
+}
@@ -0,0 +1,101 @@
+package aggregator
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+// maxLargestAssets caps the largest-assets list
+const maxLargestAssets = 10
+
+// splitAssets separates binary assets from countable source records
+func splitAssets(records []*model.FileRecord) (code, assets []*model.FileRecord) {
+	for _, r := range records {
+		if r.Asset != nil {
+			assets = append(assets, r)
+		} else {
+			code = append(code, r)
+		}
+	}
+	return code, assets
+}
+
+// ComputeAssets builds the asset inventory: counts and bytes by asset type and
+// by role, Git LFS pointer totals, and the largest assets. LFS pointers are
+// sized by the object they stand in for throughout, so the total is the sum
+// of each breakdown; the pointer files' own bytes are kept apart.
+func ComputeAssets(assets []*model.FileRecord) *model.AssetInventory {
+	if len(assets) == 0 {
+		return nil
+	}
+
+	inv := &model.AssetInventory{}
+	byType := make(map[string]*model.AssetGroup)
+	byRole := make(map[string]*model.AssetGroup)
+	largest := make([]model.AssetFile, 0, len(assets))
+
+	add := func(groups map[string]*model.AssetGroup, name string, bytes int64) {
+		g, ok := groups[name]
+		if !ok {
+			g = &model.AssetGroup{Name: name}
+			groups[name] = g
+		}
+		g.Files++
+		g.Bytes += bytes
+	}
+
+	for _, r := range assets {
+		size := r.Bytes
+		if r.Asset.LFS {
+			inv.LFSPointers++
+			inv.LFSBytes += r.Asset.LFSSize
+			inv.LFSPointerBytes += r.Bytes
+			size = r.Asset.LFSSize
+		}
+		inv.Files++
+		inv.Bytes += size
+
+		add(byType, r.Asset.Type, size)
+		add(byRole, string(r.Role), size)
+		largest = append(largest, model.AssetFile{
+			Path:  r.Path,
+			Type:  r.Asset.Type,
+			Role:  r.Role,
+			Bytes: size,
+			LFS:   r.Asset.LFS,
+		})
+	}
+
+	inv.ByType = sortedAssetGroups(byType)
+	inv.ByRole = sortedAssetGroups(byRole)
+
+	slices.SortFunc(largest, func(a, b model.AssetFile) int {
+		if c := cmp.Compare(b.Bytes, a.Bytes); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Path, b.Path)
+	})
+	if len(largest) > maxLargestAssets {
+		largest = largest[:maxLargestAssets]
+	}
+	inv.Largest = largest
+
+	return inv
+}
+
+// sortedAssetGroups orders groups by bytes descending, then name
+func sortedAssetGroups(groups map[string]*model.AssetGroup) []model.AssetGroup {
+	result := make([]model.AssetGroup, 0, len(groups))
+	for _, g := range groups {
+		result = append(result, *g)
+	}
+	slices.SortFunc(result, func(a, b model.AssetGroup) int {
+		if c := cmp.Compare(b.Bytes, a.Bytes); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return result
+}
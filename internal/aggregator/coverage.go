@@ -0,0 +1,108 @@
+package aggregator
+
+import (
+	"cmp"
+	"math"
+	"path/filepath"
+	"slices"
+
+	"github.com/modern-tooling/aloc/internal/coverage"
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+// ComputeCoverage joins a coverage report with the records, setting each
+// measured file's coverage, and totals covered LOC by role, and for core
+// files by language and directory. A file's covered LOC is its LOC scaled
+// by the covered share of the lines the report measured.
+func ComputeCoverage(records []*model.FileRecord, profile *coverage.Profile, root string) *model.CoverageReport {
+	if profile == nil {
+		return nil
+	}
+
+	paths := make([]string, len(records))
+	for i, r := range records {
+		paths[i] = r.Path
+	}
+	matched, unmatched := profile.Match(root, paths)
+
+	report := &model.CoverageReport{
+		Source:    profile.Source,
+		Format:    string(profile.Format),
+		Unmatched: len(unmatched),
+	}
+	byRole := make(map[string]*model.CoverageStat)
+	byLanguage := make(map[string]*model.CoverageStat)
+	byDir := make(map[string]*model.CoverageStat)
+	add := func(groups map[string]*model.CoverageStat, name string, r *model.FileRecord) {
+		s, ok := groups[name]
+		if !ok {
+			s = &model.CoverageStat{Name: name}
+			groups[name] = s
+		}
+		s.Files++
+		s.LOC += r.LOC
+		if r.Coverage != nil {
+			s.MeasuredFiles++
+			s.CoveredLOC += r.Coverage.CoveredLOC
+		}
+	}
+
+	for _, r := range records {
+		r.Coverage = nil
+		if fc, ok := matched[r.Path]; ok {
+			ratio := fc.Ratio()
+			r.Coverage = &model.FileCoverage{
+				Measured:   fc.Measured,
+				Covered:    fc.Covered,
+				Ratio:      ratio,
+				CoveredLOC: int(math.Round(float64(r.LOC) * ratio)),
+			}
+			report.Files++
+		}
+
+		add(byRole, string(r.Role), r)
+		if r.Role == model.RoleCore {
+			add(byLanguage, r.Language, r)
+			add(byDir, filepath.ToSlash(filepath.Dir(r.Path)), r)
+		}
+	}
+
+	report.ByRole = coverageStats(byRole, func(s *model.CoverageStat) bool {
+		return s.MeasuredFiles > 0 || s.Name == string(model.RoleCore)
+	})
+	report.ByLanguage = coverageStats(byLanguage, func(s *model.CoverageStat) bool { return s.MeasuredFiles > 0 })
+	report.ByDirectory = coverageStats(byDir, func(s *model.CoverageStat) bool { return true })
+	return report
+}
+
+// coverageStats keeps the groups worth reporting, largest first
+func coverageStats(groups map[string]*model.CoverageStat, keep func(*model.CoverageStat) bool) []model.CoverageStat {
+	stats := []model.CoverageStat{}
+	for _, s := range groups {
+		if !keep(s) {
+			continue
+		}
+		if s.LOC > 0 {
+			s.Coverage = float64(s.CoveredLOC) / float64(s.LOC)
+		}
+		stats = append(stats, *s)
+	}
+	slices.SortFunc(stats, func(a, b model.CoverageStat) int {
+		if c := cmp.Compare(b.LOC, a.LOC); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return stats
+}
+
+// coveredCoreRatio is covered core LOC over core LOC, for the health ratios
+func coveredCoreRatio(report *model.CoverageReport) *float32 {
+	for _, s := range report.ByRole {
+		if s.Name == string(model.RoleCore) && s.LOC > 0 {
+			ratio := float32(s.Coverage)
+			return &ratio
+		}
+	}
+	return nil
+}
@@ -1,7 +1,9 @@
 package aggregator
 
 import (
+	"cmp"
 	"fmt"
+	"slices"
 
 	"github.com/modern-tooling/aloc/internal/effort"
 	"github.com/modern-tooling/aloc/internal/model"
@@ -110,8 +112,55 @@ func ComputeEffortEstimatesWithLines(totalLOC int, lines model.LineMetrics, opts
 	return estimates
 }
 
-// ComputeEffortWithResponsibilities computes effort with per-role hybrid breakdown
-func ComputeEffortWithResponsibilities(totalLOC int, lines model.LineMetrics, responsibilities []model.Responsibility, ratios model.Ratios, opts EffortOptions) *model.EffortEstimates {
+// effortLOC returns the LOC that sizes effort estimates: physical LOC by
+// default, or logical lines (physical LOC where unsupported) when the model
+// config enables use_logical_loc. Roles that do not count toward effort (a
+// custom role with effort: false) are left out.
+func effortLOC(records []*model.FileRecord) int {
+	var loc int
+	for _, n := range effortLOCByRole(records) {
+		loc += n
+	}
+	return loc
+}
+
+// effortLOCByRole is effortLOC by role. A file's split parts take their
+// share of its logical lines in proportion to their LOC.
+func effortLOCByRole(records []*model.FileRecord) map[model.Role]int {
+	logical := effort.GetModelConfig().UseLogicalLOC
+	byRole := make(map[model.Role]int)
+	for _, r := range records {
+		size := r.LOC
+		if logical && r.Lines.Logical > 0 {
+			size = r.Lines.Logical
+		}
+		parts := r.RoleLOCs()
+		rest := size
+		for _, p := range parts[1:] {
+			share := size
+			if r.LOC > 0 {
+				share = size * p.LOC / r.LOC
+			}
+			rest -= share
+			if p.Role.Info().Effort {
+				byRole[p.Role] += share
+			}
+		}
+		if parts[0].Role.Info().Effort {
+			byRole[parts[0].Role] += rest
+		}
+	}
+	return byRole
+}
+
+// ComputeEffortWithResponsibilities computes effort with per-role hybrid
+// breakdown. sizing is the LOC that sizes effort by role (see effortLOC);
+// the estimates and the breakdown both use it.
+func ComputeEffortWithResponsibilities(sizing map[model.Role]int, lines model.LineMetrics, responsibilities []model.Responsibility, ratios model.Ratios, opts EffortOptions) *model.EffortEstimates {
+	var totalLOC int
+	for _, n := range sizing {
+		totalLOC += n
+	}
 	estimates := ComputeEffortEstimatesWithLines(totalLOC, lines, opts)
 	if estimates == nil {
 		return nil
@@ -132,7 +181,7 @@ func ComputeEffortWithResponsibilities(totalLOC int, lines model.LineMetrics, re
 
 	// Compute per-role hybrid savings
 	if estimates.Human != nil {
-		estimates.HybridBreakdown = ComputeHybridBreakdown(responsibilities, estimates.Human.EstimatedCost)
+		estimates.HybridBreakdown = ComputeHybridBreakdown(sizing, estimates.Human.EstimatedCost)
 	}
 
 	// Compute quick actions
@@ -153,32 +202,46 @@ func ComputeEffortWithResponsibilities(totalLOC int, lines model.LineMetrics, re
 	return estimates
 }
 
-// ComputeHybridBreakdown calculates per-role savings from AI assistance
-func ComputeHybridBreakdown(responsibilities []model.Responsibility, totalHumanCost float64) []model.HybridSavings {
+// ComputeHybridBreakdown calculates per-role savings from AI assistance,
+// splitting the cost by the effort-sizing LOC of each role
+func ComputeHybridBreakdown(sizing map[model.Role]int, totalHumanCost float64) []model.HybridSavings {
 	var breakdown []model.HybridSavings
 	var totalLOC int
 
-	for _, r := range responsibilities {
-		totalLOC += r.LOC
+	for role, loc := range sizing {
+		if role.Info().Effort {
+			totalLOC += loc
+		}
 	}
 
 	if totalLOC == 0 {
 		return breakdown
 	}
 
-	for _, r := range responsibilities {
-		rate, ok := HybridReductionRates[r.Role]
-		if !ok || r.LOC == 0 {
-			continue
+	// largest roles first, as in the responsibilities
+	roles := make([]model.Role, 0, len(HybridReductionRates))
+	for role := range HybridReductionRates {
+		if sizing[role] > 0 {
+			roles = append(roles, role)
+		}
+	}
+	slices.SortFunc(roles, func(a, b model.Role) int {
+		if c := cmp.Compare(sizing[b], sizing[a]); c != 0 {
+			return c
 		}
+		return cmp.Compare(a, b)
+	})
+
+	for _, role := range roles {
+		rate := HybridReductionRates[role]
 
 		// Proportion of total cost for this role
-		roleProportion := float64(r.LOC) / float64(totalLOC)
+		roleProportion := float64(sizing[role]) / float64(totalLOC)
 		roleCost := totalHumanCost * roleProportion
 		dollarsSaved := roleCost * rate.Reduction
 
 		breakdown = append(breakdown, model.HybridSavings{
-			Role:         r.Role,
+			Role:         role,
 			Reduction:    rate.Reduction,
 			DollarsSaved: dollarsSaved,
 			Description:  rate.Description,
@@ -0,0 +1,38 @@
+package aggregator
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+// ComputeTestFrameworks totals test files and LOC by detected framework,
+// largest first
+func ComputeTestFrameworks(records []*model.FileRecord) []model.TestFrameworkStat {
+	byName := make(map[string]*model.TestFrameworkStat)
+	for _, r := range records {
+		if r.TestFramework == "" {
+			continue
+		}
+		stat, ok := byName[r.TestFramework]
+		if !ok {
+			stat = &model.TestFrameworkStat{Name: r.TestFramework}
+			byName[r.TestFramework] = stat
+		}
+		stat.Files++
+		stat.LOC += r.LOC
+	}
+
+	stats := make([]model.TestFrameworkStat, 0, len(byName))
+	for _, s := range byName {
+		stats = append(stats, *s)
+	}
+	slices.SortFunc(stats, func(a, b model.TestFrameworkStat) int {
+		if c := cmp.Compare(b.LOC, a.LOC); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return stats
+}
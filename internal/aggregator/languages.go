@@ -24,7 +24,7 @@ func ComputeLanguageBreakdown(records []*model.FileRecord) []model.LanguageComp
 	byLang := make(map[string]*langAccum)
 
 	for _, r := range records {
-		if r.Language == "" || r.Language == "unknown" {
+		if r.Language == "" || r.Language == "unknown" || r.Linguist.Undetectable() {
 			continue
 		}
 
@@ -44,14 +44,15 @@ func ComputeLanguageBreakdown(records []*model.FileRecord) []model.LanguageComp
 		acc.Blanks += r.Lines.Blanks
 		acc.LOCTotal += r.Lines.Code + r.Lines.Comments + r.Lines.Blanks
 		acc.Files++
-		acc.ByRole[r.Role] += r.LOC
-
-		// Track tests by role (subset of Code, shown separately)
-		switch r.Role {
-		case model.RoleTest:
-			acc.Tests += r.Lines.Code // test code lines only
-		case model.RoleConfig:
-			acc.Config += r.LOC
+		// Track tests by role (subset of Code, shown separately), including inline tests
+		for _, part := range r.RoleLOCs() {
+			acc.ByRole[part.Role] += part.LOC
+			switch part.Role {
+			case model.RoleTest:
+				acc.Tests += part.LOC // test code lines only
+			case model.RoleConfig:
+				acc.Config += part.LOC
+			}
 		}
 
 		// Accumulate embedded code block stats (for Markdown, etc.)
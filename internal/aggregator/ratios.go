@@ -13,11 +13,25 @@ func ComputeRatios(responsibilities []model.Responsibility) model.Ratios {
 		coreLOC = 1 // avoid division by zero
 	}
 
-	return model.Ratios{
+	ratios := model.Ratios{
 		TestToCore:      float32(byRole[model.RoleTest]) / float32(coreLOC),
 		InfraToCore:     float32(byRole[model.RoleInfra]) / float32(coreLOC),
 		DocsToCore:      float32(byRole[model.RoleDocs]) / float32(coreLOC),
 		GeneratedToCore: float32(byRole[model.RoleGenerated]) / float32(coreLOC),
 		ConfigToCore:    float32(byRole[model.RoleConfig]) / float32(coreLOC),
 	}
+
+	// custom roles that ask for a ratio get one, present in the tree or not
+	for _, info := range model.CustomRoles() {
+		if info.Ratio == nil {
+			continue
+		}
+		ratios.Roles = append(ratios.Roles, model.RoleRatio{
+			Role:   info.Role,
+			Name:   info.Name,
+			ToCore: float32(byRole[info.Role]) / float32(coreLOC),
+			Target: *info.Ratio,
+		})
+	}
+	return ratios
 }
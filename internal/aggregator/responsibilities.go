@@ -11,27 +11,32 @@ type roleAccum struct {
 	LOC           int
 	Files         int
 	ConfidenceSum float64
-	SubRoleCounts map[model.TestKind]int
+	SubRoleCounts map[model.SubRole]int
 }
 
 func ComputeResponsibilities(records []*model.FileRecord) []model.Responsibility {
 	byRole := make(map[model.Role]*roleAccum)
 
 	for _, r := range records {
-		acc, ok := byRole[r.Role]
-		if !ok {
-			acc = &roleAccum{
-				Role:          r.Role,
-				SubRoleCounts: make(map[model.TestKind]int),
+		// inline tests and other split parts count toward their own role
+		for i, part := range r.RoleLOCs() {
+			acc, ok := byRole[part.Role]
+			if !ok {
+				acc = &roleAccum{
+					Role:          part.Role,
+					SubRoleCounts: make(map[model.SubRole]int),
+				}
+				byRole[part.Role] = acc
 			}
-			byRole[r.Role] = acc
-		}
-		acc.LOC += r.LOC
-		acc.Files++
-		acc.ConfidenceSum += float64(r.Confidence) * float64(r.LOC)
+			acc.LOC += part.LOC
+			if i == 0 {
+				acc.Files++ // files count once, under their own role
+			}
+			acc.ConfidenceSum += float64(r.Confidence) * float64(part.LOC)
 
-		if r.Role == model.RoleTest && r.SubRole != "" {
-			acc.SubRoleCounts[r.SubRole] += r.LOC
+			if part.SubRole != "" {
+				acc.SubRoleCounts[part.SubRole] += part.LOC
+			}
 		}
 	}
 
@@ -49,9 +54,9 @@ func ComputeResponsibilities(records []*model.FileRecord) []model.Responsibility
 			Confidence: confidence,
 		}
 
-		// Test breakdown
-		if acc.Role == model.RoleTest && len(acc.SubRoleCounts) > 0 {
-			resp.Breakdown = computeTestBreakdown(acc.SubRoleCounts, acc.LOC)
+		// Sub-role breakdown: test kinds, migration formats, ...
+		if len(acc.SubRoleCounts) > 0 {
+			resp.Breakdown = computeSubRoleBreakdown(acc.SubRoleCounts, acc.LOC)
 		}
 
 		result = append(result, resp)
@@ -65,11 +70,11 @@ func ComputeResponsibilities(records []*model.FileRecord) []model.Responsibility
 	return result
 }
 
-func computeTestBreakdown(counts map[model.TestKind]int, total int) map[model.TestKind]float32 {
+func computeSubRoleBreakdown(counts map[model.SubRole]int, total int) map[model.SubRole]float32 {
 	if total == 0 {
 		return nil
 	}
-	breakdown := make(map[model.TestKind]float32)
+	breakdown := make(map[model.SubRole]float32)
 	for kind, loc := range counts {
 		breakdown[kind] = float32(loc) / float32(total)
 	}
@@ -13,6 +13,7 @@ func ComputeSummary(records []*model.FileRecord) model.Summary {
 		lines.Blanks += r.Lines.Blanks
 		lines.Comments += r.Lines.Comments
 		lines.Code += r.Lines.Code
+		lines.Logical += r.Lines.Logical
 		if r.Language != "" && r.Language != "unknown" {
 			langs[r.Language] = true
 		}
@@ -0,0 +1,82 @@
+package aggregator
+
+import (
+	"cmp"
+	"path/filepath"
+	"slices"
+
+	"github.com/modern-tooling/aloc/internal/model"
+	"github.com/modern-tooling/aloc/internal/scanner"
+)
+
+// maxUntestedFiles caps the largest untested files listed
+const maxUntestedFiles = 10
+
+// ComputeUntested finds the core files no test is paired with, by directory
+// and largest first. Files with inline tests count as tested. Only primary
+// languages are counted: text and data files have no tests to pair.
+func ComputeUntested(records []*model.FileRecord) *model.UntestedReport {
+	report := &model.UntestedReport{
+		Directories: []model.UntestedDir{},
+		Largest:     []model.UntestedFile{},
+	}
+	byDir := make(map[string]*model.UntestedDir)
+
+	for _, r := range records {
+		if r.Role != model.RoleCore || scanner.GetLanguageCategory(r.Language) != scanner.CategoryPrimary {
+			continue
+		}
+		dir := filepath.ToSlash(filepath.Dir(r.Path))
+		d, ok := byDir[dir]
+		if !ok {
+			d = &model.UntestedDir{Path: dir}
+			byDir[dir] = d
+		}
+		report.CoreFiles++
+		report.CoreLOC += r.LOC
+		d.CoreFiles++
+		d.CoreLOC += r.LOC
+		if isTested(r) {
+			continue
+		}
+		report.UntestedFiles++
+		report.UntestedLOC += r.LOC
+		d.UntestedFiles++
+		d.UntestedLOC += r.LOC
+		report.Largest = append(report.Largest, model.UntestedFile{Path: r.Path, LOC: r.LOC})
+	}
+
+	if report.CoreFiles == 0 {
+		return nil
+	}
+
+	for _, d := range byDir {
+		report.Directories = append(report.Directories, *d)
+	}
+	slices.SortFunc(report.Directories, func(a, b model.UntestedDir) int {
+		if c := cmp.Compare(b.UntestedLOC, a.UntestedLOC); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Path, b.Path)
+	})
+	slices.SortFunc(report.Largest, func(a, b model.UntestedFile) int {
+		if c := cmp.Compare(b.LOC, a.LOC); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Path, b.Path)
+	})
+	if len(report.Largest) > maxUntestedFiles {
+		report.Largest = report.Largest[:maxUntestedFiles]
+	}
+	return report
+}
+
+// isTested reports whether a core file has a paired test or inline tests
+func isTested(r *model.FileRecord) bool {
+	if len(r.TestedBy) > 0 {
+		return true
+	}
+	return slices.ContainsFunc(r.Split, func(p model.RoleLOC) bool {
+		return p.Role == model.RoleTest
+	})
+}
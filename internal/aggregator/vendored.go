@@ -0,0 +1,38 @@
+package aggregator
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+// ComputeVendored totals vendored files and LOC by detected upstream project,
+// largest first. Vendored files without a known upstream are left out.
+func ComputeVendored(records []*model.FileRecord) []model.VendoredStat {
+	byName := make(map[string]*model.VendoredStat)
+	for _, r := range records {
+		if r.Role != model.RoleVendor || r.Upstream == "" {
+			continue
+		}
+		stat, ok := byName[r.Upstream]
+		if !ok {
+			stat = &model.VendoredStat{Name: r.Upstream}
+			byName[r.Upstream] = stat
+		}
+		stat.Files++
+		stat.LOC += r.LOC
+	}
+
+	stats := make([]model.VendoredStat, 0, len(byName))
+	for _, s := range byName {
+		stats = append(stats, *s)
+	}
+	slices.SortFunc(stats, func(a, b model.VendoredStat) int {
+		if c := cmp.Compare(b.LOC, a.LOC); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return stats
+}
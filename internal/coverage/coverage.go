@@ -0,0 +1,163 @@
+// Package coverage reads test coverage reports (Go coverprofile, LCOV,
+// Cobertura and JaCoCo) and joins them with scanned file paths.
+package coverage
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Format names a coverage report format
+type Format string
+
+const (
+	FormatGo        Format = "go"
+	FormatLCOV      Format = "lcov"
+	FormatCobertura Format = "cobertura"
+	FormatJaCoCo    Format = "jacoco"
+)
+
+// FileCoverage counts the measured and covered lines of one file. Go
+// coverprofiles count statements instead, as go tool cover does.
+type FileCoverage struct {
+	Measured int
+	Covered  int
+}
+
+// Ratio returns the covered share of the measured lines
+func (c FileCoverage) Ratio() float64 {
+	if c.Measured == 0 {
+		return 0
+	}
+	return float64(c.Covered) / float64(c.Measured)
+}
+
+// Profile is a parsed coverage report, keyed by the paths it names
+type Profile struct {
+	Source string // the file read, when loaded
+	Format Format
+	Files  map[string]*FileCoverage
+}
+
+// Load reads a coverage report, detecting its format
+func Load(path string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read coverage: %w", err)
+	}
+	profile, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	profile.Source = path
+	return profile, nil
+}
+
+// Parse detects the format of a coverage report and parses it
+func Parse(data []byte) (*Profile, error) {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("mode:")):
+		return parseGo(trimmed)
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return parseXML(trimmed)
+	case bytes.Contains(trimmed, []byte("SF:")):
+		return parseLCOV(trimmed)
+	}
+	return nil, fmt.Errorf("unknown coverage format (want a Go coverprofile, LCOV, Cobertura or JaCoCo XML)")
+}
+
+// lineHits merges the per-line hit counts of the sections of one file; a
+// line is covered if any section covered it
+type lineHits map[string]map[int]bool
+
+func (h lineHits) add(file string, line int, covered bool) {
+	lines, ok := h[file]
+	if !ok {
+		lines = make(map[int]bool)
+		h[file] = lines
+	}
+	lines[line] = lines[line] || covered
+}
+
+func (h lineHits) profile(format Format) *Profile {
+	p := &Profile{Format: format, Files: make(map[string]*FileCoverage, len(h))}
+	for file, lines := range h {
+		fc := &FileCoverage{Measured: len(lines)}
+		for _, covered := range lines {
+			if covered {
+				fc.Covered++
+			}
+		}
+		p.Files[file] = fc
+	}
+	return p
+}
+
+// Match joins the report with scanned paths, relative to root. Report paths
+// may be absolute, relative to root, or carry a prefix such as a Go import
+// path; a scanned path may also be longer than the report's (JaCoCo names
+// files by package, without src/main/java). Report paths that match no
+// scanned file, or several, are returned as unmatched.
+func (p *Profile) Match(root string, paths []string) (map[string]FileCoverage, []string) {
+	byPath := make(map[string]string, len(paths))
+	bySuffix := make(map[string][]string)
+	for _, path := range paths {
+		slash := filepath.ToSlash(path)
+		byPath[slash] = path
+		for i := strings.Index(slash, "/"); i >= 0; i = nextSlash(slash, i) {
+			bySuffix[slash[i+1:]] = append(bySuffix[slash[i+1:]], path)
+		}
+	}
+	rootSlash := filepath.ToSlash(root) + "/"
+
+	matched := make(map[string]FileCoverage)
+	var unmatched []string
+	for name, fc := range p.Files {
+		c := strings.TrimPrefix(filepath.ToSlash(name), "./")
+		c = strings.TrimPrefix(c, rootSlash)
+
+		path, ok := lookup(c, byPath, bySuffix)
+		if !ok {
+			unmatched = append(unmatched, name)
+			continue
+		}
+		// sections of one file reported under several names add up
+		m := matched[path]
+		m.Measured += fc.Measured
+		m.Covered += fc.Covered
+		matched[path] = m
+	}
+	sort.Strings(unmatched)
+	return matched, unmatched
+}
+
+// lookup finds the scanned path a report path names: the path itself, a
+// scanned path ending in it, or the path with leading directories dropped
+func lookup(c string, byPath map[string]string, bySuffix map[string][]string) (string, bool) {
+	if path, ok := byPath[c]; ok {
+		return path, true
+	}
+	if paths := bySuffix[c]; len(paths) > 0 {
+		return paths[0], len(paths) == 1
+	}
+	for i := strings.Index(c, "/"); i >= 0; i = nextSlash(c, i) {
+		if path, ok := byPath[c[i+1:]]; ok {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// nextSlash returns the index of the next "/" after i, or -1
+func nextSlash(s string, i int) int {
+	j := strings.Index(s[i+1:], "/")
+	if j < 0 {
+		return -1
+	}
+	return i + 1 + j
+}
@@ -0,0 +1,165 @@
+package coverage
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParse_GoCoverprofile(t *testing.T) {
+	data := `mode: atomic
+github.com/acme/shop/internal/cart/cart.go:10.30,12.2 2 5
+github.com/acme/shop/internal/cart/cart.go:14.30,18.2 3 0
+github.com/acme/shop/internal/cart/cart.go:14.30,18.2 3 1
+github.com/acme/shop/internal/cart/tax.go:5.20,9.2 4 0
+`
+	p, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Format != FormatGo {
+		t.Errorf("Format = %q, want go", p.Format)
+	}
+	// the repeated block counts once, covered by the second test binary
+	if got := *p.Files["github.com/acme/shop/internal/cart/cart.go"]; got != (FileCoverage{Measured: 5, Covered: 5}) {
+		t.Errorf("cart.go = %+v, want 5 of 5 statements", got)
+	}
+	if got := *p.Files["github.com/acme/shop/internal/cart/tax.go"]; got != (FileCoverage{Measured: 4, Covered: 0}) {
+		t.Errorf("tax.go = %+v, want 0 of 4 statements", got)
+	}
+}
+
+func TestParse_LCOV(t *testing.T) {
+	data := `TN:
+SF:/home/ci/app/src/util/format.ts
+FN:1,format
+DA:1,4
+DA:2,4
+DA:3,0
+DA:5,0
+LF:4
+LH:2
+end_of_record
+SF:/home/ci/app/src/util/format.ts
+DA:3,1
+end_of_record
+`
+	p, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Format != FormatLCOV {
+		t.Errorf("Format = %q, want lcov", p.Format)
+	}
+	if got := *p.Files["/home/ci/app/src/util/format.ts"]; got != (FileCoverage{Measured: 4, Covered: 3}) {
+		t.Errorf("format.ts = %+v, want 3 of 4 lines after merging records", got)
+	}
+}
+
+func TestParse_Cobertura(t *testing.T) {
+	data := `<?xml version="1.0" ?>
+<!DOCTYPE coverage SYSTEM "http://cobertura.sourceforge.net/xml/coverage-04.dtd">
+<coverage line-rate="0.5" version="7.4">
+  <sources><source>/build/app</source></sources>
+  <packages>
+    <package name="billing">
+      <classes>
+        <class name="invoice.py" filename="billing/invoice.py" line-rate="0.5">
+          <lines>
+            <line number="1" hits="1"/>
+            <line number="2" hits="0"/>
+            <line number="3" hits="3"/>
+            <line number="4" hits="0"/>
+          </lines>
+        </class>
+      </classes>
+    </package>
+  </packages>
+</coverage>`
+	p, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Format != FormatCobertura {
+		t.Errorf("Format = %q, want cobertura", p.Format)
+	}
+	if got := *p.Files["/build/app/billing/invoice.py"]; got != (FileCoverage{Measured: 4, Covered: 2}) {
+		t.Errorf("invoice.py = %+v, want 2 of 4 lines", got)
+	}
+}
+
+func TestParse_JaCoCo(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<!DOCTYPE report PUBLIC "-//JACOCO//DTD Report 1.1//EN" "report.dtd">
+<report name="shop">
+  <sessioninfo id="ci" start="1" dump="2"/>
+  <package name="com/acme/shop">
+    <class name="com/acme/shop/Order" sourcefilename="Order.java"/>
+    <sourcefile name="Order.java">
+      <line nr="3" mi="0" ci="3" mb="0" cb="0"/>
+      <line nr="5" mi="2" ci="0" mb="0" cb="0"/>
+      <line nr="6" mi="1" ci="1" mb="1" cb="1"/>
+      <counter type="LINE" missed="1" covered="2"/>
+    </sourcefile>
+  </package>
+</report>`
+	p, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Format != FormatJaCoCo {
+		t.Errorf("Format = %q, want jacoco", p.Format)
+	}
+	if got := *p.Files["com/acme/shop/Order.java"]; got != (FileCoverage{Measured: 3, Covered: 2}) {
+		t.Errorf("Order.java = %+v, want 2 of 3 lines", got)
+	}
+}
+
+func TestParse_Unknown(t *testing.T) {
+	for _, data := range []string{"", "hello", "<html></html>"} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", data)
+		}
+	}
+}
+
+func TestProfile_Match(t *testing.T) {
+	p := &Profile{Files: map[string]*FileCoverage{
+		"github.com/acme/shop/internal/cart/cart.go": {Measured: 10, Covered: 8},
+		"/home/ci/shop/web/src/app.ts":               {Measured: 4, Covered: 1},
+		"/repo/shop/README.md":                       {Measured: 1, Covered: 1},
+		"com/acme/shop/Order.java":                   {Measured: 6, Covered: 3},
+		"./cmd/shop/main.go":                         {Measured: 2, Covered: 0},
+		"util.go":                                    {Measured: 3, Covered: 3}, // two scanned files end in it
+		"deleted/old.go":                             {Measured: 5, Covered: 5},
+	}}
+	paths := []string{
+		"internal/cart/cart.go",
+		"web/src/app.ts",
+		"README.md",
+		"src/main/java/com/acme/shop/Order.java",
+		"cmd/shop/main.go",
+		"a/util.go",
+		"b/util.go",
+	}
+
+	matched, unmatched := p.Match("/repo/shop", paths)
+
+	want := map[string]FileCoverage{
+		"internal/cart/cart.go":                  {Measured: 10, Covered: 8},
+		"web/src/app.ts":                         {Measured: 4, Covered: 1},
+		"README.md":                              {Measured: 1, Covered: 1},
+		"src/main/java/com/acme/shop/Order.java": {Measured: 6, Covered: 3},
+		"cmd/shop/main.go":                       {Measured: 2, Covered: 0},
+	}
+	if len(matched) != len(want) {
+		t.Errorf("matched = %v, want %v", matched, want)
+	}
+	for path, w := range want {
+		if matched[path] != w {
+			t.Errorf("%s = %+v, want %+v", path, matched[path], w)
+		}
+	}
+	if !slices.Equal(unmatched, []string{"deleted/old.go", "util.go"}) {
+		t.Errorf("unmatched = %v, want deleted/old.go and the ambiguous util.go", unmatched)
+	}
+}
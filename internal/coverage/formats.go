@@ -0,0 +1,208 @@
+package coverage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// parseGo reads a Go coverprofile: "file:start.col,end.col statements count"
+// lines after a "mode:" line. Blocks listed more than once, as profiles
+// merged from several test binaries do, count once.
+func parseGo(data []byte) (*Profile, error) {
+	type block struct {
+		statements int
+		covered    bool
+	}
+	blocks := make(map[string]map[string]*block) // file, then position
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		// the file name may contain colons; the position follows the last one
+		colon := strings.LastIndex(line, ":")
+		fields := strings.Fields(line[colon+1:])
+		if colon < 0 || len(fields) != 3 {
+			return nil, fmt.Errorf("malformed coverprofile line %q", line)
+		}
+		statements, err1 := strconv.Atoi(fields[1])
+		count, err2 := strconv.Atoi(fields[2])
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("malformed coverprofile line %q", line)
+		}
+
+		file := line[:colon]
+		if blocks[file] == nil {
+			blocks[file] = make(map[string]*block)
+		}
+		b, ok := blocks[file][fields[0]]
+		if !ok {
+			b = &block{statements: statements}
+			blocks[file][fields[0]] = b
+		}
+		b.covered = b.covered || count > 0
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	p := &Profile{Format: FormatGo, Files: make(map[string]*FileCoverage, len(blocks))}
+	for file, fileBlocks := range blocks {
+		fc := &FileCoverage{}
+		for _, b := range fileBlocks {
+			fc.Measured += b.statements
+			if b.covered {
+				fc.Covered += b.statements
+			}
+		}
+		p.Files[file] = fc
+	}
+	return p, nil
+}
+
+// parseLCOV reads LCOV tracefiles: "SF:" opens a file's record, "DA:line,hits"
+// gives its lines and "end_of_record" closes it
+func parseLCOV(data []byte) (*Profile, error) {
+	hits := make(lineHits)
+	var file string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "SF:"):
+			file = strings.TrimPrefix(line, "SF:")
+		case strings.HasPrefix(line, "DA:") && file != "":
+			fields := strings.Split(strings.TrimPrefix(line, "DA:"), ",")
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("malformed LCOV line %q", line)
+			}
+			n, err1 := strconv.Atoi(fields[0])
+			count, err2 := strconv.ParseFloat(fields[1], 64) // some tools write 1.0e3
+			if err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("malformed LCOV line %q", line)
+			}
+			hits.add(file, n, count > 0)
+		case line == "end_of_record":
+			file = ""
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(hits) == 0 {
+		return nil, fmt.Errorf("no LCOV line data (DA:) found")
+	}
+	return hits.profile(FormatLCOV), nil
+}
+
+// coberturaReport is the part of a Cobertura report aloc reads
+type coberturaReport struct {
+	Sources  []string `xml:"sources>source"`
+	Packages []struct {
+		Classes []struct {
+			Filename string `xml:"filename,attr"`
+			Lines    []struct {
+				Number int    `xml:"number,attr"`
+				Hits   string `xml:"hits,attr"`
+			} `xml:"lines>line"`
+		} `xml:"classes>class"`
+	} `xml:"packages>package"`
+}
+
+// jacocoReport is the part of a JaCoCo report aloc reads
+type jacocoReport struct {
+	Packages []jacocoPackage `xml:"package"`
+	Groups   []struct {
+		Packages []jacocoPackage `xml:"package"`
+	} `xml:"group"`
+}
+
+type jacocoPackage struct {
+	Name        string `xml:"name,attr"`
+	SourceFiles []struct {
+		Name  string `xml:"name,attr"`
+		Lines []struct {
+			Number          int `xml:"nr,attr"`
+			CoveredInstrs   int `xml:"ci,attr"`
+			CoveredBranches int `xml:"cb,attr"`
+		} `xml:"line"`
+	} `xml:"sourcefile"`
+}
+
+// parseXML reads Cobertura (<coverage>) and JaCoCo (<report>) reports
+func parseXML(data []byte) (*Profile, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false // JaCoCo declares an external DTD
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("unknown coverage XML: %w", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "coverage":
+			var report coberturaReport
+			if err := decoder.DecodeElement(&report, &start); err != nil {
+				return nil, fmt.Errorf("cobertura: %w", err)
+			}
+			return coberturaProfile(report), nil
+		case "report":
+			var report jacocoReport
+			if err := decoder.DecodeElement(&report, &start); err != nil {
+				return nil, fmt.Errorf("jacoco: %w", err)
+			}
+			return jacocoProfile(report), nil
+		default:
+			return nil, fmt.Errorf("unknown coverage XML root <%s> (want Cobertura <coverage> or JaCoCo <report>)", start.Name.Local)
+		}
+	}
+}
+
+// coberturaProfile merges the classes of each file. File names are relative
+// to one of the report's sources; with a single source it is joined on.
+func coberturaProfile(report coberturaReport) *Profile {
+	hits := make(lineHits)
+	for _, pkg := range report.Packages {
+		for _, class := range pkg.Classes {
+			file := class.Filename
+			if len(report.Sources) == 1 && !path.IsAbs(file) {
+				file = path.Join(strings.TrimSpace(report.Sources[0]), file)
+			}
+			for _, line := range class.Lines {
+				count, _ := strconv.ParseFloat(line.Hits, 64)
+				hits.add(file, line.Number, count > 0)
+			}
+		}
+	}
+	return hits.profile(FormatCobertura)
+}
+
+// jacocoProfile names files by package directory and source file name
+func jacocoProfile(report jacocoReport) *Profile {
+	hits := make(lineHits)
+	packages := report.Packages
+	for _, g := range report.Groups {
+		packages = append(packages, g.Packages...)
+	}
+	for _, pkg := range packages {
+		for _, sf := range pkg.SourceFiles {
+			file := path.Join(pkg.Name, sf.Name)
+			for _, line := range sf.Lines {
+				hits.add(file, line.Number, line.CoveredInstrs > 0 || line.CoveredBranches > 0)
+			}
+		}
+	}
+	return hits.profile(FormatJaCoCo)
+}
@@ -103,6 +103,9 @@ type ModelConfig struct {
 	TeamCompositionBySize *TeamCompositionBySize     `json:"team_composition_by_size,omitempty"`
 	AILeverageBySkill     AILeverageBySkill          `json:"ai_leverage_by_skill"`
 	DefaultHumanCostMo    float64                    `json:"default_human_cost_per_month"`
+	// UseLogicalLOC sizes estimates by logical lines (statements) where the
+	// language supports them, so estimates do not depend on formatting style
+	UseLogicalLOC bool `json:"use_logical_loc,omitempty"`
 }
 
 // DefaultModelConfig returns the default configuration with all hardcoded values
@@ -268,6 +271,9 @@ func LoadModelConfig(path string) (*ModelConfig, error) {
 	if override.DefaultHumanCostMo != 0 {
 		cfg.DefaultHumanCostMo = override.DefaultHumanCostMo
 	}
+	if override.UseLogicalLOC {
+		cfg.UseLogicalLOC = true
+	}
 
 	return cfg, nil
 }
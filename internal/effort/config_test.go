@@ -74,7 +74,8 @@ func TestLoadModelConfig_Merge(t *testing.T) {
 		"skill_bands": {
 			"staff": { "annual_cost_low": 350000 }
 		},
-		"default_human_cost_per_month": 20000
+		"default_human_cost_per_month": 20000,
+		"use_logical_loc": true
 	}`
 	if err := os.WriteFile(configPath, []byte(configJSON), 0644); err != nil {
 		t.Fatalf("failed to write config: %v", err)
@@ -127,6 +128,10 @@ func TestLoadModelConfig_Merge(t *testing.T) {
 	if cfg.DefaultHumanCostMo != 20000 {
 		t.Errorf("expected default human cost=20000, got %v", cfg.DefaultHumanCostMo)
 	}
+
+	if !cfg.UseLogicalLOC {
+		t.Error("expected use_logical_loc=true")
+	}
 }
 
 func TestLoadModelConfig_FileNotFound(t *testing.T) {
@@ -103,6 +103,9 @@ func parseProfileJSON(data []byte) (*ModelConfig, error) {
 	if override.DefaultHumanCostMo != 0 {
 		cfg.DefaultHumanCostMo = override.DefaultHumanCostMo
 	}
+	if override.UseLogicalLOC {
+		cfg.UseLogicalLOC = true
+	}
 
 	return cfg, nil
 }
@@ -0,0 +1,240 @@
+package git
+
+import (
+	"bufio"
+	"os/exec"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+// maxStaleDirs caps the stale directories reported, largest first
+const maxStaleDirs = 10
+
+// StaleOptions controls abandoned code detection
+type StaleOptions struct {
+	StaleMonths    int // files untouched this long are stale (default 12)
+	InactiveMonths int // authors without a commit this long are inactive (default 6)
+}
+
+// DefaultStaleOptions returns sensible defaults
+func DefaultStaleOptions() StaleOptions {
+	return StaleOptions{
+		StaleMonths:    12,
+		InactiveMonths: 6,
+	}
+}
+
+// FileActivity is what the whole history says about one file
+type FileActivity struct {
+	LastModified time.Time
+	Authors      map[string]bool // hashed
+}
+
+// Activity is the history of every file and author
+type Activity struct {
+	Files      map[string]*FileActivity
+	LastCommit map[string]time.Time // by hashed author, across the repository
+}
+
+// StaleFile is a file that looks abandoned
+type StaleFile struct {
+	Path         string
+	LastModified time.Time // zero if never committed
+	Reasons      []model.StaleReason
+}
+
+// StaleDir is a directory in which no file changed for StaleMonths
+type StaleDir struct {
+	Path         string
+	Files        int
+	LOC          int
+	LastModified time.Time
+}
+
+// Abandoned contains the files and directories nobody seems to maintain
+type Abandoned struct {
+	Files []StaleFile // by path
+	Dirs  []StaleDir  // outermost stale directories, largest first
+
+	StaleMonths    int
+	InactiveMonths int
+}
+
+// AnalyzeAbandoned reads the whole history of root and finds abandoned code
+func AnalyzeAbandoned(root string, records []*model.FileRecord, opts StaleOptions) (*Abandoned, error) {
+	activity, err := ParseActivity(root)
+	if err != nil {
+		return nil, err
+	}
+	return FindAbandoned(records, activity, time.Now(), opts), nil
+}
+
+// ParseActivity runs git log over the whole history and returns when each
+// file last changed, who changed it, and when each author last committed.
+// Only file names are listed, so this stays cheap on long histories. File
+// paths are relative to root, which may be below the repository top, while
+// authors' last commits span the whole repository.
+func ParseActivity(root string) (*Activity, error) {
+	// %x00 marks commit headers: email|timestamp
+	cmd := exec.Command("git", "-C", root,
+		"log",
+		"--name-only",
+		"--no-renames",
+		"--relative",
+		"--format=%x00%aE|%aI",
+	)
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	return parseActivity(string(out)), nil
+}
+
+// parseActivity parses the git log output into file and author activity
+func parseActivity(output string) *Activity {
+	activity := &Activity{
+		Files:      make(map[string]*FileActivity),
+		LastCommit: make(map[string]time.Time),
+	}
+	var author string
+	var when time.Time
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		if header, ok := strings.CutPrefix(line, "\x00"); ok {
+			email, timestamp, _ := strings.Cut(header, "|")
+			author = hashAuthor(email)
+			t, err := time.Parse(time.RFC3339, timestamp)
+			if err != nil {
+				author = ""
+				continue
+			}
+			when = t
+			if when.After(activity.LastCommit[author]) {
+				activity.LastCommit[author] = when
+			}
+			continue
+		}
+
+		if author == "" {
+			continue
+		}
+		fa, ok := activity.Files[line]
+		if !ok {
+			fa = &FileActivity{Authors: make(map[string]bool)}
+			activity.Files[line] = fa
+		}
+		fa.Authors[author] = true
+		if when.After(fa.LastModified) {
+			fa.LastModified = when
+		}
+	}
+
+	return activity
+}
+
+// FindAbandoned flags files untouched for StaleMonths, files whose only
+// author made no commit for InactiveMonths, and files carrying deprecation
+// markers. Vendored files are left out: upstream code is expected not to
+// change. Files git does not know yet are new, not stale.
+func FindAbandoned(records []*model.FileRecord, activity *Activity, now time.Time, opts StaleOptions) *Abandoned {
+	if opts.StaleMonths <= 0 {
+		opts.StaleMonths = 12
+	}
+	if opts.InactiveMonths <= 0 {
+		opts.InactiveMonths = 6
+	}
+	staleCutoff := now.AddDate(0, -opts.StaleMonths, 0)
+	inactiveCutoff := now.AddDate(0, -opts.InactiveMonths, 0)
+
+	result := &Abandoned{StaleMonths: opts.StaleMonths, InactiveMonths: opts.InactiveMonths}
+
+	// newest change and size below each directory, to find stale directories
+	type dirStat struct {
+		files, loc int
+		last       time.Time
+		fresh      bool // holds a file git does not know yet
+	}
+	dirs := make(map[string]*dirStat)
+
+	for _, r := range records {
+		if r.Role == model.RoleVendor {
+			continue
+		}
+		fa := activity.Files[r.Path]
+
+		var reasons []model.StaleReason
+		if fa != nil && fa.LastModified.Before(staleCutoff) {
+			reasons = append(reasons, model.StaleUntouched)
+		}
+		if fa != nil && len(fa.Authors) == 1 {
+			for author := range fa.Authors {
+				if activity.LastCommit[author].Before(inactiveCutoff) {
+					reasons = append(reasons, model.StaleInactiveAuthor)
+				}
+			}
+		}
+		if r.Deprecated {
+			reasons = append(reasons, model.StaleDeprecated)
+		}
+		if len(reasons) > 0 {
+			sf := StaleFile{Path: r.Path, Reasons: reasons}
+			if fa != nil {
+				sf.LastModified = fa.LastModified
+			}
+			result.Files = append(result.Files, sf)
+		}
+
+		for d := path.Dir(r.Path); d != "." && d != "/"; d = path.Dir(d) {
+			ds, ok := dirs[d]
+			if !ok {
+				ds = &dirStat{}
+				dirs[d] = ds
+			}
+			ds.files++
+			ds.loc += r.LOC
+			if fa == nil {
+				ds.fresh = true
+			} else if fa.LastModified.After(ds.last) {
+				ds.last = fa.LastModified
+			}
+		}
+	}
+
+	stale := func(d string) bool {
+		ds, ok := dirs[d]
+		return ok && !ds.fresh && ds.last.Before(staleCutoff)
+	}
+	for d, ds := range dirs {
+		if !stale(d) || stale(path.Dir(d)) {
+			continue
+		}
+		result.Dirs = append(result.Dirs, StaleDir{Path: d, Files: ds.files, LOC: ds.loc, LastModified: ds.last})
+	}
+
+	slices.SortFunc(result.Files, func(a, b StaleFile) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	slices.SortFunc(result.Dirs, func(a, b StaleDir) int {
+		if a.LOC != b.LOC {
+			return b.LOC - a.LOC
+		}
+		return strings.Compare(a.Path, b.Path)
+	})
+	if len(result.Dirs) > maxStaleDirs {
+		result.Dirs = result.Dirs[:maxStaleDirs]
+	}
+
+	return result
+}
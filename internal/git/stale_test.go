@@ -0,0 +1,154 @@
+package git
+
+import (
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+func TestParseActivity(t *testing.T) {
+	output := "\x00alice@example.com|2025-03-01T10:00:00Z\n\nsrc/a.go\nsrc/b.go\n" +
+		"\x00bob@example.com|2024-01-15T10:00:00Z\n\nsrc/a.go\n" +
+		"\x00alice@example.com|2023-06-01T10:00:00Z\n\nsrc/b.go\n"
+
+	activity := parseActivity(output)
+
+	a := activity.Files["src/a.go"]
+	if a == nil || len(a.Authors) != 2 {
+		t.Fatalf("src/a.go = %+v, want 2 authors", a)
+	}
+	if want := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC); !a.LastModified.Equal(want) {
+		t.Errorf("src/a.go LastModified = %v, want %v", a.LastModified, want)
+	}
+	if b := activity.Files["src/b.go"]; len(b.Authors) != 1 {
+		t.Errorf("src/b.go authors = %d, want 1", len(b.Authors))
+	}
+	if got := activity.LastCommit[hashAuthor("bob@example.com")]; got.Year() != 2024 {
+		t.Errorf("bob's last commit = %v, want 2024", got)
+	}
+}
+
+func TestFindAbandoned(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	old := now.AddDate(-2, 0, 0)
+	recent := now.AddDate(0, -1, 0)
+	alice, bob := hashAuthor("alice"), hashAuthor("bob")
+
+	activity := &Activity{
+		Files: map[string]*FileActivity{
+			"legacy/report/pdf.go":  {LastModified: old, Authors: map[string]bool{alice: true, bob: true}},
+			"legacy/report/csv.go":  {LastModified: old, Authors: map[string]bool{alice: true, bob: true}},
+			"legacy/export.go":      {LastModified: old, Authors: map[string]bool{bob: true}},
+			"api/handler.go":        {LastModified: recent, Authors: map[string]bool{alice: true}},
+			"api/client.go":         {LastModified: recent, Authors: map[string]bool{bob: true}},
+			"vendor/lib/lib.go":     {LastModified: old, Authors: map[string]bool{alice: true}},
+			"tools/gen/main.go":     {LastModified: old, Authors: map[string]bool{alice: true, bob: true}},
+			"api/internal/flags.go": {LastModified: recent, Authors: map[string]bool{alice: true}},
+		},
+		LastCommit: map[string]time.Time{
+			alice: recent,
+			bob:   now.AddDate(0, -8, 0),
+		},
+	}
+	records := []*model.FileRecord{
+		{Path: "legacy/report/pdf.go", LOC: 300, Role: model.RoleCore},
+		{Path: "legacy/report/csv.go", LOC: 100, Role: model.RoleCore},
+		{Path: "legacy/export.go", LOC: 50, Role: model.RoleCore},
+		{Path: "api/handler.go", LOC: 80, Role: model.RoleCore},
+		{Path: "api/client.go", LOC: 60, Role: model.RoleCore},
+		{Path: "api/internal/flags.go", LOC: 20, Role: model.RoleCore, Deprecated: true},
+		{Path: "vendor/lib/lib.go", LOC: 900, Role: model.RoleVendor},
+		{Path: "tools/gen/main.go", LOC: 40, Role: model.RoleScripts},
+		{Path: "tools/gen/new.go", LOC: 10, Role: model.RoleScripts}, // not committed yet
+	}
+
+	result := FindAbandoned(records, activity, now, StaleOptions{StaleMonths: 12, InactiveMonths: 6})
+
+	reasons := make(map[string][]model.StaleReason)
+	for _, f := range result.Files {
+		reasons[f.Path] = f.Reasons
+	}
+	want := map[string][]model.StaleReason{
+		"legacy/report/pdf.go":  {model.StaleUntouched},
+		"legacy/report/csv.go":  {model.StaleUntouched},
+		"legacy/export.go":      {model.StaleUntouched, model.StaleInactiveAuthor},
+		"api/client.go":         {model.StaleInactiveAuthor},
+		"api/internal/flags.go": {model.StaleDeprecated},
+		"tools/gen/main.go":     {model.StaleUntouched},
+	}
+	if len(reasons) != len(want) {
+		t.Errorf("stale files = %v, want %v", reasons, want)
+	}
+	for p, w := range want {
+		if !slices.Equal(reasons[p], w) {
+			t.Errorf("%s reasons = %v, want %v", p, reasons[p], w)
+		}
+	}
+
+	// legacy/ is stale as a whole, so legacy/report/ is not listed on its
+	// own; tools/gen/ holds a new file and vendor/ is left out
+	if len(result.Dirs) != 1 {
+		t.Fatalf("stale dirs = %+v, want only legacy", result.Dirs)
+	}
+	if d := result.Dirs[0]; d.Path != "legacy" || d.Files != 3 || d.LOC != 450 || !d.LastModified.Equal(old) {
+		t.Errorf("stale dir = %+v, want legacy with 3 files, 450 LOC", d)
+	}
+}
+
+func TestParseActivity_Subdirectory(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	git := func(env []string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(), env...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	commit := func(email, date string) {
+		git([]string{"GIT_AUTHOR_DATE=" + date, "GIT_COMMITTER_DATE=" + date}, "add", ".")
+		git([]string{"GIT_AUTHOR_DATE=" + date, "GIT_COMMITTER_DATE=" + date},
+			"-c", "user.name=dev", "-c", "user.email="+email, "commit", "-q", "-m", "change")
+	}
+	write := func(name, content string) {
+		t.Helper()
+		p := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git(nil, "init", "-q")
+	write("services/api/server.go", "package api\n")
+	write("README.md", "readme\n")
+	commit("alice@example.com", "2024-01-15T10:00:00Z")
+	write("README.md", "readme, edited\n")
+	commit("bob@example.com", "2025-03-01T10:00:00Z")
+
+	activity, err := ParseActivity(filepath.Join(repo, "services"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a := activity.Files["api/server.go"]; a == nil || !a.Authors[hashAuthor("alice@example.com")] {
+		t.Errorf("api/server.go = %+v, want alice's commit under the scanned root's path", a)
+	}
+	if len(activity.Files) != 1 {
+		t.Errorf("Files = %v, want only the files below the scanned root", slices.Collect(maps.Keys(activity.Files)))
+	}
+	if got := activity.LastCommit[hashAuthor("bob@example.com")]; got.Year() != 2025 {
+		t.Errorf("bob's last commit = %v, want 2025 from outside the scanned root", got)
+	}
+}
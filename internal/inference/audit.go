@@ -0,0 +1,223 @@
+package inference
+
+import (
+	"cmp"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+// Audit classifies files like InferBatch and lists those with confidence
+// below threshold, or nearly tied between their top two roles, grouped by
+// directory with suggested overrides. Overridden files and assets are left out.
+func (e *Engine) Audit(files []*model.RawFile, threshold float32) *model.Audit {
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.Path
+	}
+	return buildAudit(files, e.Explain(files, paths), threshold)
+}
+
+// auditEntry pairs a scanned file with its classification trace
+type auditEntry struct {
+	file      *model.RawFile
+	ex        *model.Explanation
+	uncertain bool
+}
+
+func buildAudit(files []*model.RawFile, explanations []*model.Explanation, threshold float32) *model.Audit {
+	fileOf := make(map[string]*model.RawFile, len(files))
+	for _, f := range files {
+		fileOf[f.Path] = f
+	}
+
+	audit := &model.Audit{Threshold: threshold}
+	byDir := make(map[string][]auditEntry)
+	for _, ex := range explanations {
+		f := fileOf[ex.Path]
+		audit.TotalLOC += f.LOC
+		dir := path.Dir(filepath.ToSlash(ex.Path))
+		byDir[dir] = append(byDir[dir], auditEntry{file: f, ex: ex, uncertain: isUncertain(f, ex, threshold)})
+	}
+
+	for dir, entries := range byDir {
+		if group, ok := auditDir(dir, entries); ok {
+			audit.Groups = append(audit.Groups, group)
+			audit.Files += len(group.Files)
+			audit.LOC += group.LOC
+		}
+	}
+	slices.SortFunc(audit.Groups, func(a, b model.AuditGroup) int {
+		if c := cmp.Compare(b.LOC, a.LOC); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Dir, b.Dir)
+	})
+	return audit
+}
+
+// isUncertain reports whether a file's classification needs review
+func isUncertain(f *model.RawFile, ex *model.Explanation, threshold float32) bool {
+	if ex.Override != "" || f.Asset != nil {
+		return false
+	}
+	return ex.Confidence < threshold || isNearTie(ex)
+}
+
+// isNearTie reports whether the top two roles were within the ambiguity margin
+// and neighborhood inference did not settle on the runner-up
+func isNearTie(ex *model.Explanation) bool {
+	return ex.AmbiguityPenalty < 1 && ex.RunnerUp != "" && ex.RunnerUp != ex.Role
+}
+
+// auditDir lists the uncertain files in one directory. Each is suggested its
+// current role, unless it had no evidence or its runner-up is the role held
+// by a strict majority of its confident siblings.
+func auditDir(dir string, entries []auditEntry) (model.AuditGroup, bool) {
+	counts := make(map[model.Role]int)
+	confident := 0
+	for _, en := range entries {
+		if !en.uncertain && en.file.Asset == nil {
+			counts[en.ex.Role]++
+			confident++
+		}
+	}
+	var majority model.Role
+	for role, n := range counts {
+		if n*2 > confident {
+			majority = role
+		}
+	}
+
+	group := model.AuditGroup{Dir: dir}
+	for _, en := range entries {
+		if !en.uncertain {
+			continue
+		}
+		suggested := en.ex.Role
+		if majority != "" && (len(en.ex.Weights) == 0 || majority == en.ex.RunnerUp) {
+			suggested = majority
+		}
+		af := model.AuditFile{
+			Path:       filepath.ToSlash(en.ex.Path),
+			LOC:        en.file.LOC,
+			Role:       en.ex.Role,
+			SubRole:    en.ex.SubRole,
+			Confidence: en.ex.Confidence,
+			NearTie:    isNearTie(en.ex),
+			Suggested:  suggested,
+		}
+		if af.NearTie {
+			af.RunnerUp = en.ex.RunnerUp
+		}
+		group.Files = append(group.Files, af)
+		group.LOC += af.LOC
+	}
+	if len(group.Files) == 0 {
+		return group, false
+	}
+	slices.SortFunc(group.Files, func(a, b model.AuditFile) int {
+		if c := cmp.Compare(b.LOC, a.LOC); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Path, b.Path)
+	})
+
+	group.Suggestions = suggestOverrides(dir, entries, group.Files, majority)
+	return group, true
+}
+
+// roleKey is a role with one of its sub-roles
+type roleKey struct {
+	role    model.Role
+	subRole model.SubRole
+}
+
+// suggestOverrides proposes one override per suggested role and sub-role. A
+// file keeping its current role keeps its sub-role; one moved to the
+// directory majority gets the role's default. A glob over the directory, or
+// over its files with a shared suffix such as "_test.go", is used when every
+// file it matches would end up with that role and sub-role anyway;
+// otherwise the files are listed by path.
+func suggestOverrides(dir string, entries []auditEntry, files []model.AuditFile, majority model.Role) []model.OverrideSuggestion {
+	suggested := make(map[string]roleKey, len(files))
+	byRole := make(map[roleKey]*model.OverrideSuggestion)
+	var order []roleKey
+	for _, f := range files {
+		key := roleKey{role: f.Suggested}
+		if f.Suggested == f.Role {
+			key.subRole = f.SubRole
+		}
+		suggested[f.Path] = key
+		s, ok := byRole[key]
+		if !ok {
+			basis := "current role"
+			if f.Suggested == majority {
+				basis = "directory majority"
+			}
+			s = &model.OverrideSuggestion{Role: key.role, SubRole: key.subRole, Basis: basis}
+			byRole[key] = s
+			order = append(order, key)
+		}
+		s.Patterns = append(s.Patterns, f.Path)
+		s.Files++
+		s.LOC += f.LOC
+	}
+
+	suggestions := make([]model.OverrideSuggestion, 0, len(order))
+	for _, key := range order {
+		s := byRole[key]
+		if dir != "." && s.Files > 1 {
+			candidates := []string{dir + "/*"}
+			if suffix := commonSuffix(s.Patterns); suffix != "" {
+				candidates = append(candidates, dir+"/*"+suffix)
+			}
+			for _, pattern := range candidates {
+				if patternAgrees(entries, suggested, key, pattern) {
+					s.Patterns = []string{pattern}
+					break
+				}
+			}
+		}
+		suggestions = append(suggestions, *s)
+	}
+	return suggestions
+}
+
+// patternAgrees reports whether every file in a directory that pattern
+// matches would have the role and sub-role of key: the uncertain ones by
+// suggestion, the rest by their current classification
+func patternAgrees(entries []auditEntry, suggested map[string]roleKey, key roleKey, pattern string) bool {
+	for _, en := range entries {
+		if !matchGlob(pattern, en.ex.Path) {
+			continue
+		}
+		got := roleKey{role: en.ex.Role, subRole: en.ex.SubRole}
+		if en.uncertain {
+			got = suggested[filepath.ToSlash(en.ex.Path)]
+		}
+		if got != key {
+			return false
+		}
+	}
+	return true
+}
+
+// commonSuffix returns the longest file name suffix shared by paths that
+// starts at a "_", "-" or "." (such as "_test.go" or ".spec.ts"), or ""
+func commonSuffix(paths []string) string {
+	suffix := path.Base(paths[0])
+	for _, p := range paths[1:] {
+		name := path.Base(p)
+		for !strings.HasSuffix(name, suffix) {
+			suffix = suffix[1:]
+		}
+	}
+	if i := strings.IndexAny(suffix, "_-."); i >= 0 {
+		return suffix[i:]
+	}
+	return ""
+}
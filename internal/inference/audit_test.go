@@ -0,0 +1,127 @@
+package inference
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+func TestEngineAudit(t *testing.T) {
+	files := []*model.RawFile{
+		{Path: "pkg/a.go", LOC: 50, LanguageHint: "Go"},
+		{Path: "pkg/b.go", LOC: 40, LanguageHint: "Go"},
+		{Path: "pkg/a_test.go", LOC: 30, LanguageHint: "Go"},
+		{Path: "pkg/b_test.go", LOC: 20, LanguageHint: "Go"},
+		{Path: "docs/gen.go", LOC: 10, LanguageHint: "Go"},
+	}
+	engine := NewEngine(Options{Overrides: []Override{{Pattern: "docs/gen.go", Role: model.RoleGenerated}}})
+
+	audit := engine.Audit(files, 0.80)
+
+	if audit.Files != 4 || audit.LOC != 140 || audit.TotalLOC != 150 {
+		t.Errorf("audit = %d files, %d/%d LOC, want 4 files, 140/150 LOC", audit.Files, audit.LOC, audit.TotalLOC)
+	}
+	if len(audit.Groups) != 1 || audit.Groups[0].Dir != "pkg" {
+		t.Fatalf("groups = %+v, want only pkg (overridden files are not listed)", audit.Groups)
+	}
+
+	got := make(map[model.Role][]string)
+	for _, s := range audit.Groups[0].Suggestions {
+		got[s.Role] = s.Patterns
+	}
+	// *.go would also match the tests, so core files are listed by path
+	if want := []string{"pkg/a.go", "pkg/b.go"}; !slices.Equal(got[model.RoleCore], want) {
+		t.Errorf("core patterns = %v, want %v", got[model.RoleCore], want)
+	}
+	if want := []string{"pkg/*_test.go"}; !slices.Equal(got[model.RoleTest], want) {
+		t.Errorf("test patterns = %v, want %v", got[model.RoleTest], want)
+	}
+}
+
+func TestBuildAudit_DirectoryMajority(t *testing.T) {
+	confident := func(path string) *model.Explanation {
+		return &model.Explanation{Path: path, Role: model.RoleInfra, Confidence: 0.9, AmbiguityPenalty: 1,
+			Weights: map[model.Role]float32{model.RoleInfra: 0.9}}
+	}
+	explanations := []*model.Explanation{
+		confident("deploy/a.yaml"),
+		confident("deploy/b.yaml"),
+		// near tie with the directory majority: siblings break the tie
+		{Path: "deploy/values.yaml", Role: model.RoleConfig, RunnerUp: model.RoleInfra, Confidence: 0.5, AmbiguityPenalty: 0.8,
+			Weights: map[model.Role]float32{model.RoleConfig: 0.7, model.RoleInfra: 0.6}},
+		// no evidence at all
+		{Path: "deploy/run", Role: model.RoleCore, Confidence: 0.3, AmbiguityPenalty: 1},
+		// clear evidence for another role is kept
+		{Path: "deploy/README.md", Role: model.RoleDocs, Confidence: 0.6, AmbiguityPenalty: 1,
+			Weights: map[model.Role]float32{model.RoleDocs: 0.6}},
+	}
+	var files []*model.RawFile
+	for _, ex := range explanations {
+		files = append(files, &model.RawFile{Path: ex.Path, LOC: 10})
+	}
+
+	audit := buildAudit(files, explanations, 0.80)
+
+	if len(audit.Groups) != 1 {
+		t.Fatalf("groups = %+v, want one", audit.Groups)
+	}
+	suggested := make(map[string]model.Role)
+	for _, f := range audit.Groups[0].Files {
+		suggested[f.Path] = f.Suggested
+	}
+	tests := []struct {
+		path string
+		want model.Role
+	}{
+		{"deploy/values.yaml", model.RoleInfra},
+		{"deploy/run", model.RoleInfra},
+		{"deploy/README.md", model.RoleDocs},
+	}
+	for _, tt := range tests {
+		if suggested[tt.path] != tt.want {
+			t.Errorf("%s suggested = %q, want %q", tt.path, suggested[tt.path], tt.want)
+		}
+	}
+
+	for _, s := range audit.Groups[0].Suggestions {
+		if s.Role == model.RoleInfra {
+			if s.Basis != "directory majority" || !slices.Equal(s.Patterns, []string{"deploy/run", "deploy/values.yaml"}) {
+				t.Errorf("infra suggestion = %+v, want both files by path from the directory majority", s)
+			}
+		}
+	}
+}
+
+func TestBuildAudit_KeepsSubRole(t *testing.T) {
+	uncertain := func(path string, subRole model.TestKind) *model.Explanation {
+		return &model.Explanation{Path: path, Role: model.RoleTest, SubRole: subRole, Confidence: 0.6, AmbiguityPenalty: 1,
+			Weights: map[model.Role]float32{model.RoleTest: 0.6}}
+	}
+	explanations := []*model.Explanation{
+		uncertain("web/tests/login.spec.ts", model.TestE2E),
+		uncertain("web/tests/cart.spec.ts", model.TestE2E),
+		uncertain("web/tests/format.ts", model.TestUnit),
+	}
+	var files []*model.RawFile
+	for _, ex := range explanations {
+		files = append(files, &model.RawFile{Path: ex.Path, LOC: 10})
+	}
+
+	audit := buildAudit(files, explanations, 0.80)
+
+	got := make(map[model.TestKind][]string)
+	for _, s := range audit.Groups[0].Suggestions {
+		if s.Role != model.RoleTest {
+			t.Errorf("suggestion role = %s, want test", s.Role)
+		}
+		got[s.SubRole] = s.Patterns
+	}
+	// web/tests/* would also pin the unit test as e2e
+	if want := []string{"web/tests/*.spec.ts"}; !slices.Equal(got[model.TestE2E], want) {
+		t.Errorf("e2e patterns = %v, want %v", got[model.TestE2E], want)
+	}
+	if want := []string{"web/tests/format.ts"}; !slices.Equal(got[model.TestUnit], want) {
+		t.Errorf("unit patterns = %v, want %v", got[model.TestUnit], want)
+	}
+}
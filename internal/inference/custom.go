@@ -0,0 +1,227 @@
+package inference
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+// RuleKind selects what a custom rule is matched against
+type RuleKind string
+
+const (
+	RuleKindPath      RuleKind = "path"      // the file's relative path
+	RuleKindFilename  RuleKind = "filename"  // the base name
+	RuleKindExtension RuleKind = "extension" // the extension, including compound ones like .pb.go
+	RuleKindHeader    RuleKind = "header"    // the first 2KB of content (probed even without --deep)
+)
+
+// CustomRule is a user-defined weighted rule. It adds evidence to RoleScore
+// alongside the built-in rules of the same kind rather than replacing them.
+type CustomRule struct {
+	Kind      RuleKind
+	Pattern   string
+	MatchType string // filename rules: "suffix", "prefix", "contains" (default) or "exact"
+	Role      model.Role
+	SubRole   model.SubRole
+	Weight    float32
+
+	re *regexp.Regexp
+}
+
+// NewCustomRule validates a rule and compiles its pattern when regex is set.
+// Plain patterns match case-insensitively like the built-in rules; regex
+// patterns are used as written (prefix with (?i) to ignore case).
+func NewCustomRule(kind RuleKind, pattern, matchType string, regex bool, role model.Role, subRole model.SubRole, weight float32) (CustomRule, error) {
+	rule := CustomRule{
+		Kind:      kind,
+		Pattern:   pattern,
+		MatchType: matchType,
+		Role:      role,
+		SubRole:   subRole,
+		Weight:    weight,
+	}
+
+	switch kind {
+	case RuleKindPath, RuleKindFilename, RuleKindExtension, RuleKindHeader:
+	default:
+		return rule, fmt.Errorf("rule %q: unknown kind %q", pattern, kind)
+	}
+	if pattern == "" {
+		return rule, fmt.Errorf("%s rule: pattern is required", kind)
+	}
+	if !slices.Contains(model.AllRoles, role) {
+		return rule, fmt.Errorf("%s rule %q: unknown role %q", kind, pattern, role)
+	}
+	if subRole != "" && !slices.Contains(role.Info().SubRoles, subRole) {
+		return rule, fmt.Errorf("%s rule %q: sub-role %q is not one of role %s's %v", kind, pattern, subRole, role, role.Info().SubRoles)
+	}
+	if weight <= 0 || weight > 1 {
+		return rule, fmt.Errorf("%s rule %q: weight must be in (0, 1], got %v", kind, pattern, weight)
+	}
+
+	switch matchType {
+	case "":
+		rule.MatchType = "contains"
+	case "suffix", "prefix", "contains", "exact":
+	default:
+		return rule, fmt.Errorf("%s rule %q: unknown match %q", kind, pattern, matchType)
+	}
+
+	if regex {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return rule, fmt.Errorf("%s rule %q: %w", kind, pattern, err)
+		}
+		rule.re = re
+	}
+	return rule, nil
+}
+
+// matches reports whether the rule matches the given subject (path, base
+// name or header, depending on Kind)
+func (r *CustomRule) matches(subject string) bool {
+	if r.re != nil {
+		return r.re.MatchString(subject)
+	}
+	if r.Kind == RuleKindHeader {
+		// header markers are case-sensitive, like the built-in header rules
+		return strings.Contains(subject, r.Pattern)
+	}
+
+	subject = strings.ToLower(subject)
+	pattern := strings.ToLower(r.Pattern)
+	switch r.Kind {
+	case RuleKindExtension:
+		return strings.HasSuffix(subject, "."+strings.TrimPrefix(pattern, "."))
+	case RuleKindFilename:
+		switch r.MatchType {
+		case "suffix":
+			return strings.HasSuffix(subject, pattern)
+		case "prefix":
+			return strings.HasPrefix(subject, pattern)
+		case "exact":
+			return subject == pattern
+		}
+	}
+	return strings.Contains(subject, pattern)
+}
+
+// apply adds the rule's evidence to score if it matches
+func (r *CustomRule) apply(subject string, score *RoleScore) {
+	if r.matches(subject) {
+		score.addRule(r.Role, r.SubRole, r.Weight, ruleSignals[r.Kind], r.Pattern, true)
+	}
+}
+
+var ruleSignals = map[RuleKind]model.Signal{
+	RuleKindPath:      model.SignalPath,
+	RuleKindFilename:  model.SignalFilename,
+	RuleKindExtension: model.SignalExtension,
+	RuleKindHeader:    model.SignalHeader,
+}
+
+// DisabledRule identifies built-in rules to turn off: those of Kind whose
+// pattern equals Pattern (case-insensitive), or all of Kind if Pattern is empty
+type DisabledRule struct {
+	Kind    RuleKind
+	Pattern string
+}
+
+// ParseDisabledRule parses "kind:pattern" (e.g. "path:/bin/",
+// "filename:makefile") or a bare kind such as "header"
+func ParseDisabledRule(s string) (DisabledRule, error) {
+	kind, pattern, _ := strings.Cut(s, ":")
+	d := DisabledRule{Kind: RuleKind(strings.TrimSpace(kind)), Pattern: pattern}
+	if _, ok := ruleSignals[d.Kind]; !ok {
+		return d, fmt.Errorf("disable %q: unknown rule kind %q (want path, filename, extension or header)", s, kind)
+	}
+	return d, nil
+}
+
+// ruleSet holds the built-in rules left after disabling, with learned
+// weights applied, plus learned directory rules and custom rules
+type ruleSet struct {
+	path      []PathRule
+	filename  []FilenameRule
+	extension []ExtensionRule
+	header    []HeaderRule
+	learned   []PathRule // directory rules from a weights file, matched on "/"+path
+	custom    map[RuleKind][]CustomRule
+
+	// what the set was built from, to rebuild it with other weights
+	customRules []CustomRule
+	disabled    []DisabledRule
+	weights     *Weights
+}
+
+func newRuleSet(custom []CustomRule, disabled []DisabledRule, weights *Weights) *ruleSet {
+	learned := weights.lookup()
+	// enabled reports whether a built-in rule is kept and sets its weight
+	enabled := func(kind RuleKind, pattern string, role model.Role, weight *float32) bool {
+		for _, d := range disabled {
+			if d.Kind == kind && (d.Pattern == "" || strings.EqualFold(d.Pattern, pattern)) {
+				return false
+			}
+		}
+		if w, ok := learned[newWeightKey(kind, pattern, role)]; ok {
+			*weight = w
+		}
+		return *weight > 0
+	}
+
+	rs := &ruleSet{
+		custom:      make(map[RuleKind][]CustomRule),
+		customRules: custom,
+		disabled:    disabled,
+		weights:     weights,
+	}
+	for _, r := range PathRules {
+		if enabled(RuleKindPath, r.Fragment, r.Role, &r.Weight) {
+			rs.path = append(rs.path, r)
+		}
+	}
+	for _, r := range FilenameRules {
+		if enabled(RuleKindFilename, r.Pattern, r.Role, &r.Weight) {
+			rs.filename = append(rs.filename, r)
+		}
+	}
+	for _, r := range ExtensionRules {
+		if enabled(RuleKindExtension, r.Ext, r.Role, &r.Weight) {
+			rs.extension = append(rs.extension, r)
+		}
+	}
+	for _, r := range HeaderRules {
+		if enabled(RuleKindHeader, r.Pattern, r.Role, &r.Weight) {
+			rs.header = append(rs.header, r)
+		}
+	}
+	// path weights without a built-in rule are learned directory rules
+	builtin := builtinWeights()
+	for _, r := range weights.rules() {
+		key := newWeightKey(RuleKind(r.Kind), r.Pattern, r.Role)
+		if _, ok := builtin[key]; !ok && key.kind == RuleKindPath && r.Weight > 0 {
+			rs.learned = append(rs.learned, PathRule{Fragment: key.pattern, Role: r.Role, Weight: r.Weight})
+		}
+	}
+	for _, r := range custom {
+		rs.custom[r.Kind] = append(rs.custom[r.Kind], r)
+	}
+	return rs
+}
+
+// reweighted rebuilds the set from the same custom and disabled rules with
+// other learned weights
+func (rs *ruleSet) reweighted(weights *Weights) *ruleSet {
+	return newRuleSet(rs.customRules, rs.disabled, weights)
+}
+
+// applyCustom applies the custom rules of one kind
+func (rs *ruleSet) applyCustom(kind RuleKind, subject string, score *RoleScore) {
+	for i := range rs.custom[kind] {
+		rs.custom[kind][i].apply(subject, score)
+	}
+}
@@ -0,0 +1,152 @@
+package inference
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+func mustRule(t *testing.T, kind RuleKind, pattern, match string, regex bool, role model.Role, sub model.SubRole, weight float32) CustomRule {
+	t.Helper()
+	r, err := NewCustomRule(kind, pattern, match, regex, role, sub, weight)
+	if err != nil {
+		t.Fatalf("NewCustomRule(%s, %q) failed: %v", kind, pattern, err)
+	}
+	return r
+}
+
+func TestNewCustomRule_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    RuleKind
+		pattern string
+		match   string
+		regex   bool
+		role    model.Role
+		sub     model.SubRole
+		weight  float32
+	}{
+		{"unknown kind", "content", "x", "", false, model.RoleCore, "", 0.5},
+		{"empty pattern", RuleKindPath, "", "", false, model.RoleCore, "", 0.5},
+		{"unknown role", RuleKindPath, "/x/", "", false, "library", "", 0.5},
+		{"sub-role on non-test", RuleKindPath, "/x/", "", false, model.RoleCore, model.TestUnit, 0.5},
+		{"unknown sub-role", RuleKindPath, "/x/", "", false, model.RoleTest, "smoke", 0.5},
+		{"zero weight", RuleKindPath, "/x/", "", false, model.RoleCore, "", 0},
+		{"weight above one", RuleKindPath, "/x/", "", false, model.RoleCore, "", 1.5},
+		{"unknown match", RuleKindFilename, "x", "glob", false, model.RoleCore, "", 0.5},
+		{"bad regex", RuleKindPath, "(", "", true, model.RoleCore, "", 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewCustomRule(tt.kind, tt.pattern, tt.match, tt.regex, tt.role, tt.sub, tt.weight); err == nil {
+				t.Error("NewCustomRule succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestParseDisabledRule(t *testing.T) {
+	d, err := ParseDisabledRule("path:/bin/")
+	if err != nil || d.Kind != RuleKindPath || d.Pattern != "/bin/" {
+		t.Errorf("ParseDisabledRule(path:/bin/) = %+v, %v", d, err)
+	}
+	d, err = ParseDisabledRule("header")
+	if err != nil || d.Kind != RuleKindHeader || d.Pattern != "" {
+		t.Errorf("ParseDisabledRule(header) = %+v, %v", d, err)
+	}
+	if _, err := ParseDisabledRule("content:foo"); err == nil {
+		t.Error("ParseDisabledRule(content:foo) succeeded, want error")
+	}
+}
+
+func TestEngineInfer_CustomRules(t *testing.T) {
+	engine := NewEngine(Options{
+		Rules: []CustomRule{
+			mustRule(t, RuleKindPath, "/platform/", "", false, model.RoleInfra, "", 0.80),
+			mustRule(t, RuleKindFilename, `^check_.*\.py$`, "", true, model.RoleTest, model.TestIntegration, 0.90),
+			mustRule(t, RuleKindFilename, "Jenkinsfile", "exact", false, model.RoleInfra, "", 0.90),
+			mustRule(t, RuleKindExtension, "tmpl", "", false, model.RoleGenerated, "", 0.60),
+		},
+	})
+
+	tests := []struct {
+		path    string
+		role    model.Role
+		subRole model.SubRole
+		signal  model.Signal
+	}{
+		{"repo/platform/network.go", model.RoleInfra, "", model.SignalPath},
+		{"repo/qa/check_login.py", model.RoleTest, model.TestIntegration, model.SignalFilename},
+		{"repo/jenkinsfile", model.RoleInfra, "", model.SignalFilename},
+		{"repo/internal/page.tmpl", model.RoleGenerated, "", model.SignalExtension},
+	}
+	for _, tt := range tests {
+		record := engine.Infer(&model.RawFile{Path: tt.path, LOC: 10})
+		if record.Role != tt.role || record.SubRole != tt.subRole {
+			t.Errorf("%s: role = %v/%v, want %v/%v", tt.path, record.Role, record.SubRole, tt.role, tt.subRole)
+		}
+		found := false
+		for _, s := range record.Signals {
+			found = found || s == tt.signal
+		}
+		if !found {
+			t.Errorf("%s: signals = %v, want %v", tt.path, record.Signals, tt.signal)
+		}
+	}
+}
+
+func TestEngineInfer_CustomRulesCombineWithBuiltins(t *testing.T) {
+	// a weak custom rule does not outvote a strong built-in one
+	engine := NewEngine(Options{
+		Rules: []CustomRule{
+			mustRule(t, RuleKindPath, "/auth/", "", false, model.RoleCore, "", 0.30),
+		},
+	})
+	record := engine.Infer(&model.RawFile{Path: "repo/auth/login_test.go", LOC: 10})
+	if record.Role != model.RoleTest {
+		t.Errorf("Role = %v, want test", record.Role)
+	}
+}
+
+func TestEngineInfer_DisabledRules(t *testing.T) {
+	file := &model.RawFile{Path: "repo/bin/server.go", LOC: 10}
+
+	if record := NewEngine(Options{}).Infer(file); record.Role != model.RoleScripts {
+		t.Fatalf("Role = %v, want scripts with built-in rules", record.Role)
+	}
+
+	engine := NewEngine(Options{
+		DisabledRules: []DisabledRule{{Kind: RuleKindPath, Pattern: "/BIN/"}},
+	})
+	if record := engine.Infer(file); record.Role != model.RoleCore {
+		t.Errorf("Role = %v, want core with /bin/ disabled", record.Role)
+	}
+}
+
+func TestEngineInfer_CustomHeaderRule(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "client.go")
+	os.WriteFile(path, []byte("// Code owned by Platform Team\npackage client\n"), 0644)
+
+	// custom header rules probe even without HeaderProbe
+	engine := NewEngine(Options{
+		Rules: []CustomRule{
+			mustRule(t, RuleKindHeader, "Platform Team", "", false, model.RoleInfra, "", 0.85),
+		},
+	})
+	if record := engine.Infer(&model.RawFile{Path: path, LOC: 2}); record.Role != model.RoleInfra {
+		t.Errorf("Role = %v, want infra", record.Role)
+	}
+
+	// plain header patterns are case-sensitive
+	engine = NewEngine(Options{
+		Rules: []CustomRule{
+			mustRule(t, RuleKindHeader, "platform team", "", false, model.RoleInfra, "", 0.85),
+		},
+	})
+	if record := engine.Infer(&model.RawFile{Path: path, LOC: 2}); record.Role == model.RoleInfra {
+		t.Error("Role = infra, want header pattern to match case-sensitively")
+	}
+}
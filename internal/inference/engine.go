@@ -1,83 +1,329 @@
 package inference
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/modern-tooling/aloc/internal/model"
 )
 
 type Engine struct {
-	overrides         *Overrides
-	enableHeaderProbe bool
+	overrides          *Overrides
+	rules              *ruleSet
+	enableHeaderProbe  bool
 	enableNeighborhood bool
+	neighborhood       NeighborhoodWeights
+	goPrecise          bool
+	root               string
+	testConfigs        *testConfigs   // loaded per batch
+	vendored           []vendoredTree // found per batch
+	testPairs          []TestPair
 }
 
 type Options struct {
-	HeaderProbe   bool
-	Neighborhood  bool
-	Overrides     map[model.Role][]string
+	HeaderProbe         bool
+	Neighborhood        bool
+	NeighborhoodWeights *NeighborhoodWeights // nil uses DefaultNeighborhoodWeights
+	Overrides           []Override           // checked before all other rules
+	Rules               []CustomRule         // user-defined rules, applied alongside the built-ins
+	DisabledRules       []DisabledRule       // built-in rules to skip
+	GoPrecise           bool                 // parse Go files (generated markers, build tags, test functions, testdata/)
+	Root                string               // directory file paths are relative to, for reading content
+	Weights             *Weights             // learned rule weights (aloc learn); nil keeps the built-in weights
+	TestPairs           []TestPair           // test-to-source mappings, tried before the naming conventions
 }
 
 func NewEngine(opts Options) *Engine {
 	var overrides *Overrides
-	if opts.Overrides != nil {
+	if len(opts.Overrides) > 0 {
 		overrides = NewOverrides(opts.Overrides)
 	}
+	neighborhood := DefaultNeighborhoodWeights()
+	if opts.NeighborhoodWeights != nil {
+		neighborhood = *opts.NeighborhoodWeights
+	}
 	return &Engine{
 		overrides:          overrides,
+		rules:              newRuleSet(opts.Rules, opts.DisabledRules, opts.Weights),
 		enableHeaderProbe:  opts.HeaderProbe,
 		enableNeighborhood: opts.Neighborhood,
+		neighborhood:       neighborhood,
+		goPrecise:          opts.GoPrecise,
+		root:               opts.Root,
+		testPairs:          opts.TestPairs,
 	}
 }
 
 func (e *Engine) Infer(file *model.RawFile) *model.FileRecord {
-	score := NewRoleScore()
+	return e.infer(file, NewRoleScore())
+}
 
+func (e *Engine) infer(file *model.RawFile, score *RoleScore) *model.FileRecord {
 	// 1. Check overrides first (weight 1.0)
 	if e.overrides != nil {
 		if override := e.overrides.Match(file.Path); override != nil {
-			score.Add(override.Role, 1.0, model.SignalOverride)
-			return e.buildRecord(file, score)
+			score.addRule(override.Role, override.SubRole, 1.0, model.SignalOverride, override.Pattern, false)
+			record := e.buildRecord(file, score, nil)
+			if override.Language != "" {
+				record.Language = override.Language
+			}
+			if override.Confidence > 0 {
+				record.Confidence = override.Confidence
+			}
+			return record
 		}
 	}
 
+	// .gitattributes linguist attributes decide the role as GitHub does
+	if roles := e.applyAttributeRules(file, score); len(roles) > 0 {
+		score.skip("path, filename, extension, header and framework rules: decided by .gitattributes")
+		record := e.buildRecord(file, score, nil)
+		record.Confidence = attributeWeight
+		return record
+	}
+
 	// 2. Apply path rules
-	applyPathRules(file.Path, score)
+	e.applyPathRules(file.Path, score)
+
+	// 3. Apply filename rules, then recognize migrations, localization and
+	// schema files
+	e.applyFilenameRules(file.Path, score)
+	e.applyFamilyRules(file, score)
 
-	// 3. Apply filename rules
-	applyFilenameRules(file.Path, score)
+	// Vendored trees and known libraries
+	upstream := e.applyVendorRules(file, score)
 
 	// 4. Apply extension bias (only if not already decisive)
 	if score.MaxWeight() < 0.50 {
-		applyExtensionRules(file.Path, score)
+		e.applyExtensionRules(file.Path, score)
+	} else {
+		score.skip("extension rules: evidence already >= 0.50")
+	}
+
+	// 5. Apply header probe (optional; custom header rules always probe)
+	probe := e.enableHeaderProbe || len(e.rules.custom[RuleKindHeader]) > 0
+	switch {
+	case !probe:
+		score.skip("header rules: header probing disabled (--deep or --header-probe)")
+	case file.Asset != nil:
+		score.skip("header rules: binary asset")
+	case score.MaxWeight() >= 0.80:
+		score.skip("header rules: evidence already >= 0.80")
+	default:
+		e.applyHeaderRules(file, score)
+	}
+
+	// 6. Go-precise analysis (optional)
+	var testFuncs []model.RoleLOC
+	if e.goPrecise && file.Asset == nil {
+		testFuncs = e.applyGoRules(file, score)
+	}
+
+	// 7. Test frameworks: runner configs and imports of test files
+	framework := e.applyFrameworkRules(file, score)
+
+	record := e.buildRecord(file, score, testFuncs)
+	switch record.Role {
+	case model.RoleTest:
+		record.TestFramework = framework
+	case model.RoleVendor:
+		record.Upstream = upstream
 	}
+	return record
+}
 
-	// 5. Apply header probe (optional)
-	if e.enableHeaderProbe && score.MaxWeight() < 0.80 {
-		applyHeaderRules(file.Path, score)
+// attributeWeight is the weight and confidence of a role .gitattributes sets
+const attributeWeight = 0.95
+
+// applyAttributeRules adds the roles set by linguist-generated,
+// linguist-vendored and linguist-documentation, returning them, and rules
+// out the roles they unset
+func (e *Engine) applyAttributeRules(file *model.RawFile, score *RoleScore) []model.Role {
+	var roles []model.Role
+	flags := file.Linguist.RoleFlags()
+	for _, attr := range linguistAttributes {
+		set, ok := flags[attr.role]
+		switch {
+		case !ok:
+		case set:
+			score.addRule(attr.role, "", attributeWeight, model.SignalAttributes, attr.name, false)
+			roles = append(roles, attr.role)
+		default:
+			score.exclude(attr.role, fmt.Sprintf("%s rules: -%s in .gitattributes", attr.role, attr.name))
+		}
 	}
+	return roles
+}
 
-	return e.buildRecord(file, score)
+// linguistAttributes are the .gitattributes flags that assign a role, in
+// the order they are applied
+var linguistAttributes = []struct {
+	name string
+	role model.Role
+}{
+	{"linguist-vendored", model.RoleVendor},
+	{"linguist-generated", model.RoleGenerated},
+	{"linguist-documentation", model.RoleDocs},
+}
+
+// applyGoRules adds evidence from testdata/ paths and parsed Go source,
+// returning the LOC of Benchmark, Fuzz and Example functions
+func (e *Engine) applyGoRules(file *model.RawFile, score *RoleScore) []model.RoleLOC {
+	if isTestdata(file.Path) {
+		score.addRule(model.RoleTest, model.TestFixture, 0.85, model.SignalPath, "/testdata/", false)
+		return nil
+	}
+	if file.LanguageHint != "Go" {
+		return nil
+	}
+
+	src, err := e.content(file)
+	if err != nil {
+		return nil
+	}
+	facts := analyzeGo(file.Path, src)
+	if facts.generated {
+		score.addRule(model.RoleGenerated, "", 0.95, model.SignalSyntax, "// Code generated ... DO NOT EDIT.", false)
+	}
+	if facts.testKind != "" && strings.HasSuffix(file.Path, "_test.go") {
+		score.addRule(model.RoleTest, facts.testKind, 0.85, model.SignalSyntax, "//go:build "+facts.buildTag, false)
+	}
+	return facts.funcs
+}
+
+// abs resolves a scanned path against the engine's root
+func (e *Engine) abs(path string) string {
+	if e.root == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(e.root, path)
+}
+
+// UnmatchedOverrides returns the overrides that matched no file in the
+// batches inferred so far
+func (e *Engine) UnmatchedOverrides() []Override {
+	if e.overrides == nil {
+		return nil
+	}
+	return e.overrides.Unmatched()
 }
 
 func (e *Engine) InferBatch(files []*model.RawFile) []*model.FileRecord {
+	e.testConfigs = e.loadTestConfigs(files)
+	e.vendored = e.findVendoredTrees(files)
 	records := make([]*model.FileRecord, len(files))
-	for i, f := range files {
-		records[i] = e.Infer(f)
-	}
+	forEach(len(files), func(i int) {
+		records[i] = e.Infer(files[i])
+	})
 
 	// Second pass: neighborhood inference
 	if e.enableNeighborhood {
-		applyNeighborhoodInference(records)
+		applyNeighborhoodInference(records, e.neighborhood)
 	}
 
+	// Pair core files with their tests once roles are final
+	e.pairTests(records)
+
 	return records
 }
 
-func (e *Engine) buildRecord(file *model.RawFile, score *RoleScore) *model.FileRecord {
+// Explain classifies files like InferBatch and returns a trace for each file
+// whose path is listed in paths, in the order of paths. Unknown paths are
+// skipped. All files are needed so neighborhood effects match a full run.
+func (e *Engine) Explain(files []*model.RawFile, paths []string) []*model.Explanation {
+	wanted := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		wanted[p] = true
+	}
+	e.testConfigs = e.loadTestConfigs(files)
+	e.vendored = e.findVendoredTrees(files)
+
+	records := make([]*model.FileRecord, len(files))
+	traced := make([]*model.Explanation, len(files))
+	forEach(len(files), func(i int) {
+		f := files[i]
+		if !wanted[f.Path] {
+			records[i] = e.Infer(f)
+			return
+		}
+		score := NewRoleScore()
+		score.tracing = true
+		records[i] = e.infer(f, score)
+		traced[i] = newExplanation(f, score)
+	})
+	byPath := make(map[string]*model.Explanation)
+	recordOf := make(map[string]*model.FileRecord)
+	for i, ex := range traced {
+		if ex != nil {
+			byPath[ex.Path] = ex
+			recordOf[ex.Path] = records[i]
+		}
+	}
+
+	var effects map[*model.FileRecord]*model.NeighborhoodEffect
+	if e.enableNeighborhood {
+		effects = applyNeighborhoodInference(records, e.neighborhood)
+	}
+
+	explanations := make([]*model.Explanation, 0, len(paths))
+	for _, p := range paths {
+		ex := byPath[p]
+		if ex == nil {
+			continue
+		}
+		record := recordOf[p]
+		ex.Neighborhood = effects[record]
+		ex.Role = record.Role
+		ex.SubRole = record.SubRole
+		ex.Confidence = record.Confidence
+		ex.Signals = record.Signals
+		explanations = append(explanations, ex)
+	}
+	return explanations
+}
+
+func newExplanation(file *model.RawFile, score *RoleScore) *model.Explanation {
+	res := score.resolve()
+	weights := make(map[model.Role]float32, len(score.Weights))
+	for role, w := range score.Weights {
+		weights[role] = w
+	}
+	ex := &model.Explanation{
+		Path:             file.Path,
+		Language:         file.LanguageHint,
+		Rules:            score.trace,
+		Skipped:          score.skipped,
+		Weights:          weights,
+		RunnerUp:         res.runnerUp,
+		AmbiguityPenalty: res.ambiguityPenalty,
+		AgreementFactor:  res.agreementFactor,
+	}
+	if len(score.trace) > 0 && score.trace[0].Signal == model.SignalOverride {
+		ex.Override = score.trace[0].Pattern
+	}
+	return ex
+}
+
+// buildRecord resolves the score into a record. testFuncs are the LOC of Go
+// Benchmark, Fuzz and Example functions, split out of test files.
+func (e *Engine) buildRecord(file *model.RawFile, score *RoleScore, testFuncs []model.RoleLOC) *model.FileRecord {
 	role, subRole, confidence, signals := score.Resolve()
+	var split []model.RoleLOC
+	if inline := min(file.Lines.InlineTests, file.LOC); inline > 0 && splitsInlineTests(role) {
+		split = []model.RoleLOC{{Role: model.RoleTest, SubRole: model.TestUnit, LOC: inline}}
+	}
+	if role == model.RoleTest && len(testFuncs) > 0 && splitLOC(testFuncs) <= file.LOC {
+		split = testFuncs
+	}
+	if prose := min(file.Lines.Prose, file.LOC-splitLOC(split)); prose > 0 && splitsProse(role) {
+		split = append(split, model.RoleLOC{Role: model.RoleDocs, LOC: prose})
+	}
 	return &model.FileRecord{
 		Path:       file.Path,
 		LOC:        file.LOC,
@@ -88,21 +334,54 @@ func (e *Engine) buildRecord(file *model.RawFile, score *RoleScore) *model.FileR
 		Confidence: confidence,
 		Signals:    signals,
 		Embedded:   file.Embedded,
+		Bytes:      file.Bytes,
+		Asset:      file.Asset,
+		Split:      split,
+		Linguist:   file.Linguist,
+		Deprecated: slices.Contains(score.Signals[model.RoleDeprecated], model.SignalHeader),
 	}
 }
 
-func applyPathRules(path string, score *RoleScore) {
+func splitLOC(parts []model.RoleLOC) int {
+	total := 0
+	for _, p := range parts {
+		total += p.LOC
+	}
+	return total
+}
+
+// splitsInlineTests reports whether inline tests are split out of a file with
+// this role; vendored and generated files are attributed as a whole
+func splitsInlineTests(role model.Role) bool {
+	return role != model.RoleTest && role != model.RoleVendor && role != model.RoleGenerated
+}
+
+// splitsProse reports whether the prose of a literate file is split out to
+// docs; vendored and generated files keep all their lines
+func splitsProse(role model.Role) bool {
+	return role != model.RoleDocs && role != model.RoleVendor && role != model.RoleGenerated
+}
+
+func (e *Engine) applyPathRules(path string, score *RoleScore) {
 	lowerPath := strings.ToLower(path)
-	for _, rule := range PathRules {
+	for _, rule := range e.rules.path {
 		if strings.Contains(lowerPath, rule.Fragment) {
-			score.Add(rule.Role, rule.Weight, model.SignalPath)
+			score.addRule(rule.Role, "", rule.Weight, model.SignalPath, rule.Fragment, false)
+		}
+	}
+	// learned directory rules also match top-level directories
+	rooted := "/" + filepath.ToSlash(lowerPath)
+	for _, rule := range e.rules.learned {
+		if strings.Contains(rooted, rule.Fragment) {
+			score.addRule(rule.Role, "", rule.Weight, model.SignalPath, rule.Fragment, false)
 		}
 	}
+	e.rules.applyCustom(RuleKindPath, path, score)
 }
 
-func applyFilenameRules(path string, score *RoleScore) {
+func (e *Engine) applyFilenameRules(path string, score *RoleScore) {
 	filename := strings.ToLower(filepath.Base(path))
-	for _, rule := range FilenameRules {
+	for _, rule := range e.rules.filename {
 		var matched bool
 		switch rule.MatchType {
 		case "suffix":
@@ -113,33 +392,91 @@ func applyFilenameRules(path string, score *RoleScore) {
 			matched = strings.Contains(filename, strings.ToLower(rule.Pattern))
 		}
 		if matched {
-			score.AddWithSubRole(rule.Role, rule.SubRole, rule.Weight, model.SignalFilename)
+			score.addRule(rule.Role, rule.SubRole, rule.Weight, model.SignalFilename, rule.Pattern, false)
 		}
 	}
+	e.rules.applyCustom(RuleKindFilename, filepath.Base(path), score)
 }
 
-func applyExtensionRules(path string, score *RoleScore) {
+func (e *Engine) applyExtensionRules(path string, score *RoleScore) {
 	ext := strings.ToLower(filepath.Ext(path))
 	// Check compound extensions like .pb.go
 	base := filepath.Base(path)
-	for _, rule := range ExtensionRules {
+	for _, rule := range e.rules.extension {
 		if strings.HasSuffix(strings.ToLower(base), rule.Ext) || ext == rule.Ext {
-			score.Add(rule.Role, rule.Weight, model.SignalExtension)
+			score.addRule(rule.Role, "", rule.Weight, model.SignalExtension, rule.Ext, false)
 		}
 	}
+	e.rules.applyCustom(RuleKindExtension, base, score)
 }
 
-func applyHeaderRules(path string, score *RoleScore) {
-	header, err := readHeader(path, 2048)
+func (e *Engine) applyHeaderRules(file *model.RawFile, score *RoleScore) {
+	header, err := e.head(file, headerProbeBytes)
 	if err != nil {
 		return
 	}
 	content := string(header)
-	for _, rule := range HeaderRules {
-		if strings.Contains(content, rule.Pattern) {
-			score.Add(rule.Role, rule.Weight, model.SignalHeader)
+	if e.enableHeaderProbe {
+		for _, rule := range e.rules.header {
+			if strings.Contains(content, rule.Pattern) {
+				score.addRule(rule.Role, "", rule.Weight, model.SignalHeader, rule.Pattern, false)
+			}
 		}
 	}
+	e.rules.applyCustom(RuleKindHeader, content, score)
+}
+
+// headerProbeBytes is how much of a file the header rules look at
+const headerProbeBytes = 2048
+
+// HeaderBytes is how much of each file inference looks at: the header rules
+// read 2KB and test framework detection 8KB. Scanning with this HeaderSize
+// spares inference from opening files again.
+const HeaderBytes = frameworkProbeBytes
+
+// DataHeaderBytes is how much of YAML and JSON files inference looks at even
+// without header probing, to recognize documents by their shape
+const DataHeaderBytes = headerProbeBytes
+
+// readsHeaders reports whether inference reads file content beyond the
+// paths: with header probing, Go-precise mode or custom header rules. The
+// scanner should then capture HeaderBytes of each file.
+func (e *Engine) readsHeaders() bool {
+	return e.enableHeaderProbe || e.goPrecise || len(e.rules.custom[RuleKindHeader]) > 0
+}
+
+// head returns the first maxBytes of a file, from the header the scanner
+// captured when it covers them, else from disk
+func (e *Engine) head(file *model.RawFile, maxBytes int) ([]byte, error) {
+	if h := file.Header; h != nil && (len(h) >= maxBytes || int64(len(h)) >= file.Bytes) {
+		return h[:min(len(h), maxBytes)], nil
+	}
+	return readHeader(e.abs(file.Path), maxBytes)
+}
+
+// content returns a whole file, from the captured header when it holds all
+// of it, else from disk
+func (e *Engine) content(file *model.RawFile) ([]byte, error) {
+	if h := file.Header; h != nil && int64(len(h)) >= file.Bytes {
+		return h, nil
+	}
+	return os.ReadFile(e.abs(file.Path))
+}
+
+// forEach calls fn for every index below n on GOMAXPROCS workers
+func forEach(n int, fn func(i int)) {
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := int(next.Add(1)) - 1; i < n; i = int(next.Add(1)) - 1 {
+				fn(i)
+			}
+		}()
+	}
+	wg.Wait()
 }
 
 func readHeader(path string, maxBytes int) ([]byte, error) {
@@ -153,57 +490,3 @@ func readHeader(path string, maxBytes int) ([]byte, error) {
 	n, _ := f.Read(buf)
 	return buf[:n], nil
 }
-
-func applyNeighborhoodInference(records []*model.FileRecord) {
-	// Group by directory
-	byDir := make(map[string][]*model.FileRecord)
-	for _, r := range records {
-		dir := filepath.Dir(r.Path)
-		byDir[dir] = append(byDir[dir], r)
-	}
-
-	for _, dirRecords := range byDir {
-		if len(dirRecords) < 3 {
-			continue
-		}
-
-		// Count role distribution
-		roleCounts := make(map[model.Role]int)
-		var total int
-		for _, r := range dirRecords {
-			if r.Confidence >= 0.70 {
-				roleCounts[r.Role]++
-				total++
-			}
-		}
-
-		if total < 2 {
-			continue
-		}
-
-		// Find dominant role
-		var dominantRole model.Role
-		var dominantCount int
-		for role, count := range roleCounts {
-			if count > dominantCount {
-				dominantCount = count
-				dominantRole = role
-			}
-		}
-
-		// Apply to low-confidence files
-		dominantRatio := float32(dominantCount) / float32(total)
-		if dominantRatio >= 0.70 {
-			for _, r := range dirRecords {
-				if r.Confidence < 0.60 && r.Role != dominantRole {
-					r.Role = dominantRole
-					r.Confidence = r.Confidence + 0.40
-					if r.Confidence > 1.0 {
-						r.Confidence = 1.0
-					}
-					r.Signals = append(r.Signals, model.SignalNeighborhood)
-				}
-			}
-		}
-	}
-}
@@ -200,8 +200,8 @@ func TestEngineInfer_ExamplesFile(t *testing.T) {
 
 func TestEngineInfer_WithOverrides(t *testing.T) {
 	engine := NewEngine(Options{
-		Overrides: map[model.Role][]string{
-			model.RoleInfra: {"ops/**"},
+		Overrides: []Override{
+			{Pattern: "ops/**", Role: model.RoleInfra},
 		},
 	})
 
@@ -226,10 +226,46 @@ func TestEngineInfer_WithOverrides(t *testing.T) {
 	}
 }
 
+func TestEngineInfer_OverrideSubRoleLanguageConfidence(t *testing.T) {
+	engine := NewEngine(Options{
+		Overrides: []Override{
+			{Pattern: "scripts/*.inc", Role: model.RoleTest, SubRole: model.TestFixture, Language: "PHP", Confidence: 0.9},
+		},
+	})
+
+	record := engine.Infer(&model.RawFile{Path: "scripts/data.inc", LOC: 10, LanguageHint: "C"})
+
+	if record.Role != model.RoleTest || record.SubRole != model.TestFixture {
+		t.Errorf("Role = %v/%v, want test/fixture", record.Role, record.SubRole)
+	}
+	if record.Language != "PHP" {
+		t.Errorf("Language = %v, want PHP", record.Language)
+	}
+	if record.Confidence != 0.9 {
+		t.Errorf("Confidence = %v, want 0.9", record.Confidence)
+	}
+}
+
+func TestInferBatch_OverrideNotReclassifiedByNeighborhood(t *testing.T) {
+	engine := NewEngine(Options{
+		Neighborhood: true,
+		Overrides:    []Override{{Pattern: "web/helpers.ts", Role: model.RoleCore}},
+	})
+	files := []*model.RawFile{{Path: "web/helpers.ts", LOC: 10, LanguageHint: "TypeScript"}}
+	for _, name := range []string{"a", "b", "c"} {
+		files = append(files, &model.RawFile{Path: "web/" + name + "_e2e.spec.ts", LOC: 10, LanguageHint: "TypeScript"})
+	}
+	records := engine.InferBatch(files)
+
+	if records[0].Role != model.RoleCore {
+		t.Errorf("Role = %v, want core (overrides are final)", records[0].Role)
+	}
+}
+
 func TestEngineInfer_OverrideTakesPrecedence(t *testing.T) {
 	engine := NewEngine(Options{
-		Overrides: map[model.Role][]string{
-			model.RoleCore: {"/project/test/**"},
+		Overrides: []Override{
+			{Pattern: "/project/test/**", Role: model.RoleCore},
 		},
 	})
 
@@ -559,8 +595,8 @@ func TestNewEngine_NilOverrides(t *testing.T) {
 
 func TestNewEngine_WithOverrides(t *testing.T) {
 	engine := NewEngine(Options{
-		Overrides: map[model.Role][]string{
-			model.RoleInfra: {"deploy/**"},
+		Overrides: []Override{
+			{Pattern: "deploy/**", Role: model.RoleInfra},
 		},
 	})
 
@@ -1197,3 +1233,116 @@ func TestEngineInfer_PbDir(t *testing.T) {
 		t.Errorf("Role = %v, want generated (pb directory)", record.Role)
 	}
 }
+
+func TestEngineInfer_SplitsInlineTests(t *testing.T) {
+	engine := NewEngine(Options{})
+
+	file := &model.RawFile{
+		Path:  "/project/src/parser.rs",
+		LOC:   200,
+		Lines: model.LineMetrics{Code: 200, InlineTests: 60},
+	}
+	record := engine.Infer(file)
+	if record.Role != model.RoleCore {
+		t.Fatalf("Role = %v, want core", record.Role)
+	}
+	parts := record.RoleLOCs()
+	if len(parts) != 2 || parts[0].LOC != 140 || parts[1].Role != model.RoleTest || parts[1].LOC != 60 {
+		t.Errorf("RoleLOCs = %+v, want core 140 and test 60", parts)
+	}
+
+	// files already classified as tests or vendored are not split
+	for _, path := range []string{"/project/tests/parser.rs", "/project/vendor/lib.rs"} {
+		file.Path = path
+		if record := engine.Infer(file); len(record.Split) != 0 {
+			t.Errorf("%s: Split = %+v, want none", path, record.Split)
+		}
+	}
+}
+
+func TestEngineInfer_SplitsLiterateProse(t *testing.T) {
+	engine := NewEngine(Options{})
+
+	file := &model.RawFile{
+		Path:         "/project/src/Parser.lhs",
+		LOC:          120,
+		LanguageHint: "Literate Haskell",
+		Lines:        model.LineMetrics{Code: 120, Prose: 80},
+	}
+	record := engine.Infer(file)
+	parts := record.RoleLOCs()
+	if record.Role != model.RoleCore || len(parts) != 2 || parts[0].LOC != 40 || parts[1].Role != model.RoleDocs || parts[1].LOC != 80 {
+		t.Errorf("%s RoleLOCs = %+v, want core 40 and docs 80", record.Role, parts)
+	}
+
+	// literate documents are docs already
+	file.Path, file.LanguageHint = "/project/analysis/report.Rmd", "R Markdown"
+	if record := engine.Infer(file); record.Role != model.RoleDocs || len(record.Split) != 0 {
+		t.Errorf("report.Rmd: %s split %+v, want docs with no split", record.Role, record.Split)
+	}
+}
+
+func TestEngineInfer_HeaderFromScan(t *testing.T) {
+	// the scanner captured the whole file, so the engine never reads the disk
+	header := []byte("// Code generated by foo. DO NOT EDIT.\npackage api\n")
+	file := &model.RawFile{
+		Path:         "/nonexistent/api/types.go",
+		LOC:          1,
+		Bytes:        int64(len(header)),
+		LanguageHint: "Go",
+		Header:       header,
+	}
+
+	record := NewEngine(Options{HeaderProbe: true}).Infer(file)
+
+	if record.Role != model.RoleGenerated {
+		t.Errorf("Role = %v, want generated from the scanned header", record.Role)
+	}
+}
+
+func TestEngineInfer_DeprecationMarker(t *testing.T) {
+	header := []byte("// Deprecated: use v2.Client instead.\npackage client\n")
+	file := &model.RawFile{
+		Path:         "/nonexistent/client/client.go",
+		LOC:          40,
+		Bytes:        int64(len(header)),
+		LanguageHint: "Go",
+		Header:       header,
+	}
+
+	if record := NewEngine(Options{HeaderProbe: true}).Infer(file); !record.Deprecated {
+		t.Error("Deprecated = false, want true for a // Deprecated: header")
+	}
+	if record := NewEngine(Options{}).Infer(file); record.Deprecated {
+		t.Error("Deprecated = true without header probing, want false")
+	}
+}
+
+func TestEngineInfer_Gitattributes(t *testing.T) {
+	set, unset := true, false
+	tests := []struct {
+		name     string
+		path     string
+		linguist *model.Linguist
+		want     model.Role
+	}{
+		{"generated outweighs the test path", "pkg/tests/gen_test.go", &model.Linguist{Generated: &set}, model.RoleGenerated},
+		{"vendored", "lib/jquery.js", &model.Linguist{Vendored: &set}, model.RoleVendor},
+		{"documentation", "guide/setup.sh", &model.Linguist{Documentation: &set}, model.RoleDocs},
+		{"unset vendored rules out the vendor path", "src/third_party/ours/app.go", &model.Linguist{Vendored: &unset}, model.RoleCore},
+		{"unset documentation rules out docs", "site/docs/server.go", &model.Linguist{Documentation: &unset}, model.RoleCore},
+		{"language only changes nothing", "app/vendor/lib.go", &model.Linguist{Language: "Go"}, model.RoleVendor},
+	}
+	engine := NewEngine(Options{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := engine.Infer(&model.RawFile{Path: tt.path, LOC: 10, LanguageHint: "Go", Linguist: tt.linguist})
+			if record.Role != tt.want {
+				t.Errorf("Role = %v, want %v (signals %v)", record.Role, tt.want, record.Signals)
+			}
+			if set, ok := tt.linguist.RoleFlags()[tt.want]; ok && set && record.Confidence != attributeWeight {
+				t.Errorf("Confidence = %v, want %v for a role .gitattributes sets", record.Confidence, attributeWeight)
+			}
+		})
+	}
+}
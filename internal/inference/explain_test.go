@@ -0,0 +1,73 @@
+package inference
+
+import (
+	"testing"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+func TestExplain_TracesRulesAndFactors(t *testing.T) {
+	engine := NewEngine(Options{})
+	files := []*model.RawFile{
+		{Path: "repo/test/auth/login_test.go", LOC: 10, LanguageHint: "Go"},
+		{Path: "repo/internal/auth.go", LOC: 10, LanguageHint: "Go"},
+	}
+
+	explanations := engine.Explain(files, []string{"repo/test/auth/login_test.go", "repo/missing.go"})
+	if len(explanations) != 1 {
+		t.Fatalf("explanations = %d, want 1 (unknown paths skipped)", len(explanations))
+	}
+	ex := explanations[0]
+
+	if len(ex.Rules) != 2 {
+		t.Fatalf("Rules = %+v, want path and filename hits", ex.Rules)
+	}
+	if ex.Rules[0].Signal != model.SignalPath || ex.Rules[0].Pattern != "/test/" {
+		t.Errorf("Rules[0] = %+v, want path /test/", ex.Rules[0])
+	}
+	if ex.Rules[1].Signal != model.SignalFilename || ex.Rules[1].SubRole != model.TestUnit {
+		t.Errorf("Rules[1] = %+v, want filename rule with unit sub-role", ex.Rules[1])
+	}
+	if len(ex.Skipped) == 0 {
+		t.Error("Skipped is empty, want extension and header stages")
+	}
+	if ex.AmbiguityPenalty != 1 || ex.AgreementFactor != 0.5 {
+		t.Errorf("penalty, agreement = %v, %v, want 1, 0.5", ex.AmbiguityPenalty, ex.AgreementFactor)
+	}
+
+	// the trace must agree with a regular run
+	record := engine.Infer(files[0])
+	if ex.Role != record.Role || ex.Confidence != record.Confidence || ex.Weights[model.RoleTest] != 0.60+0.75 {
+		t.Errorf("explanation = %s %v %v, want %s %v", ex.Role, ex.Confidence, ex.Weights, record.Role, record.Confidence)
+	}
+}
+
+func TestExplain_Override(t *testing.T) {
+	engine := NewEngine(Options{
+		Overrides: []Override{{Pattern: "**/*.gen.go", Role: model.RoleGenerated}},
+	})
+	files := []*model.RawFile{{Path: "api/types.gen.go", LOC: 10}}
+
+	ex := engine.Explain(files, []string{"api/types.gen.go"})[0]
+	if ex.Override != "**/*.gen.go" || ex.Role != model.RoleGenerated {
+		t.Errorf("Override, Role = %q, %s, want **/*.gen.go, generated", ex.Override, ex.Role)
+	}
+}
+
+func TestExplain_Neighborhood(t *testing.T) {
+	engine := NewEngine(Options{Neighborhood: true})
+	files := []*model.RawFile{
+		{Path: "repo/checkout/cart_e2e.spec.ts", LOC: 10},
+		{Path: "repo/checkout/login_e2e.spec.ts", LOC: 10},
+		{Path: "repo/checkout/pay_e2e.spec.ts", LOC: 10},
+		{Path: "repo/checkout/helpers.ts", LOC: 10},
+	}
+
+	ex := engine.Explain(files, []string{"repo/checkout/helpers.ts"})[0]
+	if ex.Neighborhood == nil {
+		t.Fatalf("Neighborhood = nil, want effect (role %s, confidence %v)", ex.Role, ex.Confidence)
+	}
+	if ex.Neighborhood.Dir != "repo/checkout" || ex.Neighborhood.FromRole != model.RoleCore || ex.Role != model.RoleTest {
+		t.Errorf("Neighborhood = %+v, role %s, want core -> test in repo/checkout", ex.Neighborhood, ex.Role)
+	}
+}
@@ -0,0 +1,154 @@
+package inference
+
+import (
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+// fileFamily recognizes a family of files by name or shape, such as Flyway
+// migrations, gettext catalogs or Kubernetes manifests, and names its format
+// as the sub-role. Path is matched against the lower-case, slash-separated
+// path with a leading slash. Families with Content also need it in the
+// file's header, which is looked at for YAML and JSON documents and, when
+// headers are probed, for other files. The first family that matches a file
+// wins, so specific formats are listed before general ones.
+type fileFamily struct {
+	Name    string
+	Role    model.Role
+	Kind    model.SubRole
+	Signal  model.Signal
+	Path    *regexp.Regexp
+	Content *regexp.Regexp
+	Weight  float32
+}
+
+var fileFamilies = []fileFamily{
+	// Migrations
+	{"Flyway", model.RoleMigrations, model.MigrationFlyway, model.SignalFilename,
+		regexp.MustCompile(`/(?:[vu]\d+(?:[._]\d+)*|r)__[^/]+\.(?:sql|java|kt)$`), nil, 0.90},
+	{"Liquibase", model.RoleMigrations, model.MigrationLiquibase, model.SignalFilename,
+		regexp.MustCompile(`/db\.changelog[^/]*\.(?:xml|ya?ml|json|sql)$`), nil, 0.90},
+	{"Rails", model.RoleMigrations, model.MigrationRails, model.SignalPath,
+		regexp.MustCompile(`/db/migrate/\d{14}_[^/]+\.rb$`), nil, 0.90},
+	{"Alembic", model.RoleMigrations, model.MigrationAlembic, model.SignalPath,
+		regexp.MustCompile(`/(?:alembic|migrations)/versions/[^/]+\.py$`), nil, 0.90},
+	{"Django", model.RoleMigrations, model.MigrationDjango, model.SignalPath,
+		regexp.MustCompile(`/migrations/\d{4}_[^/]+\.py$`), nil, 0.90},
+	{"Prisma", model.RoleMigrations, model.MigrationPrisma, model.SignalPath,
+		regexp.MustCompile(`/prisma/migrations/[^/]+/migration\.sql$`), nil, 0.90},
+	{"up/down SQL", model.RoleMigrations, model.MigrationSQL, model.SignalFilename,
+		regexp.MustCompile(`/\d+_[^/]+\.(?:up|down)\.sql$`), nil, 0.85},
+	{"SQL in a migrations directory", model.RoleMigrations, model.MigrationSQL, model.SignalPath,
+		regexp.MustCompile(`/(?:migrations?|migrate)/(?:[^/]+/)*[^/]+\.sql$`), nil, 0.80},
+
+	// Localization
+	{"gettext", model.RoleL10n, model.L10nGettext, model.SignalFilename,
+		regexp.MustCompile(`\.pot?$`), nil, 0.85},
+	{"XLIFF", model.RoleL10n, model.L10nXLIFF, model.SignalFilename,
+		regexp.MustCompile(`\.(?:xliff|xlf)$`), nil, 0.90},
+	{"Apple strings", model.RoleL10n, model.L10nApple, model.SignalFilename,
+		regexp.MustCompile(`\.(?:strings|stringsdict|xcstrings)$`), nil, 0.90},
+	{"Android strings", model.RoleL10n, model.L10nAndroid, model.SignalPath,
+		regexp.MustCompile(`/res/values(?:-[^/]+)?/(?:strings|plurals)\.xml$`), nil, 0.85},
+	{"ARB", model.RoleL10n, model.L10nARB, model.SignalFilename,
+		regexp.MustCompile(`\.arb$`), nil, 0.85},
+	{"resource bundle", model.RoleL10n, model.L10nBundle, model.SignalFilename,
+		regexp.MustCompile(`/messages(?:_[a-z]{2,3}(?:_[a-z]{2})?)?\.properties$`), nil, 0.75},
+	{"locale directory", model.RoleL10n, model.L10nBundle, model.SignalPath,
+		regexp.MustCompile(`/(?:locales?|i18n|l10n|translations|lang)/(?:[^/]+/)*[^/]+\.(?:json|ya?ml|toml|properties)$`), nil, 0.80},
+
+	// Schemas
+	{"OpenAPI", model.RoleSchemas, model.SchemaOpenAPI, model.SignalFilename,
+		regexp.MustCompile(`/[^/]*(?:openapi|swagger)[^/]*\.(?:json|ya?ml)$`), nil, 0.90},
+	{"JSON Schema", model.RoleSchemas, model.SchemaJSON, model.SignalFilename,
+		regexp.MustCompile(`\.schema\.json$`), nil, 0.85},
+	{"GraphQL SDL", model.RoleSchemas, model.SchemaGraphQL, model.SignalFilename,
+		regexp.MustCompile(`\.graphqls$|/schema\.(?:graphql|gql)$`), nil, 0.85},
+	{"Avro", model.RoleSchemas, model.SchemaAvro, model.SignalFilename,
+		regexp.MustCompile(`\.(?:avsc|avdl|avpr)$`), nil, 0.90},
+
+	// Infrastructure
+	{"Kustomize", model.RoleInfra, model.InfraKubernetes, model.SignalFilename,
+		regexp.MustCompile(`/kustomization\.ya?ml$`), nil, 0.90},
+	{"Helm chart", model.RoleInfra, model.InfraHelm, model.SignalFilename,
+		regexp.MustCompile(`/chart\.ya?ml$`), nil, 0.90},
+	{"Compose", model.RoleInfra, model.InfraCompose, model.SignalFilename,
+		regexp.MustCompile(`/(?:docker-)?compose(?:[.-][^/]*)?\.ya?ml$`), nil, 0.85},
+	{"Ansible role", model.RoleInfra, model.InfraAnsible, model.SignalPath,
+		regexp.MustCompile(`/roles/[^/]+/(?:tasks|handlers|defaults|vars|meta)/[^/]+\.ya?ml$`), nil, 0.80},
+
+	// Schemas recognized by content
+	{"openapi: 3.x", model.RoleSchemas, model.SchemaOpenAPI, model.SignalHeader,
+		regexp.MustCompile(`\.(?:json|ya?ml)$`),
+		regexp.MustCompile(`(?m)^\s*["']?(?:openapi|swagger)["']?\s*:\s*["']?[23]\.`), 0.90},
+	{"$schema: json-schema.org", model.RoleSchemas, model.SchemaJSON, model.SignalHeader,
+		regexp.MustCompile(`\.(?:json|ya?ml)$`),
+		regexp.MustCompile(`["']?\$schema["']?\s*:\s*["']?https?://json-schema\.org/`), 0.85},
+	{"GraphQL type definitions", model.RoleSchemas, model.SchemaGraphQL, model.SignalHeader,
+		regexp.MustCompile(`\.(?:graphql|gql)$`),
+		regexp.MustCompile(`(?m)^\s*(?:extend\s+)?(?:type|interface|input|enum|scalar|union|schema|directive)\s`), 0.80},
+
+	// Infrastructure recognized by content
+	{"AWSTemplateFormatVersion", model.RoleInfra, model.InfraCloudFormation, model.SignalHeader,
+		regexp.MustCompile(`\.(?:json|ya?ml)$`),
+		regexp.MustCompile(`AWSTemplateFormatVersion|AWS::Serverless|"Type"\s*:\s*"AWS::\w+::|(?m)^\s+Type:\s*['"]?AWS::\w+::`), 0.95},
+	{"Helm template", model.RoleInfra, model.InfraHelm, model.SignalHeader,
+		regexp.MustCompile(`/templates/[^/]+\.ya?ml$`),
+		regexp.MustCompile(`\{\{`), 0.90},
+	{"apiVersion and kind", model.RoleInfra, model.InfraKubernetes, model.SignalHeader,
+		regexp.MustCompile(`\.(?:json|ya?ml)$`),
+		regexp.MustCompile(`(?ms)^apiVersion:\s*\S.*^kind:\s*\S|^kind:\s*\S.*^apiVersion:\s*\S|"apiVersion"\s*:\s*".*"kind"\s*:|"kind"\s*:\s*".*"apiVersion"\s*:`), 0.85},
+	{"on and jobs", model.RoleInfra, model.InfraGitHubActions, model.SignalHeader,
+		regexp.MustCompile(`\.ya?ml$`),
+		regexp.MustCompile(`(?ms)^["']?on["']?:.*^jobs:`), 0.85},
+	{"composite action", model.RoleInfra, model.InfraGitHubActions, model.SignalHeader,
+		regexp.MustCompile(`/action\.ya?ml$`),
+		regexp.MustCompile(`(?m)^runs:\s*$`), 0.85},
+	{"hosts and tasks", model.RoleInfra, model.InfraAnsible, model.SignalHeader,
+		regexp.MustCompile(`\.ya?ml$`),
+		regexp.MustCompile(`(?ms)^-?\s*hosts:\s*\S.*^\s*(?:tasks|roles|pre_tasks|post_tasks|handlers):|(?m)^-\s+(?:ansible\.builtin\.)?import_playbook:`), 0.85},
+	{"services with images", model.RoleInfra, model.InfraCompose, model.SignalHeader,
+		regexp.MustCompile(`\.ya?ml$`),
+		regexp.MustCompile(`(?ms)^services:\s*$.*^\s+(?:image|build):`), 0.75},
+}
+
+// isDataDocument reports whether a path is a YAML or JSON file, whose
+// content is looked at without header probing: its shape decides its role
+func isDataDocument(p string) bool {
+	switch path.Ext(p) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// applyFamilyRules adds the evidence of the first file family matching a
+// file. Content is only read for files whose path fits a content family.
+func (e *Engine) applyFamilyRules(file *model.RawFile, score *RoleScore) {
+	p := "/" + strings.ToLower(filepath.ToSlash(file.Path))
+	var header string
+	var read bool
+	for _, f := range fileFamilies {
+		if !f.Path.MatchString(p) {
+			continue
+		}
+		if f.Content != nil {
+			if !e.enableHeaderProbe && !isDataDocument(p) || file.Asset != nil {
+				continue
+			}
+			if !read {
+				h, _ := e.head(file, headerProbeBytes)
+				header, read = string(h), true
+			}
+			if !f.Content.MatchString(header) {
+				continue
+			}
+		}
+		score.addRule(f.Role, f.Kind, f.Weight, f.Signal, f.Name, false)
+		return
+	}
+}
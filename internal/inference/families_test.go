@@ -0,0 +1,128 @@
+package inference
+
+import (
+	"testing"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+func TestEngineInfer_FileFamilies(t *testing.T) {
+	tests := []struct {
+		path    string
+		role    model.Role
+		subRole model.SubRole
+	}{
+		{"src/main/resources/db/migration/V1_2__add_users.sql", model.RoleMigrations, model.MigrationFlyway},
+		{"db/migration/R__views.sql", model.RoleMigrations, model.MigrationFlyway},
+		{"db/changelog/db.changelog-master.yaml", model.RoleMigrations, model.MigrationLiquibase},
+		{"db/migrate/20240101120000_create_users.rb", model.RoleMigrations, model.MigrationRails},
+		{"shop/orders/migrations/0002_order_total.py", model.RoleMigrations, model.MigrationDjango},
+		{"app/alembic/versions/3f2a1b_add_index.py", model.RoleMigrations, model.MigrationAlembic},
+		{"prisma/migrations/20240101_init/migration.sql", model.RoleMigrations, model.MigrationPrisma},
+		{"store/000001_create_users.up.sql", model.RoleMigrations, model.MigrationSQL},
+		{"sql/migrations/2024/add_orders.sql", model.RoleMigrations, model.MigrationSQL},
+		{"app/migrations/runner.go", model.RoleMigrations, ""},
+		{"queries/report.sql", model.RoleCore, ""},
+
+		{"po/fr.po", model.RoleL10n, model.L10nGettext},
+		{"src/locale/messages.de.xlf", model.RoleL10n, model.L10nXLIFF},
+		{"App/en.lproj/Localizable.strings", model.RoleL10n, model.L10nApple},
+		{"app/src/main/res/values-fr/strings.xml", model.RoleL10n, model.L10nAndroid},
+		{"lib/l10n/app_en.arb", model.RoleL10n, model.L10nARB},
+		{"src/main/resources/messages_pt_BR.properties", model.RoleL10n, model.L10nBundle},
+		{"config/locales/en.yml", model.RoleL10n, model.L10nBundle},
+		{"web/src/i18n/translator.ts", model.RoleCore, ""},
+
+		{"api/openapi.yaml", model.RoleSchemas, model.SchemaOpenAPI},
+		{"docs/swagger.json", model.RoleSchemas, model.SchemaOpenAPI},
+		{"schemas/order.schema.json", model.RoleSchemas, model.SchemaJSON},
+		{"graph/schema.graphqls", model.RoleSchemas, model.SchemaGraphQL},
+		{"events/order.avsc", model.RoleSchemas, model.SchemaAvro},
+	}
+
+	engine := NewEngine(Options{})
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			record := engine.Infer(&model.RawFile{Path: tt.path, LOC: 10})
+			if record.Role != tt.role || record.SubRole != tt.subRole {
+				t.Errorf("role = %v/%v, want %v/%v", record.Role, record.SubRole, tt.role, tt.subRole)
+			}
+		})
+	}
+}
+
+func TestEngineInfer_FileFamiliesByContent(t *testing.T) {
+	tests := []struct {
+		path    string
+		content string
+		role    model.Role
+		subRole model.SubRole
+	}{
+		{"api/v1/spec.yaml", "# Orders API\nopenapi: 3.0.3\ninfo:\n  title: Orders\n", model.RoleSchemas, model.SchemaOpenAPI},
+		{"api/legacy.json", "{\n  \"swagger\": \"2.0\",\n  \"info\": {}\n}\n", model.RoleSchemas, model.SchemaOpenAPI},
+		{"contracts/order.json", "{\n  \"$schema\": \"https://json-schema.org/draft/2020-12/schema\",\n  \"type\": \"object\"\n}\n", model.RoleSchemas, model.SchemaJSON},
+		{"graph/types.graphql", "\"\"\"An order\"\"\"\ntype Order {\n  id: ID!\n}\n", model.RoleSchemas, model.SchemaGraphQL},
+		{"web/queries/orders.graphql", "query Orders {\n  orders { id }\n}\n", model.RoleCore, ""},
+		{"tsconfig.json", "{\n  \"$schema\": \"https://json.schemastore.org/tsconfig\"\n}\n", model.RoleConfig, ""},
+		// a CRD's openAPIV3Schema is not an OpenAPI document
+		{"k8s/crd.yaml", "kind: CustomResourceDefinition\nspec:\n  versions:\n    - schema:\n        openAPIV3Schema:\n", model.RoleConfig, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			file := &model.RawFile{Path: "/nonexistent/" + tt.path, LOC: 4, Bytes: int64(len(tt.content)), Header: []byte(tt.content)}
+			record := NewEngine(Options{HeaderProbe: true}).Infer(file)
+			if record.Role != tt.role || record.SubRole != tt.subRole {
+				t.Errorf("role = %v/%v, want %v/%v", record.Role, record.SubRole, tt.role, tt.subRole)
+			}
+		})
+	}
+
+	// without header probing only YAML and JSON content is looked at
+	sdl := "type Order {\n  id: ID!\n}\n"
+	file := &model.RawFile{Path: "/nonexistent/graph/types.graphql", LOC: 3, Bytes: int64(len(sdl)), Header: []byte(sdl)}
+	if record := NewEngine(Options{}).Infer(file); record.Role == model.RoleSchemas {
+		t.Errorf("role = %v without header probing, want GraphQL content ignored", record.Role)
+	}
+	spec := "openapi: 3.0.3\n"
+	file = &model.RawFile{Path: "/nonexistent/api/v1/spec.yaml", LOC: 1, Bytes: int64(len(spec)), Header: []byte(spec)}
+	if record := NewEngine(Options{}).Infer(file); record.Role != model.RoleSchemas {
+		t.Errorf("role = %v without header probing, want schemas from the YAML content", record.Role)
+	}
+}
+
+func TestEngineInfer_DataDocumentShapes(t *testing.T) {
+	tests := []struct {
+		path    string
+		content string
+		role    model.Role
+		subRole model.SubRole
+	}{
+		{"k8s/api.yaml", "# the API\napiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: api\n", model.RoleInfra, model.InfraKubernetes},
+		{"k8s/svc.json", "{\n  \"kind\": \"Service\",\n  \"apiVersion\": \"v1\"\n}\n", model.RoleInfra, model.InfraKubernetes},
+		{"charts/api/templates/deployment.yaml", "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: {{ .Release.Name }}\n", model.RoleInfra, model.InfraHelm},
+		{"charts/api/Chart.yaml", "apiVersion: v2\nname: api\nversion: 0.1.0\n", model.RoleInfra, model.InfraHelm},
+		{"aws/stack.yaml", "AWSTemplateFormatVersion: '2010-09-09'\nResources:\n  Bucket:\n    Type: AWS::S3::Bucket\n", model.RoleInfra, model.InfraCloudFormation},
+		{"aws/stack.json", "{\n  \"Resources\": {\n    \"Bucket\": {\"Type\": \"AWS::S3::Bucket\"}\n  }\n}\n", model.RoleInfra, model.InfraCloudFormation},
+		{"ops/site.yml", "- name: web servers\n  hosts: web\n  tasks:\n    - name: install nginx\n", model.RoleInfra, model.InfraAnsible},
+		{"ops/roles/web/tasks/main.yml", "- name: install nginx\n  apt: name=nginx\n", model.RoleInfra, model.InfraAnsible},
+		{"build/ci/release.yml", "name: release\non:\n  push:\njobs:\n  build:\n    runs-on: ubuntu-latest\n", model.RoleInfra, model.InfraGitHubActions},
+		{"actions/setup/action.yml", "name: setup\nruns:\n  using: composite\n", model.RoleInfra, model.InfraGitHubActions},
+		{"compose.yaml", "services:\n  db:\n    image: postgres\n", model.RoleInfra, model.InfraCompose},
+		{"stack/dev.yml", "services:\n  web:\n    build: .\n", model.RoleInfra, model.InfraCompose},
+		{"app/settings.yaml", "server:\n  port: 8080\n", model.RoleConfig, ""},
+		{"package.json", "{\n  \"name\": \"app\"\n}\n", model.RoleConfig, ""},
+	}
+
+	// YAML and JSON are classified by shape without header probing
+	engine := NewEngine(Options{})
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			file := &model.RawFile{Path: "/nonexistent/" + tt.path, LOC: 4, Bytes: int64(len(tt.content)), Header: []byte(tt.content)}
+			record := engine.Infer(file)
+			if record.Role != tt.role || record.SubRole != tt.subRole {
+				t.Errorf("role = %v/%v, want %v/%v", record.Role, record.SubRole, tt.role, tt.subRole)
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package inference
+
+import (
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+// testFramework identifies a test framework by strings in a test file's
+// imports. Frameworks that imply a sub-role are listed before the unit test
+// frameworks, which only name the framework: unit is already the default.
+type testFramework struct {
+	Name    string
+	Kind    model.TestKind
+	Markers []string
+}
+
+var testFrameworks = []testFramework{
+	{"Playwright", model.TestE2E, []string{"@playwright/test", "from playwright", "import playwright", "com.microsoft.playwright"}},
+	{"Cypress", model.TestE2E, []string{`types="cypress"`, "cy.visit(", "from 'cypress'", `from "cypress"`}},
+	{"Selenium", model.TestE2E, []string{"org.openqa.selenium", "from selenium", "import selenium", "selenium-webdriver", "webdriverio"}},
+	{"Capybara", model.TestE2E, []string{"capybara", "Capybara"}},
+	{"Pact", model.TestContract, []string{"@pact-foundation/pact", "from pact", "import pact", "au.com.dius.pact", "pact_helper"}},
+	{"Testcontainers", model.TestIntegration, []string{"testcontainers", "Testcontainers"}},
+	{"Spring Boot Test", model.TestIntegration, []string{"@SpringBootTest"}},
+	{"Vitest", model.TestUnit, []string{"from 'vitest'", `from "vitest"`}},
+	{"Jest", model.TestUnit, []string{"@jest/globals", "jest.fn(", "jest.mock(", "jest.spyOn("}},
+	{"Mocha", model.TestUnit, []string{"from 'mocha'", "require('mocha')", `require("mocha")`}},
+	{"pytest", model.TestUnit, []string{"import pytest", "from pytest"}},
+	{"unittest", model.TestUnit, []string{"import unittest", "from unittest"}},
+	{"JUnit", model.TestUnit, []string{"org.junit"}},
+	{"TestNG", model.TestUnit, []string{"org.testng"}},
+	{"RSpec", model.TestUnit, []string{"RSpec.describe", "require 'rspec'", `require "rspec"`, "require 'spec_helper'", `require "spec_helper"`}},
+	{"Minitest", model.TestUnit, []string{"minitest", "Minitest"}},
+}
+
+// frameworkProbeBytes is how much of a test file is read for imports
+const frameworkProbeBytes = 8192
+
+// detectTestFramework returns the first framework whose markers appear in content
+func detectTestFramework(content string) (testFramework, bool) {
+	for _, fw := range testFrameworks {
+		for _, m := range fw.Markers {
+			if strings.Contains(content, m) {
+				return fw, true
+			}
+		}
+	}
+	return testFramework{}, false
+}
+
+// testDir classifies a directory from a test runner config
+type testDir struct {
+	Dir       string // slash-separated, relative to the root
+	Kind      model.TestKind
+	Framework string
+	Source    string // config file the rule came from
+}
+
+// testConfigs holds what the repository's test runner configs say
+type testConfigs struct {
+	dirs         []testDir
+	pytestMarks  []pytestMark
+	pytestSource string
+}
+
+// pytestMark is a marker declared in pytest config that names a test kind
+type pytestMark struct {
+	Name string
+	Kind model.TestKind
+}
+
+var (
+	// testDir: './e2e' in playwright.config.* and similar
+	configTestDirRE = regexp.MustCompile(`testDir\s*:\s*['"]([^'"]+)['"]`)
+	// specPattern: 'cypress/e2e/**/*.cy.ts' in cypress.config.*
+	configSpecPatternRE = regexp.MustCompile(`specPattern\s*:\s*['"]([^'"]+)['"]`)
+	// displayName: 'integration' in jest.config.* projects
+	jestDisplayNameRE = regexp.MustCompile(`displayName\s*:\s*(?:\{\s*name\s*:\s*)?['"]([^'"]+)['"]`)
+	// the first path in a project's roots or testMatch
+	jestProjectPathRE = regexp.MustCompile(`(?:roots|testMatch)\s*:\s*\[\s*['"]([^'"]+)['"]`)
+	// "name: description" or "name" lines under pytest markers
+	pytestMarkerRE = regexp.MustCompile(`^\s+["']?([A-Za-z_][A-Za-z0-9_]*)`)
+)
+
+// loadTestConfigs reads the test runner configs among files
+func (e *Engine) loadTestConfigs(files []*model.RawFile) *testConfigs {
+	cfg := &testConfigs{}
+	for _, f := range files {
+		slashPath := filepath.ToSlash(f.Path)
+		base := strings.ToLower(path.Base(slashPath))
+		dir := path.Dir(slashPath)
+		if dir == "." {
+			dir = ""
+		}
+
+		var read func(content string)
+		switch {
+		case strings.HasPrefix(base, "playwright.config."):
+			read = func(content string) {
+				if m := configTestDirRE.FindStringSubmatch(content); m != nil {
+					cfg.addDir(dir, m[1], model.TestE2E, "Playwright", slashPath)
+				}
+			}
+		case strings.HasPrefix(base, "cypress.config."):
+			read = func(content string) {
+				if m := configSpecPatternRE.FindStringSubmatch(content); m != nil {
+					cfg.addDir(dir, m[1], model.TestE2E, "Cypress", slashPath)
+				} else {
+					cfg.addDir(dir, "cypress", model.TestE2E, "Cypress", slashPath)
+				}
+			}
+		case strings.HasPrefix(base, "jest.config."):
+			read = func(content string) { cfg.readJestProjects(dir, content, slashPath) }
+		case base == "pytest.ini" || base == "setup.cfg" || base == "tox.ini" || base == "pyproject.toml":
+			read = func(content string) { cfg.readPytestMarkers(content, slashPath) }
+		default:
+			continue
+		}
+
+		content, err := e.content(f)
+		if err == nil {
+			read(string(content))
+		}
+	}
+	return cfg
+}
+
+// addDir records a test directory given relative to a config's directory.
+// Glob patterns are cut at the first wildcard.
+func (c *testConfigs) addDir(configDir, rel string, kind model.TestKind, framework, source string) {
+	rel = strings.TrimPrefix(rel, "<rootDir>")
+	if i := strings.IndexAny(rel, "*?[{"); i >= 0 {
+		rel = path.Dir(rel[:i] + "x")
+	}
+	base := configDir
+	if base == "" {
+		base = "."
+	}
+	dir := path.Join(base, strings.Trim(rel, "/"))
+	if dir == base || dir == "." || strings.HasPrefix(dir, "../") {
+		return // the config's whole tree is too broad to classify
+	}
+	c.dirs = append(c.dirs, testDir{Dir: dir, Kind: kind, Framework: framework, Source: source})
+}
+
+// readJestProjects maps jest projects named after a test kind to their roots
+func (c *testConfigs) readJestProjects(configDir, content, source string) {
+	names := jestDisplayNameRE.FindAllStringSubmatchIndex(content, -1)
+	for i, m := range names {
+		kind := kindFromName(content[m[2]:m[3]])
+		if kind == "" || kind == model.TestUnit {
+			continue
+		}
+		end := len(content)
+		if i+1 < len(names) {
+			end = names[i+1][0]
+		}
+		// roots and testMatch usually follow displayName within the project
+		if p := jestProjectPathRE.FindStringSubmatch(content[m[1]:end]); p != nil {
+			c.addDir(configDir, p[1], kind, "Jest", source)
+		}
+	}
+}
+
+// readPytestMarkers reads the markers option of pytest.ini, setup.cfg,
+// tox.ini or pyproject.toml, keeping markers named after a test kind
+func (c *testConfigs) readPytestMarkers(content, source string) {
+	inPytest, inMarkers := false, false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			inPytest = trimmed == "[pytest]" || trimmed == "[tool:pytest]" || trimmed == "[tool.pytest.ini_options]"
+			inMarkers = false
+			continue
+		}
+		if !inPytest {
+			continue
+		}
+		if key, value, ok := strings.Cut(trimmed, "="); ok && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			inMarkers = strings.TrimSpace(key) == "markers"
+			line = "  " + strings.Trim(strings.TrimSpace(value), "[")
+		}
+		if !inMarkers {
+			continue
+		}
+		if m := pytestMarkerRE.FindStringSubmatch(line); m != nil {
+			if kind := kindFromName(m[1]); kind != "" {
+				c.pytestMarks = append(c.pytestMarks, pytestMark{Name: m[1], Kind: kind})
+				c.pytestSource = source
+			}
+		}
+	}
+}
+
+// kindFromName maps names such as "integration", "e2e_browser" or
+// "contract-tests" to a test kind
+func kindFromName(name string) model.TestKind {
+	name = strings.ToLower(name)
+	switch {
+	case strings.Contains(name, "e2e") || strings.Contains(name, "end-to-end") || strings.Contains(name, "end_to_end"):
+		return model.TestE2E
+	case strings.Contains(name, "integration"):
+		return model.TestIntegration
+	case strings.Contains(name, "contract"):
+		return model.TestContract
+	case strings.Contains(name, "unit"):
+		return model.TestUnit
+	}
+	return ""
+}
+
+// applyFrameworkRules adds evidence from test runner configs and, when file
+// headers are read, from the framework imports of files that already look
+// like tests. It returns the framework found, if any.
+func (e *Engine) applyFrameworkRules(file *model.RawFile, score *RoleScore) string {
+	framework := ""
+	if e.testConfigs != nil {
+		slashPath := filepath.ToSlash(file.Path)
+		for _, d := range e.testConfigs.dirs {
+			if strings.HasPrefix(slashPath, d.Dir+"/") {
+				score.addRule(model.RoleTest, d.Kind, 0.75, model.SignalPath, d.Dir+"/ ("+d.Source+")", false)
+				framework = d.Framework
+				break
+			}
+		}
+	}
+
+	// quick mode stays path-only: imports are read with the header rules
+	if !e.readsHeaders() || file.Asset != nil || score.resolve().role != model.RoleTest {
+		return framework
+	}
+	header, err := e.head(file, frameworkProbeBytes)
+	if err != nil {
+		return framework
+	}
+	content := string(header)
+
+	if fw, ok := detectTestFramework(content); ok {
+		if fw.Kind != model.TestUnit {
+			score.addRule(model.RoleTest, fw.Kind, 0.80, model.SignalHeader, fw.Name+" import", false)
+		}
+		framework = fw.Name
+	}
+	if e.testConfigs != nil {
+		for _, mark := range e.testConfigs.pytestMarks {
+			if strings.Contains(content, "pytest.mark."+mark.Name) {
+				score.addRule(model.RoleTest, mark.Kind, 0.80, model.SignalHeader, "pytest.mark."+mark.Name+" ("+e.testConfigs.pytestSource+")", false)
+				break
+			}
+		}
+	}
+	return framework
+}
@@ -0,0 +1,103 @@
+package inference
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+func TestInferBatch_TestFrameworks(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"web/playwright.config.ts": "export default defineConfig({\n  testDir: './e2e',\n})\n",
+		"web/e2e/pages/login.ts":   "export class LoginPage {}\n",
+		"web/src/cart.test.ts":     "import { describe, it } from 'vitest'\n",
+		"web/src/api.pact.test.ts": "import { PactV3 } from '@pact-foundation/pact'\n",
+		"jest.config.js": `module.exports = {
+  projects: [
+    { displayName: 'unit', testMatch: ['<rootDir>/src/**/*.test.js'] },
+    { displayName: 'integration', testMatch: ['<rootDir>/tests/integration/**/*.test.js'] },
+  ],
+}
+`,
+		"tests/integration/db.js": "const { setup } = require('./setup')\n",
+		"pytest.ini":              "[pytest]\nmarkers =\n    slow: slow tests\n    integration_db: needs a database\n",
+		"py/tests/test_orders.py": "import pytest\n\n@pytest.mark.integration_db\ndef test_orders():\n    pass\n",
+		"py/tests/test_math.py":   "import pytest\n\ndef test_add():\n    pass\n",
+		"java/src/test/java/AppTest.java": "import org.junit.jupiter.api.Test;\n" +
+			"import org.springframework.boot.test.context.SpringBootTest;\n\n@SpringBootTest\nclass AppTest {}\n",
+	}
+	var raw []*model.RawFile
+	for rel, content := range files {
+		path := filepath.Join(root, rel)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+		raw = append(raw, &model.RawFile{Path: rel, LOC: 2})
+	}
+
+	records := NewEngine(Options{Root: root, HeaderProbe: true}).InferBatch(raw)
+	byPath := make(map[string]*model.FileRecord)
+	for _, r := range records {
+		byPath[r.Path] = r
+	}
+
+	tests := []struct {
+		path      string
+		subRole   model.TestKind
+		framework string
+	}{
+		{"web/e2e/pages/login.ts", model.TestE2E, "Playwright"},
+		{"web/src/cart.test.ts", model.TestUnit, "Vitest"},
+		{"web/src/api.pact.test.ts", model.TestContract, "Pact"},
+		{"tests/integration/db.js", model.TestIntegration, "Jest"},
+		{"py/tests/test_orders.py", model.TestIntegration, "pytest"},
+		{"py/tests/test_math.py", model.TestUnit, "pytest"},
+		{"java/src/test/java/AppTest.java", model.TestIntegration, "Spring Boot Test"},
+	}
+	for _, tt := range tests {
+		r := byPath[tt.path]
+		if r.Role != model.RoleTest || r.SubRole != tt.subRole || r.TestFramework != tt.framework {
+			t.Errorf("%s: %s/%s framework %q, want test/%s framework %q",
+				tt.path, r.Role, r.SubRole, r.TestFramework, tt.subRole, tt.framework)
+		}
+	}
+
+	if r := byPath["web/playwright.config.ts"]; r.TestFramework != "" {
+		t.Errorf("config file framework = %q, want none for non-test files", r.TestFramework)
+	}
+
+	// without header probing, runner configs still apply but imports are not read
+	quick := make(map[string]*model.FileRecord)
+	for _, r := range NewEngine(Options{Root: root}).InferBatch(raw) {
+		quick[r.Path] = r
+	}
+	if r := quick["web/e2e/pages/login.ts"]; r.SubRole != model.TestE2E || r.TestFramework != "Playwright" {
+		t.Errorf("quick mode login.ts: %s framework %q, want e2e from playwright.config.ts", r.SubRole, r.TestFramework)
+	}
+	if r := quick["web/src/api.pact.test.ts"]; r.SubRole != model.TestUnit || r.TestFramework != "" {
+		t.Errorf("quick mode api.pact.test.ts: %s framework %q, want unit with no import probe", r.SubRole, r.TestFramework)
+	}
+}
+
+func TestDetectTestFramework_Selenium(t *testing.T) {
+	if fw, ok := detectTestFramework("// retries flaky selenium runs\nimport { render } from './render'\n"); ok {
+		t.Errorf("a comment mentioning selenium detected %s", fw.Name)
+	}
+	if fw, _ := detectTestFramework("from selenium import webdriver\n"); fw.Name != "Selenium" {
+		t.Errorf("selenium import detected %q, want Selenium", fw.Name)
+	}
+}
+
+func TestAddDir_TooBroad(t *testing.T) {
+	var c testConfigs
+	c.addDir("", ".", model.TestE2E, "Playwright", "playwright.config.ts")
+	c.addDir("web", "./", model.TestE2E, "Playwright", "web/playwright.config.ts")
+	c.addDir("web", "../../outside", model.TestE2E, "Playwright", "web/playwright.config.ts")
+	c.addDir("", "cypress/e2e/**/*.cy.ts", model.TestE2E, "Cypress", "cypress.config.ts")
+
+	if len(c.dirs) != 1 || c.dirs[0].Dir != "cypress/e2e" {
+		t.Errorf("dirs = %+v, want only cypress/e2e", c.dirs)
+	}
+}
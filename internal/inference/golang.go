@@ -0,0 +1,155 @@
+package inference
+
+import (
+	"bytes"
+	"go/ast"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+// generatedGoComment is the marker from https://go.dev/s/generatedcode
+var generatedGoComment = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// goBuildTagKinds maps build tags that gate test files to test sub-roles
+var goBuildTagKinds = map[string]model.TestKind{
+	"integration": model.TestIntegration,
+	"e2e":         model.TestE2E,
+	"contract":    model.TestContract,
+}
+
+// goTestFuncPrefixes maps the go test function prefixes to test sub-roles;
+// Test functions keep the file's own sub-role
+var goTestFuncPrefixes = []struct {
+	prefix string
+	kind   model.TestKind
+}{
+	{"Benchmark", model.TestBenchmark},
+	{"Fuzz", model.TestFuzz},
+	{"Example", model.TestExample},
+}
+
+// goFacts is what parsing a Go file reveals about its role
+type goFacts struct {
+	generated bool
+	buildTag  string         // the build tag that selected testKind
+	testKind  model.TestKind // from build constraints
+	funcs     []model.RoleLOC
+}
+
+// analyzeGo parses Go source. Generated markers are found in any comment,
+// build constraints in the comments above the package clause, and
+// Benchmark, Fuzz and Example functions in _test.go files are measured so
+// their LOC can be attributed to their own sub-roles.
+func analyzeGo(path string, src []byte) goFacts {
+	var facts goFacts
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, src, parser.ParseComments|parser.SkipObjectResolution)
+	if f == nil {
+		return facts
+	}
+
+	for _, group := range f.Comments {
+		for _, c := range group.List {
+			if generatedGoComment.MatchString(c.Text) {
+				facts.generated = true
+			}
+			if c.Pos() < f.Package && constraint.IsGoBuild(c.Text) && facts.testKind == "" {
+				facts.buildTag, facts.testKind = goBuildTestKind(c.Text)
+			}
+		}
+	}
+
+	// function ranges are unreliable in a file that failed to parse
+	if err != nil || !strings.HasSuffix(path, "_test.go") {
+		return facts
+	}
+	lines := bytes.Split(src, []byte("\n"))
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil {
+			continue
+		}
+		for _, p := range goTestFuncPrefixes {
+			if isGoTestFunc(fn.Name.Name, p.prefix) {
+				loc := codeLines(lines, fset.Position(fn.Pos()).Line, fset.Position(fn.End()).Line)
+				facts.funcs = addRoleLOC(facts.funcs, p.kind, loc)
+			}
+		}
+	}
+	return facts
+}
+
+// goBuildTestKind returns the first test tag required by a //go:build line
+func goBuildTestKind(line string) (string, model.TestKind) {
+	expr, err := constraint.Parse(line)
+	if err != nil {
+		return "", ""
+	}
+	for _, tag := range positiveTags(expr, nil) {
+		if kind, ok := goBuildTagKinds[tag]; ok {
+			return tag, kind
+		}
+	}
+	return "", ""
+}
+
+// positiveTags lists the tags in expr that are not negated
+func positiveTags(expr constraint.Expr, tags []string) []string {
+	switch x := expr.(type) {
+	case *constraint.TagExpr:
+		tags = append(tags, x.Tag)
+	case *constraint.AndExpr:
+		tags = positiveTags(x.Y, positiveTags(x.X, tags))
+	case *constraint.OrExpr:
+		tags = positiveTags(x.Y, positiveTags(x.X, tags))
+	}
+	return tags
+}
+
+// isGoTestFunc reports whether name is prefix followed by nothing or a
+// non-lowercase letter, as go test requires
+func isGoTestFunc(name, prefix string) bool {
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+	if len(name) == len(prefix) {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(name[len(prefix):])
+	return !unicode.IsLower(r)
+}
+
+// codeLines counts non-blank, non-comment lines in the 1-based range [from, to]
+func codeLines(lines [][]byte, from, to int) int {
+	n := 0
+	for i := from - 1; i < to && i < len(lines); i++ {
+		trimmed := bytes.TrimSpace(lines[i])
+		if len(trimmed) > 0 && !bytes.HasPrefix(trimmed, []byte("//")) {
+			n++
+		}
+	}
+	return n
+}
+
+func addRoleLOC(parts []model.RoleLOC, kind model.TestKind, loc int) []model.RoleLOC {
+	for i := range parts {
+		if parts[i].SubRole == kind {
+			parts[i].LOC += loc
+			return parts
+		}
+	}
+	return append(parts, model.RoleLOC{Role: model.RoleTest, SubRole: kind, LOC: loc})
+}
+
+// isTestdata reports whether path is inside a testdata directory, which the
+// go tool ignores and Go projects use for fixtures
+func isTestdata(path string) bool {
+	return strings.Contains("/"+strings.ReplaceAll(path, "\\", "/"), "/testdata/")
+}
@@ -0,0 +1,145 @@
+package inference
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+func TestAnalyzeGo(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		src       string
+		generated bool
+		testKind  model.TestKind
+		funcs     []model.RoleLOC
+	}{
+		{
+			name:      "generated marker past the first 2KB",
+			path:      "api.go",
+			src:       "// Package api is large.\n" + strings.Repeat("//\n", 1500) + "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage api\n",
+			generated: true,
+		},
+		{
+			name: "marker inside a string is not a comment",
+			path: "gen.go",
+			src:  "package gen\n\nconst header = `\n// Code generated by gen. DO NOT EDIT.\n`\n",
+		},
+		{
+			name:     "integration build tag",
+			path:     "db_test.go",
+			src:      "//go:build integration && !windows\n\npackage db\n",
+			testKind: model.TestIntegration,
+		},
+		{
+			name: "negated tag is ignored",
+			path: "db_test.go",
+			src:  "//go:build !e2e\n\npackage db\n",
+		},
+		{
+			name: "test function kinds",
+			path: "sum_test.go",
+			src: `package sum
+
+func TestSum(t *testing.T) {
+	check(t)
+}
+
+// BenchmarkSum measures Sum.
+func BenchmarkSum(b *testing.B) {
+	for range b.N {
+		Sum(1, 2)
+	}
+}
+
+func Benchmarking() {}
+
+func ExampleSum() {
+	fmt.Println(Sum(1, 2))
+	// Output: 3
+}
+`,
+			funcs: []model.RoleLOC{
+				{Role: model.RoleTest, SubRole: model.TestBenchmark, LOC: 5},
+				{Role: model.RoleTest, SubRole: model.TestExample, LOC: 3},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			facts := analyzeGo(tt.path, []byte(tt.src))
+			if facts.generated != tt.generated {
+				t.Errorf("generated = %v, want %v", facts.generated, tt.generated)
+			}
+			if facts.testKind != tt.testKind {
+				t.Errorf("testKind = %q, want %q", facts.testKind, tt.testKind)
+			}
+			if len(facts.funcs) != len(tt.funcs) {
+				t.Fatalf("funcs = %+v, want %+v", facts.funcs, tt.funcs)
+			}
+			for i := range tt.funcs {
+				if facts.funcs[i] != tt.funcs[i] {
+					t.Errorf("funcs[%d] = %+v, want %+v", i, facts.funcs[i], tt.funcs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEngineInfer_GoPrecise(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(root, rel)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+	write("store/db_test.go", "//go:build integration\n\npackage store\n\nfunc TestDB(t *testing.T) {}\n")
+	write("store/db.pb.go", "package store\n")
+	write("api/types.go", strings.Repeat("// long license header\n", 200)+"// Code generated by oapi-codegen. DO NOT EDIT.\npackage api\n")
+	write("parser/testdata/input.json", "{}\n")
+
+	engine := NewEngine(Options{GoPrecise: true, Root: root})
+	tests := []struct {
+		path    string
+		lang    string
+		role    model.Role
+		subRole model.TestKind
+	}{
+		{"store/db_test.go", "Go", model.RoleTest, model.TestIntegration},
+		{"api/types.go", "Go", model.RoleGenerated, ""},
+		{"parser/testdata/input.json", "JSON", model.RoleTest, model.TestFixture},
+	}
+	for _, tt := range tests {
+		record := engine.Infer(&model.RawFile{Path: tt.path, LOC: 3, LanguageHint: tt.lang})
+		if record.Role != tt.role || record.SubRole != tt.subRole {
+			t.Errorf("%s: role = %v/%v, want %v/%v", tt.path, record.Role, record.SubRole, tt.role, tt.subRole)
+		}
+	}
+
+	// without Go-precise mode the same files fall back to name heuristics
+	plain := NewEngine(Options{Root: root})
+	if record := plain.Infer(&model.RawFile{Path: "store/db_test.go", LOC: 3, LanguageHint: "Go"}); record.SubRole != model.TestUnit {
+		t.Errorf("SubRole = %v, want unit without Go-precise mode", record.SubRole)
+	}
+}
+
+func TestEngineInfer_GoPreciseSplitsTestFuncs(t *testing.T) {
+	root := t.TempDir()
+	src := "package sum\n\nfunc TestSum(t *testing.T) {\n\tcheck(t)\n}\n\nfunc FuzzSum(f *testing.F) {\n\tf.Fuzz(check)\n}\n"
+	os.WriteFile(filepath.Join(root, "sum_test.go"), []byte(src), 0644)
+
+	engine := NewEngine(Options{GoPrecise: true, Root: root})
+	record := engine.Infer(&model.RawFile{Path: "sum_test.go", LOC: 7, LanguageHint: "Go"})
+
+	parts := record.RoleLOCs()
+	if len(parts) != 2 {
+		t.Fatalf("RoleLOCs = %+v, want unit and fuzz parts", parts)
+	}
+	if parts[0].SubRole != model.TestUnit || parts[0].LOC != 4 || parts[1].SubRole != model.TestFuzz || parts[1].LOC != 3 {
+		t.Errorf("RoleLOCs = %+v, want unit 4 and fuzz 3", parts)
+	}
+}
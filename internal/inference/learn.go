@@ -0,0 +1,365 @@
+package inference
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+// LearnOptions tunes how Learn fits rule weights to labeled files
+type LearnOptions struct {
+	Epochs     int     // passes of full-batch gradient descent
+	Rate       float64 // gradient step size
+	Margin     float64 // lead the label needs over the next role; the engine's ambiguity gap
+	Prior      float64 // L2 pull of each rule toward its current weight
+	DirWeight  float32 // starting weight of a candidate directory rule
+	MinSupport int     // labeled files a directory needs to become a candidate rule
+	Purity     float64 // share of a directory's files its role needs to become a candidate
+}
+
+func DefaultLearnOptions() LearnOptions {
+	return LearnOptions{
+		Epochs:     300,
+		Rate:       0.05,
+		Margin:     0.15,
+		Prior:      0.5,
+		DirWeight:  0.55,
+		MinSupport: 3,
+		Purity:     0.9,
+	}
+}
+
+// feature is a rule whose weight is fitted
+type feature struct {
+	key     weightKey
+	prior   float64 // current weight, or DirWeight for a candidate directory rule
+	builtin bool
+}
+
+// example is one labeled file: the fitted rules that fire on it and the
+// evidence of custom rules, which is kept fixed
+type example struct {
+	label int   // index into model.AllRoles
+	hits  []int // feature indexes
+	fixed []float64
+}
+
+// Learn fits rule weights to labeled files, keyed by path as scanned. The
+// fit mirrors how the engine decides: a file's role is the one with the most
+// summed rule weight, and core if no rule fired. For each labeled file whose
+// label does not lead the next role by Margin (the engine's ambiguity gap),
+// the label's rules are raised and the leading role's rules lowered, in
+// proportion to the share of files each rule fires on that disagree. Every
+// rule is pulled toward its current weight and kept in [0, 1], so weights
+// only move where labels contradict them; 0 disables a rule.
+//
+// Every directory holding at least MinSupport files labeled with a role
+// other than core, and otherwise mostly files of that role, becomes a
+// candidate rule for it; candidates that no labeled file needs are dropped.
+// Overrides are ignored; the before and after shares come from full
+// inference runs, neighborhood included.
+func (e *Engine) Learn(files []*model.RawFile, labels map[string]model.Role, opts LearnOptions) (*Weights, *model.LearnSummary, error) {
+	for p, role := range labels {
+		if roleIndex(role) < 0 {
+			return nil, nil, fmt.Errorf("label %s: unknown role %q", p, role)
+		}
+	}
+	var labeled []*model.RawFile
+	for _, f := range files {
+		if _, ok := labels[f.Path]; ok {
+			labeled = append(labeled, f)
+		}
+	}
+	if len(labeled) == 0 {
+		return nil, nil, fmt.Errorf("none of the %d labeled paths were scanned", len(labels))
+	}
+
+	builtin := builtinWeights()
+	var features []feature
+	index := make(map[weightKey]int)
+	featureOf := func(key weightKey, prior float32) int {
+		i, ok := index[key]
+		if !ok {
+			_, isBuiltin := builtin[key]
+			i = len(features)
+			index[key] = i
+			features = append(features, feature{key: key, prior: float64(prior), builtin: isBuiltin})
+		}
+		return i
+	}
+
+	current := e.classify(e.rules, files)
+	candidates := candidateDirs(files, labels, current, opts, builtin)
+	examples := make([]example, len(labeled))
+	for i, f := range labeled {
+		ex := example{
+			label: roleIndex(labels[f.Path]),
+			fixed: make([]float64, len(model.AllRoles)),
+		}
+		for _, hit := range e.ruleHits(f) {
+			if hit.Custom {
+				ex.fixed[roleIndex(hit.Role)] += float64(hit.Weight)
+				continue
+			}
+			ex.hits = append(ex.hits, featureOf(newWeightKey(signalKinds[hit.Signal], hit.Pattern, hit.Role), hit.Weight))
+		}
+		rooted := "/" + strings.ToLower(filepath.ToSlash(f.Path))
+		for _, c := range candidates {
+			if strings.Contains(rooted, c.pattern) {
+				ex.hits = append(ex.hits, featureOf(c, opts.DirWeight))
+			}
+		}
+		slices.Sort(ex.hits)
+		ex.hits = slices.Compact(ex.hits)
+		examples[i] = ex
+	}
+
+	theta := fitWeights(features, examples, opts)
+	pruneDirRules(theta, features, examples, opts.Margin)
+
+	weights := &Weights{Examples: len(labeled)}
+	for i, f := range features {
+		w := float32(math.Round(theta[i]*100) / 100)
+		def := builtin[f.key]
+		if f.builtin && math.Abs(float64(w-def)) < 0.01 || !f.builtin && w == 0 {
+			continue
+		}
+		weights.Rules = append(weights.Rules, model.RuleWeight{
+			Kind:    string(f.key.kind),
+			Pattern: f.key.pattern,
+			Role:    f.key.role,
+			Weight:  w,
+			Default: def,
+		})
+	}
+	// weights in use that fired on no labeled file are kept as they are
+	for _, r := range e.rules.weights.rules() {
+		if _, ok := index[newWeightKey(RuleKind(r.Kind), r.Pattern, r.Role)]; !ok {
+			weights.Rules = append(weights.Rules, r)
+		}
+	}
+	kindOrder := []RuleKind{RuleKindPath, RuleKindFilename, RuleKindExtension, RuleKindHeader}
+	slices.SortFunc(weights.Rules, func(a, b model.RuleWeight) int {
+		return cmp.Or(
+			cmp.Compare(slices.Index(kindOrder, RuleKind(a.Kind)), slices.Index(kindOrder, RuleKind(b.Kind))),
+			cmp.Compare(a.Pattern, b.Pattern),
+			cmp.Compare(a.Role, b.Role),
+		)
+	})
+
+	summary := &model.LearnSummary{
+		Examples: len(labeled),
+		Before:   labeledShare(current, labels),
+		After:    labeledShare(e.classify(e.rules.reweighted(weights), files), labels),
+		Rules:    weights.Rules,
+	}
+	return weights, summary, nil
+}
+
+// fitWeights runs projected subgradient descent on the margin loss and
+// returns the fitted weight of each feature
+func fitWeights(features []feature, examples []example, opts LearnOptions) []float64 {
+	theta := make([]float64, len(features))
+	fires := make([]float64, len(features))
+	for i, f := range features {
+		theta[i] = f.prior
+	}
+	for _, ex := range examples {
+		for _, h := range ex.hits {
+			fires[h]++
+		}
+	}
+
+	grad := make([]float64, len(features))
+	scores := make([]float64, len(model.AllRoles))
+	for range opts.Epochs {
+		clear(grad)
+		for _, ex := range examples {
+			rival, ok := violation(ex, theta, features, scores, opts.Margin)
+			if ok {
+				continue
+			}
+			for _, h := range ex.hits {
+				switch roleIndex(features[h].key.role) {
+				case ex.label:
+					grad[h]--
+				case rival:
+					grad[h]++
+				}
+			}
+		}
+		for i, f := range features {
+			theta[i] -= opts.Rate * (grad[i]/fires[i] + 2*opts.Prior*(theta[i]-f.prior))
+			theta[i] = min(max(theta[i], 0), 1)
+		}
+	}
+	return theta
+}
+
+// violation scores an example and reports whether its label leads the
+// strongest other role by at least margin; if not, it also returns that
+// role. Without evidence for any other role the label only needs some
+// evidence of its own, or to be core, the engine's fallback.
+func violation(ex example, theta []float64, features []feature, scores []float64, margin float64) (int, bool) {
+	copy(scores, ex.fixed)
+	for _, h := range ex.hits {
+		scores[roleIndex(features[h].key.role)] += theta[h]
+	}
+	rival, best := -1, 0.0
+	for r, s := range scores {
+		if r != ex.label && s > best {
+			rival, best = r, s
+		}
+	}
+	if rival < 0 {
+		return -1, scores[ex.label] > 0 || model.AllRoles[ex.label] == model.RoleCore
+	}
+	return rival, scores[ex.label]-best >= margin
+}
+
+// pruneDirRules drops candidate directory rules that no labeled file needs
+// to reach its label by margin, one at a time in feature order
+func pruneDirRules(theta []float64, features []feature, examples []example, margin float64) {
+	scores := make([]float64, len(model.AllRoles))
+	satisfied := func() int {
+		n := 0
+		for _, ex := range examples {
+			if _, ok := violation(ex, theta, features, scores, margin); ok {
+				n++
+			}
+		}
+		return n
+	}
+	for i, f := range features {
+		if f.builtin || theta[i] == 0 {
+			continue
+		}
+		before, w := satisfied(), theta[i]
+		theta[i] = 0
+		if satisfied() < before {
+			theta[i] = w
+		}
+	}
+}
+
+func roleIndex(role model.Role) int {
+	return slices.Index(model.AllRoles, role)
+}
+
+// candidateDirs returns a path rule key for each directory name (as
+// "/name/") and role other than core with at least MinSupport labeled files
+// below it, where that role covers at least Purity of all files below it.
+// Unlabeled files count with their current role, so a directory that merely
+// contains a labeled one is not taken over. Built-in rules are skipped.
+func candidateDirs(files []*model.RawFile, labels, current map[string]model.Role, opts LearnOptions, builtin map[weightKey]float32) []weightKey {
+	total := make(map[string]int)
+	agree := make(map[weightKey]int)
+	support := make(map[weightKey]int)
+	for _, f := range files {
+		role, labeled := labels[f.Path]
+		if !labeled {
+			role = current[f.Path]
+		}
+		seen := make(map[string]bool)
+		for _, name := range strings.Split(path.Dir(strings.ToLower(filepath.ToSlash(f.Path))), "/") {
+			if name == "." || name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			pattern := "/" + name + "/"
+			total[pattern]++
+			key := newWeightKey(RuleKindPath, pattern, role)
+			agree[key]++
+			if labeled {
+				support[key]++
+			}
+		}
+	}
+
+	var keys []weightKey
+	for key, n := range support {
+		_, isBuiltin := builtin[key]
+		share := float64(agree[key]) / float64(total[key.pattern])
+		if key.role != model.RoleCore && !isBuiltin && n >= opts.MinSupport && share >= opts.Purity {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, func(a, b weightKey) int {
+		return cmp.Or(cmp.Compare(a.pattern, b.pattern), cmp.Compare(a.role, b.role))
+	})
+	return keys
+}
+
+// ruleHits returns the weighted rules that fire on a file, without the
+// early exits of Infer so every rule's weight can be fitted
+func (e *Engine) ruleHits(file *model.RawFile) []model.RuleHit {
+	score := NewRoleScore()
+	score.tracing = true
+	e.applyPathRules(file.Path, score)
+	e.applyFilenameRules(file.Path, score)
+	e.applyExtensionRules(file.Path, score)
+	probe := e.enableHeaderProbe || len(e.rules.custom[RuleKindHeader]) > 0
+	if probe && file.Asset == nil {
+		e.applyHeaderRules(file, score)
+	}
+	return score.trace
+}
+
+// signalKinds maps a rule's signal back to its kind
+var signalKinds = map[model.Signal]RuleKind{
+	model.SignalPath:      RuleKindPath,
+	model.SignalFilename:  RuleKindFilename,
+	model.SignalExtension: RuleKindExtension,
+	model.SignalHeader:    RuleKindHeader,
+}
+
+// classify infers roles for all files with rules and without overrides
+func (e *Engine) classify(rules *ruleSet, files []*model.RawFile) map[string]model.Role {
+	trial := *e
+	trial.rules = rules
+	trial.overrides = nil
+
+	roles := make(map[string]model.Role, len(files))
+	for _, r := range trial.InferBatch(files) {
+		roles[r.Path] = r.Role
+	}
+	return roles
+}
+
+// labeledShare returns the share of labeled files given their label
+func labeledShare(roles, labels map[string]model.Role) float32 {
+	var total, agree int
+	for p, label := range labels {
+		if role, ok := roles[p]; ok {
+			total++
+			if role == label {
+				agree++
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float32(agree) / float32(total)
+}
+
+// OverrideLabels returns the role each file's override assigns, as labels
+// for Learn
+func (e *Engine) OverrideLabels(files []*model.RawFile) map[string]model.Role {
+	labels := make(map[string]model.Role)
+	if e.overrides == nil {
+		return labels
+	}
+	for _, f := range files {
+		if o := e.overrides.Match(f.Path); o != nil {
+			labels[f.Path] = o.Role
+		}
+	}
+	return labels
+}
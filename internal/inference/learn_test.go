@@ -0,0 +1,149 @@
+package inference
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+func TestEngineLearn(t *testing.T) {
+	var files []*model.RawFile
+	labels := make(map[string]model.Role)
+	add := func(path string, role model.Role) {
+		files = append(files, &model.RawFile{Path: path, LOC: 10, LanguageHint: "Go"})
+		if role != "" {
+			labels[path] = role
+		}
+	}
+	for i := range 4 {
+		// our tests live in checks/, top-level and nested
+		add(fmt.Sprintf("checks/c%d.go", i), model.RoleTest)
+		add(fmt.Sprintf("svc/api/checks/c%d.go", i), model.RoleTest)
+		// scripts/ holds product code here, not scripts
+		add(fmt.Sprintf("tools/scripts/s%d.go", i), model.RoleCore)
+		// unlabeled product code next to the checks
+		add(fmt.Sprintf("svc/api/h%d.go", i), "")
+	}
+
+	engine := NewEngine(Options{})
+	weights, summary, err := engine.Learn(files, labels, DefaultLearnOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[weightKey]float32)
+	for _, r := range weights.Rules {
+		got[newWeightKey(RuleKind(r.Kind), r.Pattern, r.Role)] = r.Weight
+	}
+	if w := got[newWeightKey(RuleKindPath, "/checks/", model.RoleTest)]; w < 0.5 {
+		t.Errorf("/checks/ test weight = %v, want a learned rule >= 0.5", w)
+	}
+	if w, ok := got[newWeightKey(RuleKindPath, "/scripts/", model.RoleScripts)]; !ok || w >= 0.55 {
+		t.Errorf("/scripts/ scripts weight = %v (set %v), want below the default 0.55", w, ok)
+	}
+	for _, dir := range []string{"/svc/", "/api/"} {
+		if _, ok := got[newWeightKey(RuleKindPath, dir, model.RoleTest)]; ok {
+			t.Errorf("learned %s as test, but it mostly holds unlabeled core files", dir)
+		}
+	}
+	if summary.Examples != 12 || summary.Before >= summary.After || summary.After != 1 {
+		t.Errorf("summary = %d examples, %.2f → %.2f, want 12 examples improving to 1", summary.Examples, summary.Before, summary.After)
+	}
+
+	// the weights file round-trips into an engine
+	path := filepath.Join(t.TempDir(), "weights.json")
+	if err := weights.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadWeights(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trained := NewEngine(Options{Weights: loaded})
+	for _, f := range files {
+		want := labels[f.Path]
+		if want == "" {
+			want = model.RoleCore
+		}
+		if r := trained.Infer(f); r.Role != want {
+			t.Errorf("%s = %v, want %v", f.Path, r.Role, want)
+		}
+	}
+
+	// learning again from the loaded weights keeps them, including the
+	// disabled rule that no longer fires
+	again, _, err := trained.Learn(files, labels, DefaultLearnOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(again.Rules, weights.Rules) {
+		t.Errorf("relearned rules = %+v, want %+v", again.Rules, weights.Rules)
+	}
+}
+
+func TestEngineLearn_NoLabeledFiles(t *testing.T) {
+	files := []*model.RawFile{{Path: "a.go"}}
+	_, _, err := NewEngine(Options{}).Learn(files, map[string]model.Role{"b.go": model.RoleTest}, DefaultLearnOptions())
+	if err == nil {
+		t.Error("Learn() error = nil, want an error when no labeled file was scanned")
+	}
+}
+
+func TestWeights_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    model.RuleWeight
+		wantErr bool
+	}{
+		{"built-in rule", model.RuleWeight{Kind: "path", Pattern: "/scripts/", Role: model.RoleScripts, Weight: 0.2}, false},
+		{"disabled built-in", model.RuleWeight{Kind: "extension", Pattern: ".lock", Role: model.RoleGenerated}, false},
+		{"learned directory", model.RuleWeight{Kind: "path", Pattern: "/checks/", Role: model.RoleTest, Weight: 0.8}, false},
+		{"not a directory", model.RuleWeight{Kind: "path", Pattern: "checks", Role: model.RoleTest, Weight: 0.8}, true},
+		{"unknown filename rule", model.RuleWeight{Kind: "filename", Pattern: "foo", Role: model.RoleTest, Weight: 0.8}, true},
+		{"unknown kind", model.RuleWeight{Kind: "syntax", Pattern: "x", Role: model.RoleTest, Weight: 0.8}, true},
+		{"unknown role", model.RuleWeight{Kind: "path", Pattern: "/checks/", Role: "qa", Weight: 0.8}, true},
+		{"weight out of range", model.RuleWeight{Kind: "path", Pattern: "/checks/", Role: model.RoleTest, Weight: 1.5}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &Weights{Rules: []model.RuleWeight{tt.rule}}
+			if err := w.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewRuleSet_Weights(t *testing.T) {
+	weights := &Weights{Rules: []model.RuleWeight{
+		{Kind: "path", Pattern: "/scripts/", Role: model.RoleScripts, Weight: 0.3},
+		{Kind: "extension", Pattern: ".lock", Role: model.RoleGenerated, Weight: 0},
+		{Kind: "path", Pattern: "/checks/", Role: model.RoleTest, Weight: 0.8},
+		{Kind: "path", Pattern: "/bin/", Role: model.RoleScripts, Weight: 0.9},
+	}}
+	engine := NewEngine(Options{Weights: weights, DisabledRules: []DisabledRule{{Kind: RuleKindPath, Pattern: "/bin/"}}})
+
+	tests := []struct {
+		path       string
+		wantRole   model.Role
+		wantWeight float32
+	}{
+		{"app/scripts/run.sh", model.RoleScripts, 0.3},
+		{"yarn.lock", model.RoleCore, 0},
+		{"checks/a.go", model.RoleTest, 0.8},
+		{"svc/checks/a.go", model.RoleTest, 0.8},
+		{"app/bin/run.sh", model.RoleCore, 0}, // disabled stays disabled
+	}
+	for _, tt := range tests {
+		score := NewRoleScore()
+		engine.applyPathRules(tt.path, score)
+		engine.applyExtensionRules(tt.path, score)
+		role, _, _, _ := score.Resolve()
+		if role != tt.wantRole || score.Weights[role] != tt.wantWeight {
+			t.Errorf("%s = %v (%v), want %v (%v)", tt.path, role, score.Weights[role], tt.wantRole, tt.wantWeight)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package inference
+
+import (
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+// NeighborhoodWeights tunes how confidently classified files lend their role
+// to low-confidence files nearby in the directory tree. A vote counts 1 in
+// the file's own directory and is multiplied by Decay for every directory
+// step between voter and file, up or down the tree.
+type NeighborhoodWeights struct {
+	Decay      float32 // vote multiplier per directory step, in (0, 1]
+	Levels     int     // parent directories consulted above the file's own
+	Depth      int     // subdirectory levels voting below each directory consulted
+	MinSupport float32 // decayed votes needed before reclassifying
+	Dominance  float32 // share of the votes the winning role needs
+	Boost      float32 // confidence added to a reclassified file
+}
+
+// DefaultNeighborhoodWeights reproduces the sibling rule (two confident
+// siblings, 70% agreement, +0.40) and adds immediate subdirectories at half
+// weight. Parents are consulted only when Levels is raised.
+func DefaultNeighborhoodWeights() NeighborhoodWeights {
+	return NeighborhoodWeights{
+		Decay:      0.5,
+		Levels:     0,
+		Depth:      1,
+		MinSupport: 2,
+		Dominance:  0.70,
+		Boost:      0.40,
+	}
+}
+
+const (
+	neighborhoodVoterConfidence  = 0.70 // files at or above this vote
+	neighborhoodTargetConfidence = 0.60 // files below this can be reclassified
+)
+
+// isDefaultCore reports whether a file is core only because nothing said
+// otherwise. Such files vote core for their neighbors, though not for
+// themselves, so an untested core directory is not outvoted by the test
+// directories around it. Their votes only hold other roles back: the winning
+// role still needs weights.MinSupport confident votes.
+func isDefaultCore(r *model.FileRecord) bool {
+	return r.Role == model.RoleCore && len(r.Signals) == 0
+}
+
+// defaultCoreVote is the vote of a file with no evidence
+var defaultCoreVote = roleVote{role: model.RoleCore, byDefault: true}
+
+// roleVote identifies what a voter stands for; sub-roles only matter for test
+type roleVote struct {
+	role      model.Role
+	subRole   model.SubRole
+	byDefault bool // cast by a file with no evidence
+}
+
+type votes map[roleVote]float32
+
+func (v votes) add(other votes, scale float32) {
+	for k, w := range other {
+		v[k] += w * scale
+	}
+}
+
+// applyNeighborhoodInference moves low-confidence files to the role that
+// dominates the confident files around them, and returns the effect on each
+// file it changed. Votes are aggregated per subtree once, so each directory
+// is evaluated by walking only its ancestors. Subtrees are cut weights.Depth
+// levels down, so a file near the root is not swayed by the whole repository.
+func applyNeighborhoodInference(records []*model.FileRecord, weights NeighborhoodWeights) map[*model.FileRecord]*model.NeighborhoodEffect {
+	effects := make(map[*model.FileRecord]*model.NeighborhoodEffect)
+
+	direct := make(map[string]votes)
+	byDir := make(map[string][]*model.FileRecord)
+	for _, r := range records {
+		dir := path.Dir(filepath.ToSlash(r.Path))
+		byDir[dir] = append(byDir[dir], r)
+		if direct[dir] == nil {
+			direct[dir] = votes{}
+		}
+		if r.Confidence >= neighborhoodVoterConfidence {
+			direct[dir][roleVote{role: r.Role, subRole: r.SubRole}]++
+		} else if isDefaultCore(r) {
+			direct[dir][defaultCoreVote]++
+		}
+	}
+
+	// every ancestor takes part, even without files of its own
+	for dir := range byDir {
+		for d := dir; path.Dir(d) != d; {
+			d = path.Dir(d)
+			if direct[d] == nil {
+				direct[d] = votes{}
+			}
+		}
+	}
+
+	// down[k][d] holds the decayed votes of d and of the directories up to k
+	// levels below it, as seen from d; children are complete before their
+	// parent because deeper dirs go first
+	dirs := make([]string, 0, len(direct))
+	for dir := range direct {
+		dirs = append(dirs, dir)
+	}
+	slices.SortFunc(dirs, func(a, b string) int { return dirDepth(b) - dirDepth(a) })
+	down := make([]map[string]votes, max(weights.Depth, 0)+1)
+	for k := range down {
+		down[k] = make(map[string]votes, len(dirs))
+	}
+	for _, d := range dirs {
+		parent := path.Dir(d)
+		for k := range down {
+			if down[k][d] == nil {
+				down[k][d] = votes{}
+			}
+			down[k][d].add(direct[d], 1)
+			if k > 0 && parent != d {
+				if down[k][parent] == nil {
+					down[k][parent] = votes{}
+				}
+				down[k][parent].add(down[k-1][d], weights.Decay)
+			}
+		}
+	}
+
+	for dir, dirRecords := range byDir {
+		var candidates []*model.FileRecord
+		for _, r := range dirRecords {
+			if r.Confidence < neighborhoodTargetConfidence && !slices.Contains(r.Signals, model.SignalOverride) {
+				candidates = append(candidates, r)
+			}
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+
+		// files with no evidence are evaluated without their own vote
+		var found [2]struct {
+			n  neighborhood
+			ok bool
+		}
+		for i := range found {
+			found[i].n, found[i].ok = neighborhoodOf(dir, down, weights, i == 1)
+		}
+		for _, r := range candidates {
+			n, ok := found[0].n, found[0].ok
+			if isDefaultCore(r) {
+				n, ok = found[1].n, found[1].ok
+			}
+			if !ok || r.Role == n.role {
+				continue
+			}
+			// a role .gitattributes negates stays ruled out
+			if set, ok := r.Linguist.RoleFlags()[n.role]; ok && !set {
+				continue
+			}
+			effects[r] = &model.NeighborhoodEffect{
+				Dir:            n.source,
+				Distance:       n.distance,
+				FromRole:       r.Role,
+				FromConfidence: r.Confidence,
+				DominantRatio:  n.ratio,
+				Support:        n.support,
+			}
+			r.Role = n.role
+			r.SubRole = n.subRole
+			r.Confidence = min(r.Confidence+weights.Boost, 1.0)
+			r.Signals = append(r.Signals, model.SignalNeighborhood)
+			r.NeighborhoodDir = n.source
+		}
+	}
+
+	return effects
+}
+
+// neighborhood is the role a directory's surroundings agree on
+type neighborhood struct {
+	role     model.Role
+	subRole  model.SubRole
+	ratio    float32 // share of the decayed votes for role
+	support  float32 // all decayed votes
+	source   string  // directory whose votes for role weighed most
+	distance int     // levels from dir up to source
+}
+
+// neighborhoodOf combines the votes of dir's subtree with those of up to
+// weights.Levels ancestors, each excluding the branch already counted. down
+// is indexed by depth as built by applyNeighborhoodInference. withoutSelf
+// leaves out the default core vote of the file being evaluated.
+func neighborhoodOf(dir string, down []map[string]votes, weights NeighborhoodWeights, withoutSelf bool) (neighborhood, bool) {
+	type contribution struct {
+		dir   string
+		level int
+		votes votes
+	}
+	depth := len(down) - 1
+	own := down[depth][dir]
+	if withoutSelf {
+		own = votes{}
+		own.add(down[depth][dir], 1)
+		if own[defaultCoreVote]--; own[defaultCoreVote] < 1e-6 {
+			delete(own, defaultCoreVote)
+		}
+	}
+	contributions := []contribution{{dir, 0, own}}
+	scale := float32(1)
+	for child, level := dir, 1; level <= weights.Levels; level++ {
+		parent := path.Dir(child)
+		if parent == child {
+			break
+		}
+		scale *= weights.Decay
+		// the parent's subtree minus the branch the walk came up from
+		var branch votes
+		if depth > 0 {
+			branch = down[depth-1][child]
+		}
+		v := votes{}
+		for k, w := range down[depth][parent] {
+			if rest := w - weights.Decay*branch[k]; rest > 1e-6 {
+				v[k] = rest * scale
+			}
+		}
+		contributions = append(contributions, contribution{parent, level, v})
+		child = parent
+	}
+
+	total := votes{}
+	for _, c := range contributions {
+		total.add(c.votes, 1)
+	}
+	byRole := make(map[model.Role]float32)
+	confident := make(map[model.Role]float32)
+	var support float32
+	for k, w := range total {
+		byRole[k.role] += w
+		if !k.byDefault {
+			confident[k.role] += w
+		}
+		support += w
+	}
+	if support < weights.MinSupport || support == 0 {
+		return neighborhood{}, false
+	}
+
+	var n neighborhood
+	var best float32
+	for _, role := range model.AllRoles {
+		if byRole[role] > best {
+			best, n.role = byRole[role], role
+		}
+	}
+	n.ratio = best / support
+	n.support = support
+	if n.ratio < weights.Dominance || confident[n.role] < weights.MinSupport {
+		return neighborhood{}, false
+	}
+
+	var bestSub float32
+	for k, w := range total {
+		if k.role == n.role && (w > bestSub || w == bestSub && k.subRole < n.subRole) {
+			bestSub, n.subRole = w, k.subRole
+		}
+	}
+	if n.role == model.RoleTest && n.subRole == "" {
+		n.subRole = model.TestUnit
+	}
+
+	var bestSource float32
+	for _, c := range contributions {
+		var w float32
+		for k, v := range c.votes {
+			if k.role == n.role && !k.byDefault {
+				w += v
+			}
+		}
+		if w > bestSource {
+			bestSource, n.source, n.distance = w, c.dir, c.level
+		}
+	}
+	return n, true
+}
+
+func dirDepth(dir string) int {
+	if dir == "." || dir == "/" {
+		return 0
+	}
+	return strings.Count(strings.Trim(dir, "/"), "/") + 1
+}
@@ -0,0 +1,162 @@
+package inference
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+// confidentFiles returns n records in dir with the given role at 0.9 confidence
+func confidentFiles(dir string, n int, role model.Role, subRole model.SubRole) []*model.FileRecord {
+	records := make([]*model.FileRecord, n)
+	for i := range records {
+		records[i] = &model.FileRecord{
+			Path:       fmt.Sprintf("%s/f%d", dir, i),
+			Role:       role,
+			SubRole:    subRole,
+			Confidence: 0.9,
+		}
+	}
+	return records
+}
+
+func lowConfidence(path string) *model.FileRecord {
+	return &model.FileRecord{Path: path, Role: model.RoleCore, Confidence: 0.30}
+}
+
+// withParents returns the default weights with three parent levels consulted
+func withParents() NeighborhoodWeights {
+	w := DefaultNeighborhoodWeights()
+	w.Levels = 3
+	return w
+}
+
+func TestNeighborhood_InheritsFromParent(t *testing.T) {
+	helper := lowConfidence("services/api/testutil/helpers.go")
+	records := append(confidentFiles("services/api", 4, model.RoleTest, model.TestIntegration), helper)
+
+	effects := applyNeighborhoodInference(records, withParents())
+
+	if helper.Role != model.RoleTest || helper.SubRole != model.TestIntegration {
+		t.Fatalf("helper = %v/%v, want test/integration", helper.Role, helper.SubRole)
+	}
+	if helper.NeighborhoodDir != "services/api" {
+		t.Errorf("NeighborhoodDir = %q, want services/api", helper.NeighborhoodDir)
+	}
+	e := effects[helper]
+	if e == nil || e.Dir != "services/api" || e.Distance != 1 || e.Support != 2 {
+		t.Errorf("effect = %+v, want services/api, distance 1, support 2", e)
+	}
+}
+
+func TestNeighborhood_Decay(t *testing.T) {
+	tests := []struct {
+		name     string
+		records  []*model.FileRecord
+		weights  func(*NeighborhoodWeights)
+		wantRole model.Role
+	}{
+		{
+			name:     "parents ignored by default",
+			records:  confidentFiles("services/api", 4, model.RoleTest, ""),
+			weights:  func(w *NeighborhoodWeights) { *w = DefaultNeighborhoodWeights() },
+			wantRole: model.RoleCore,
+		},
+		{
+			name:     "two levels up is too weak at half decay",
+			records:  confidentFiles("services", 4, model.RoleTest, ""),
+			wantRole: model.RoleCore,
+		},
+		{
+			name:     "two levels up with slower decay",
+			records:  confidentFiles("services", 4, model.RoleTest, ""),
+			weights:  func(w *NeighborhoodWeights) { w.Decay = 0.8 },
+			wantRole: model.RoleTest,
+		},
+		{
+			name:     "cousin directory counts through the common parent",
+			records:  confidentFiles("services/api/tests", 8, model.RoleTest, ""),
+			wantRole: model.RoleTest,
+		},
+		{
+			name: "no dominant role",
+			records: append(confidentFiles("services/api", 4, model.RoleTest, ""),
+				confidentFiles("services/api/x", 4, model.RoleDocs, "")...),
+			wantRole: model.RoleCore,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := lowConfidence("services/api/testutil/helpers.go")
+			weights := withParents()
+			if tt.weights != nil {
+				tt.weights(&weights)
+			}
+			applyNeighborhoodInference(append(tt.records, helper), weights)
+			if helper.Role != tt.wantRole {
+				t.Errorf("Role = %v, want %v", helper.Role, tt.wantRole)
+			}
+		})
+	}
+}
+
+func TestNeighborhood_FromSubdirectories(t *testing.T) {
+	readme := lowConfidence("docs/index.txt")
+	records := append(confidentFiles("docs/guides", 4, model.RoleDocs, ""), readme)
+
+	effects := applyNeighborhoodInference(records, DefaultNeighborhoodWeights())
+
+	if readme.Role != model.RoleDocs {
+		t.Fatalf("Role = %v, want docs from the subdirectory", readme.Role)
+	}
+	if e := effects[readme]; e.Dir != "docs" || e.Distance != 0 {
+		t.Errorf("effect = %+v, want docs at distance 0", e)
+	}
+}
+
+func TestNeighborhood_DepthLimitsDescendants(t *testing.T) {
+	// 24 files three levels down would outvote a root file at 0.125 each
+	records := func() (*model.FileRecord, []*model.FileRecord) {
+		setup := lowConfidence("setup.cfg")
+		return setup, append(confidentFiles("services/api/tests", 24, model.RoleTest, ""), setup)
+	}
+
+	setup, all := records()
+	applyNeighborhoodInference(all, DefaultNeighborhoodWeights())
+	if setup.Role != model.RoleCore {
+		t.Errorf("root file Role = %v, want core: deep descendants do not vote", setup.Role)
+	}
+
+	setup, all = records()
+	weights := DefaultNeighborhoodWeights()
+	weights.Depth = 3
+	applyNeighborhoodInference(all, weights)
+	if setup.Role != model.RoleTest {
+		t.Errorf("root file Role = %v, want test with depth 3", setup.Role)
+	}
+}
+
+func TestNeighborhood_UntestedCoreBesideTests(t *testing.T) {
+	// internal/core has no tests; its files carry no evidence and sit
+	// between two fully tested directories
+	for _, weights := range []NeighborhoodWeights{DefaultNeighborhoodWeights(), withParents()} {
+		core := []*model.FileRecord{
+			lowConfidence("internal/core/a.go"),
+			lowConfidence("internal/core/b.go"),
+			lowConfidence("internal/core/c.go"),
+		}
+		records := append(confidentFiles("internal/api", 6, model.RoleTest, model.TestUnit),
+			confidentFiles("internal/store", 6, model.RoleTest, model.TestUnit)...)
+		records = append(records, confidentFiles("internal", 2, model.RoleTest, model.TestUnit)...)
+		records = append(records, core...)
+
+		applyNeighborhoodInference(records, weights)
+
+		for _, r := range core {
+			if r.Role != model.RoleCore {
+				t.Errorf("levels %d: %s Role = %v, want core", weights.Levels, r.Path, r.Role)
+			}
+		}
+	}
+}
@@ -1,51 +1,115 @@
 package inference
 
 import (
+	"cmp"
+	"fmt"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync/atomic"
 
 	"github.com/modern-tooling/aloc/internal/model"
 )
 
-type Overrides struct {
-	rules []overrideRule
+// Override assigns a role to the files matching a glob, skipping all other
+// rules. Overrides are checked by descending Priority, then in list order;
+// the first match wins.
+type Override struct {
+	Pattern    string
+	Role       model.Role
+	SubRole    model.SubRole // one of the role's sub-roles; test defaults to unit
+	Language   string        // reports and counts matching files as this language
+	Confidence float32       // 0 keeps the usual single-signal confidence
+	Priority   int
 }
 
-type overrideRule struct {
-	pattern string
-	role    model.Role
+// NewOverride validates an override entry
+func NewOverride(pattern string, role model.Role, subRole model.SubRole, language string, confidence float32, priority int) (Override, error) {
+	o := Override{
+		Pattern:    pattern,
+		Role:       role,
+		SubRole:    subRole,
+		Language:   language,
+		Confidence: confidence,
+		Priority:   priority,
+	}
+	if pattern == "" {
+		return o, fmt.Errorf("override: pattern is required")
+	}
+	if !slices.Contains(model.AllRoles, role) {
+		return o, fmt.Errorf("override %q: unknown role %q", pattern, role)
+	}
+	if subRole != "" && !slices.Contains(role.Info().SubRoles, subRole) {
+		return o, fmt.Errorf("override %q: sub-role %q is not one of role %s's %v", pattern, subRole, role, role.Info().SubRoles)
+	}
+	if confidence < 0 || confidence > 1 {
+		return o, fmt.Errorf("override %q: confidence must be between 0 and 1, got %v", pattern, confidence)
+	}
+	return o, nil
+}
+
+type Overrides struct {
+	rules   []Override
+	matched []atomic.Bool // per rule, for reporting patterns that matched nothing
 }
 
 type OverrideResult struct {
-	Role    model.Role
-	Pattern string
+	Role       model.Role
+	SubRole    model.SubRole
+	Language   string
+	Confidence float32
+	Pattern    string
 }
 
-func NewOverrides(config map[model.Role][]string) *Overrides {
-	var rules []overrideRule
-	for role, patterns := range config {
-		for _, pattern := range patterns {
-			rules = append(rules, overrideRule{
-				pattern: pattern,
-				role:    role,
-			})
-		}
-	}
-	return &Overrides{rules: rules}
+// NewOverrides orders overrides by descending priority, keeping list order
+// among equal priorities
+func NewOverrides(list []Override) *Overrides {
+	rules := slices.Clone(list)
+	slices.SortStableFunc(rules, func(a, b Override) int {
+		return cmp.Compare(b.Priority, a.Priority)
+	})
+	return &Overrides{rules: rules, matched: make([]atomic.Bool, len(rules))}
 }
 
 func (o *Overrides) Match(filePath string) *OverrideResult {
-	for _, rule := range o.rules {
-		if matchGlob(rule.pattern, filePath) {
+	for i, rule := range o.rules {
+		if matchGlob(rule.Pattern, filePath) {
+			o.matched[i].Store(true)
 			return &OverrideResult{
-				Role:    rule.role,
-				Pattern: rule.pattern,
+				Role:       rule.Role,
+				SubRole:    rule.SubRole,
+				Language:   rule.Language,
+				Confidence: rule.Confidence,
+				Pattern:    rule.Pattern,
 			}
 		}
 	}
 	return nil
 }
 
+// Language returns the language set by the override that wins for filePath,
+// or "". It does not count as a match for Unmatched.
+func (o *Overrides) Language(filePath string) string {
+	for _, rule := range o.rules {
+		if matchGlob(rule.Pattern, filePath) {
+			return rule.Language
+		}
+	}
+	return ""
+}
+
+// Unmatched returns the overrides that have not matched any file yet, in
+// the order they are checked
+func (o *Overrides) Unmatched() []Override {
+	var unmatched []Override
+	for i, rule := range o.rules {
+		if !o.matched[i].Load() {
+			unmatched = append(unmatched, rule)
+		}
+	}
+	return unmatched
+}
+
 func matchGlob(pattern, path string) bool {
 	// Normalize paths
 	pattern = filepath.ToSlash(pattern)
@@ -7,9 +7,10 @@ import (
 )
 
 func TestNewOverrides(t *testing.T) {
-	config := map[model.Role][]string{
-		model.RoleInfra: {"deploy/**", "infra/**"},
-		model.RoleTest:  {"*_test.go"},
+	config := []Override{
+		{Pattern: "deploy/**", Role: model.RoleInfra},
+		{Pattern: "infra/**", Role: model.RoleInfra},
+		{Pattern: "*_test.go", Role: model.RoleTest},
 	}
 
 	overrides := NewOverrides(config)
@@ -23,8 +24,8 @@ func TestNewOverrides(t *testing.T) {
 }
 
 func TestOverridesMatch_DoublestarPattern(t *testing.T) {
-	overrides := NewOverrides(map[model.Role][]string{
-		model.RoleInfra: {"deploy/**"},
+	overrides := NewOverrides([]Override{
+		{Pattern: "deploy/**", Role: model.RoleInfra},
 	})
 
 	tests := []struct {
@@ -54,8 +55,8 @@ func TestOverridesMatch_DoublestarPattern(t *testing.T) {
 }
 
 func TestOverridesMatch_SimpleGlob(t *testing.T) {
-	overrides := NewOverrides(map[model.Role][]string{
-		model.RoleTest: {"*_test.go"},
+	overrides := NewOverrides([]Override{
+		{Pattern: "*_test.go", Role: model.RoleTest},
 	})
 
 	tests := []struct {
@@ -82,8 +83,8 @@ func TestOverridesMatch_SimpleGlob(t *testing.T) {
 }
 
 func TestOverridesMatch_ExtensionPattern(t *testing.T) {
-	overrides := NewOverrides(map[model.Role][]string{
-		model.RoleGenerated: {"**/*.pb.go"},
+	overrides := NewOverrides([]Override{
+		{Pattern: "**/*.pb.go", Role: model.RoleGenerated},
 	})
 
 	tests := []struct {
@@ -111,9 +112,9 @@ func TestOverridesMatch_ExtensionPattern(t *testing.T) {
 }
 
 func TestOverridesMatch_ReturnsCorrectRole(t *testing.T) {
-	overrides := NewOverrides(map[model.Role][]string{
-		model.RoleInfra: {"deploy/**"},
-		model.RoleTest:  {"test/**"},
+	overrides := NewOverrides([]Override{
+		{Pattern: "deploy/**", Role: model.RoleInfra},
+		{Pattern: "test/**", Role: model.RoleTest},
 	})
 
 	result := overrides.Match("deploy/script.sh")
@@ -129,8 +130,8 @@ func TestOverridesMatch_ReturnsCorrectRole(t *testing.T) {
 }
 
 func TestOverridesMatch_NoMatch(t *testing.T) {
-	overrides := NewOverrides(map[model.Role][]string{
-		model.RoleInfra: {"deploy/**"},
+	overrides := NewOverrides([]Override{
+		{Pattern: "deploy/**", Role: model.RoleInfra},
 	})
 
 	result := overrides.Match("src/main.go")
@@ -140,18 +141,83 @@ func TestOverridesMatch_NoMatch(t *testing.T) {
 }
 
 func TestOverridesMatch_FirstMatchWins(t *testing.T) {
-	overrides := NewOverrides(map[model.Role][]string{
-		model.RoleInfra: {"ops/**"},
-		model.RoleTest:  {"ops/**"},
+	overrides := NewOverrides([]Override{
+		{Pattern: "ops/**", Role: model.RoleInfra},
+		{Pattern: "ops/**", Role: model.RoleTest},
 	})
 
 	result := overrides.Match("ops/script.sh")
 	if result == nil {
 		t.Fatal("Match returned nil")
 	}
-	// First match should win (order may vary since maps are unordered)
-	if result.Role != model.RoleInfra && result.Role != model.RoleTest {
-		t.Errorf("Role = %v, want infra or test", result.Role)
+	// list order decides between equal priorities, on every run
+	if result.Role != model.RoleInfra {
+		t.Errorf("Role = %v, want infra (listed first)", result.Role)
+	}
+}
+
+func TestOverridesMatch_Priority(t *testing.T) {
+	overrides := NewOverrides([]Override{
+		{Pattern: "ops/**", Role: model.RoleInfra},
+		{Pattern: "ops/tests/**", Role: model.RoleTest, SubRole: model.TestE2E, Priority: 10},
+		{Pattern: "ops/**", Role: model.RoleConfig, Priority: 10},
+	})
+
+	tests := []struct {
+		path string
+		role model.Role
+	}{
+		{"ops/tests/smoke.sh", model.RoleTest},
+		{"ops/deploy.sh", model.RoleConfig},
+	}
+	for _, tt := range tests {
+		result := overrides.Match(tt.path)
+		if result == nil || result.Role != tt.role {
+			t.Errorf("Match(%q) = %+v, want role %v", tt.path, result, tt.role)
+		}
+	}
+	if result := overrides.Match("ops/tests/smoke.sh"); result.SubRole != model.TestE2E {
+		t.Errorf("SubRole = %v, want e2e", result.SubRole)
+	}
+}
+
+func TestOverrides_Unmatched(t *testing.T) {
+	overrides := NewOverrides([]Override{
+		{Pattern: "deploy/**", Role: model.RoleInfra},
+		{Pattern: "dpeloy/**", Role: model.RoleInfra},
+	})
+	overrides.Match("deploy/run.sh")
+	overrides.Language("dpeloy/run.sh") // language lookups are not matches
+
+	unmatched := overrides.Unmatched()
+	if len(unmatched) != 1 || unmatched[0].Pattern != "dpeloy/**" {
+		t.Errorf("Unmatched = %+v, want only dpeloy/**", unmatched)
+	}
+}
+
+func TestNewOverride_Validation(t *testing.T) {
+	tests := []struct {
+		name       string
+		pattern    string
+		role       model.Role
+		subRole    model.SubRole
+		confidence float32
+		wantErr    bool
+	}{
+		{"valid", "ops/**", model.RoleInfra, "", 0, false},
+		{"test sub-role", "e2e/**", model.RoleTest, model.TestE2E, 0.9, false},
+		{"empty pattern", "", model.RoleInfra, "", 0, true},
+		{"unknown role", "ops/**", model.Role("ops"), "", 0, true},
+		{"sub-role without test", "ops/**", model.RoleInfra, model.TestE2E, 0, true},
+		{"confidence above 1", "ops/**", model.RoleInfra, "", 1.5, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewOverride(tt.pattern, tt.role, tt.subRole, "", tt.confidence, 0)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
 	}
 }
 
@@ -264,7 +330,7 @@ func TestMatchGlob_PathNormalization(t *testing.T) {
 }
 
 func TestOverridesMatch_EmptyConfig(t *testing.T) {
-	overrides := NewOverrides(map[model.Role][]string{})
+	overrides := NewOverrides([]Override{})
 
 	result := overrides.Match("any/path/file.go")
 	if result != nil {
@@ -313,8 +379,10 @@ func TestMatchDoublestar_InvalidPattern(t *testing.T) {
 }
 
 func TestOverridesMatch_MultiplePatterns(t *testing.T) {
-	overrides := NewOverrides(map[model.Role][]string{
-		model.RoleInfra: {"deploy/**", "infra/**", "*.tf"},
+	overrides := NewOverrides([]Override{
+		{Pattern: "deploy/**", Role: model.RoleInfra},
+		{Pattern: "infra/**", Role: model.RoleInfra},
+		{Pattern: "*.tf", Role: model.RoleInfra},
 	})
 
 	tests := []struct {
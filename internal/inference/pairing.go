@@ -0,0 +1,249 @@
+package inference
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+// TestPair maps test files to the source file they test, for layouts the
+// naming conventions miss. Templates use {dir} for any directories and
+// {name} for one path segment, e.g. test "spec/{dir}/{name}_spec.rb" and
+// source "lib/{dir}/{name}.rb".
+type TestPair struct {
+	Source string
+	Test   string
+
+	re *regexp.Regexp
+}
+
+var pairPlaceholderRE = regexp.MustCompile(`\{[a-z]*\}/?`)
+
+// NewTestPair validates a mapping and compiles its test template. Every
+// placeholder of the source must appear in the test.
+func NewTestPair(source, test string) (TestPair, error) {
+	pair := TestPair{Source: source, Test: test}
+	if source == "" || test == "" {
+		return pair, fmt.Errorf("test pair: source and test are required")
+	}
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	seen := make(map[string]bool)
+	last := 0
+	for _, loc := range pairPlaceholderRE.FindAllStringIndex(test, -1) {
+		expr.WriteString(regexp.QuoteMeta(test[last:loc[0]]))
+		last = loc[1]
+		switch ph := test[loc[0]:loc[1]]; ph {
+		case "{dir}/":
+			expr.WriteString(`(?:(?P<dir>.+)/)?`)
+		case "{dir}":
+			expr.WriteString(`(?P<dir>.*)`)
+		case "{name}", "{name}/":
+			expr.WriteString(`(?P<name>[^/]+)`)
+			if strings.HasSuffix(ph, "/") {
+				expr.WriteString("/")
+			}
+		default:
+			return pair, fmt.Errorf("test pair %q: unknown placeholder %s (use {dir} or {name})", test, strings.TrimSuffix(ph, "/"))
+		}
+		name := strings.Trim(test[loc[0]:loc[1]], "{}/")
+		if seen[name] {
+			return pair, fmt.Errorf("test pair %q: placeholder {%s} used twice", test, name)
+		}
+		seen[name] = true
+	}
+	expr.WriteString(regexp.QuoteMeta(test[last:]))
+	expr.WriteString("$")
+
+	for _, ph := range pairPlaceholderRE.FindAllString(source, -1) {
+		if name := strings.Trim(ph, "{}/"); !seen[name] {
+			return pair, fmt.Errorf("test pair %q: source placeholder {%s} is not in the test template", source, name)
+		}
+	}
+
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return pair, fmt.Errorf("test pair %q: %w", test, err)
+	}
+	pair.re = re
+	return pair, nil
+}
+
+// sourceFor returns the source path the mapping gives a test path, if it matches
+func (p TestPair) sourceFor(testPath string) (string, bool) {
+	m := p.re.FindStringSubmatch(testPath)
+	if m == nil {
+		return "", false
+	}
+	source := p.Source
+	for i, name := range p.re.SubexpNames() {
+		if name == "" {
+			continue
+		}
+		if m[i] == "" {
+			source = strings.ReplaceAll(source, "{"+name+"}/", "")
+		}
+		source = strings.ReplaceAll(source, "{"+name+"}", m[i])
+	}
+	return source, true
+}
+
+// testStemMarkers strip the test marker from a test file's base name,
+// leaving the name of the source it tests: foo_test.go, foo.spec.ts,
+// FooTest.java, FooSpec.scala, test_foo.py, foo_spec.rb
+var testStemMarkers = []*regexp.Regexp{
+	regexp.MustCompile(`^(.+)[._-](?:test|tests|spec|specs)$`),
+	regexp.MustCompile(`^(.+[a-z0-9])(?:Tests?|Spec|IT)$`),
+	regexp.MustCompile(`^tests?_(.+)$`),
+	regexp.MustCompile(`^Test([A-Z].*)$`),
+}
+
+// pairingDirNoise are directory names that differ between a source file and
+// its test in common layouts: src/main/java and src/test/java, lib/ and
+// spec/, src/ and __tests__/
+var pairingDirNoise = map[string]bool{
+	"test": true, "tests": true, "__tests__": true, "__test__": true,
+	"spec": true, "specs": true, "testing": true,
+	"unit": true, "integration": true, "e2e": true, "it": true,
+	"src": true, "main": true, "lib": true, "source": true,
+}
+
+// testStem returns the lower-case name of the source a test file tests
+func testStem(p string) string {
+	base := path.Base(p)
+	stem := strings.TrimSuffix(base, path.Ext(base))
+	for _, re := range testStemMarkers {
+		if m := re.FindStringSubmatch(stem); m != nil {
+			return strings.ToLower(m[1])
+		}
+	}
+	return strings.ToLower(stem)
+}
+
+// sourceStem returns the lower-case base name of a source file without its
+// extension
+func sourceStem(p string) string {
+	base := path.Base(p)
+	return strings.ToLower(strings.TrimSuffix(base, path.Ext(base)))
+}
+
+// pairingDir drops the test and source directory names from a path's
+// directory, so a source and its test in mirrored trees compare equal
+func pairingDir(p string) []string {
+	var parts []string
+	for _, c := range strings.Split(path.Dir(p), "/") {
+		if c != "." && !pairingDirNoise[strings.ToLower(c)] {
+			parts = append(parts, c)
+		}
+	}
+	return parts
+}
+
+// languageFamily groups languages whose tests are written in each other,
+// like foo.tsx and foo.test.ts
+func languageFamily(language string) string {
+	switch language {
+	case "TypeScript", "TSX", "JavaScript", "JSX":
+		return "JavaScript"
+	case "C", "C++", "C Header", "C++ Header", "Objective-C", "Objective-C++":
+		return "C"
+	}
+	return language
+}
+
+// sharedTrailingDirs counts the trailing directory names two directories share
+func sharedTrailingDirs(a, b []string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[len(a)-1-n] == b[len(b)-1-n] {
+		n++
+	}
+	return n
+}
+
+// testTreeAbove reports whether a test sits in a test directory whose
+// location, minus test and source directory names, contains the source's:
+// tests/test_invoice.py above billing/invoice.py
+func testTreeAbove(testPath string, testDir, sourceDir []string) bool {
+	dir := path.Dir(testPath)
+	inTestTree := dir != "." && strings.Count(dir, "/")+1 > len(testDir)
+	return inTestTree && len(sourceDir) >= len(testDir) && slices.Equal(sourceDir[:len(testDir)], testDir)
+}
+
+// pairTests records on each core file the test files that test it. A test
+// is paired by the configured mappings when one matches, otherwise by name:
+// with the same-named sources of its language family whose directory, minus
+// test and source directory names, matches best. Sources with no directory
+// in common with the test pair only from a test tree above them, and a name
+// shared by sources that are equally far from the test pairs nothing.
+func (e *Engine) pairTests(records []*model.FileRecord) {
+	type source struct {
+		record *model.FileRecord
+		dir    []string
+	}
+	byPath := make(map[string]*model.FileRecord)
+	byKey := make(map[string][]source) // stem and language family
+	for _, r := range records {
+		r.TestedBy = nil
+		if r.Role != model.RoleCore {
+			continue
+		}
+		p := filepath.ToSlash(r.Path)
+		byPath[p] = r
+		key := sourceStem(p) + "\x00" + languageFamily(r.Language)
+		byKey[key] = append(byKey[key], source{r, pairingDir(p)})
+	}
+
+	for _, t := range records {
+		if t.Role != model.RoleTest {
+			continue
+		}
+		p := filepath.ToSlash(t.Path)
+
+		mapped := false
+		for _, pair := range e.testPairs {
+			if sp, ok := pair.sourceFor(p); ok {
+				mapped = true
+				if s := byPath[sp]; s != nil {
+					s.TestedBy = append(s.TestedBy, t.Path)
+				}
+				break
+			}
+		}
+		if mapped {
+			continue
+		}
+
+		candidates := byKey[testStem(p)+"\x00"+languageFamily(t.Language)]
+		dir := pairingDir(p)
+		best, matches := -1, []source(nil)
+		for _, c := range candidates {
+			score := sharedTrailingDirs(c.dir, dir)
+			if slices.Equal(c.dir, dir) {
+				score = len(dir) + 1 // mirrored trees beat partial matches
+			}
+			switch {
+			case score > best:
+				best, matches = score, []source{c}
+			case score == best:
+				matches = append(matches, c)
+			}
+		}
+		// same-named files in unrelated directories: index.ts, utils.py
+		if best == 0 && (len(matches) != 1 || !testTreeAbove(p, dir, matches[0].dir)) {
+			continue
+		}
+		for _, s := range matches {
+			s.record.TestedBy = append(s.record.TestedBy, t.Path)
+		}
+	}
+
+	for _, r := range records {
+		slices.Sort(r.TestedBy)
+	}
+}
@@ -0,0 +1,105 @@
+package inference
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+func TestPairTests_Conventions(t *testing.T) {
+	core := func(path, lang string) *model.FileRecord {
+		return &model.FileRecord{Path: path, Language: lang, Role: model.RoleCore, LOC: 10}
+	}
+	test := func(path, lang string) *model.FileRecord {
+		return &model.FileRecord{Path: path, Language: lang, Role: model.RoleTest, LOC: 10}
+	}
+	records := []*model.FileRecord{
+		core("internal/api/server.go", "Go"),
+		test("internal/api/server_test.go", "Go"),
+		core("src/main/java/com/acme/Order.java", "Java"),
+		test("src/test/java/com/acme/OrderTest.java", "Java"),
+		core("src/components/Button.tsx", "TSX"),
+		test("src/components/Button.test.ts", "TypeScript"),
+		core("src/hooks/useAuth.ts", "TypeScript"),
+		test("src/hooks/__tests__/useAuth.ts", "TypeScript"),
+		core("billing/invoice.py", "Python"),
+		test("tests/test_invoice.py", "Python"),
+		core("lib/parser.rb", "Ruby"),
+		test("spec/parser_spec.rb", "Ruby"),
+		// the name is shared by unrelated directories: no pair
+		core("src/a/index.ts", "TypeScript"),
+		core("src/b/index.ts", "TypeScript"),
+		test("test/index.test.ts", "TypeScript"),
+		// same-named sources in directories the test has nothing in common with
+		core("internal/render/format.go", "Go"),
+		test("cmd/report/format_test.go", "Go"),
+		core("pkg/cache/lru.go", "Go"),
+		test("lru_test.go", "Go"),
+		// another language with the same name
+		core("scripts/server.py", "Python"),
+		core("internal/api/untested.go", "Go"),
+	}
+
+	NewEngine(Options{}).pairTests(records)
+
+	want := map[string][]string{
+		"internal/api/server.go":            {"internal/api/server_test.go"},
+		"src/main/java/com/acme/Order.java": {"src/test/java/com/acme/OrderTest.java"},
+		"src/components/Button.tsx":         {"src/components/Button.test.ts"},
+		"src/hooks/useAuth.ts":              {"src/hooks/__tests__/useAuth.ts"},
+		"billing/invoice.py":                {"tests/test_invoice.py"},
+		"lib/parser.rb":                     {"spec/parser_spec.rb"},
+	}
+	for _, r := range records {
+		if r.Role != model.RoleCore {
+			if r.TestedBy != nil {
+				t.Errorf("%s: test file got TestedBy %v", r.Path, r.TestedBy)
+			}
+			continue
+		}
+		if !slices.Equal(r.TestedBy, want[r.Path]) {
+			t.Errorf("%s: TestedBy = %v, want %v", r.Path, r.TestedBy, want[r.Path])
+		}
+	}
+}
+
+func TestPairTests_ConfiguredPairs(t *testing.T) {
+	pair, err := NewTestPair("app/{dir}/{name}.php", "checks/{dir}/{name}Check.php")
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := []*model.FileRecord{
+		{Path: "app/Http/Kernel.php", Language: "PHP", Role: model.RoleCore},
+		{Path: "app/Router.php", Language: "PHP", Role: model.RoleCore},
+		{Path: "checks/Http/KernelCheck.php", Language: "PHP", Role: model.RoleTest},
+		{Path: "checks/RouterCheck.php", Language: "PHP", Role: model.RoleTest},
+	}
+
+	NewEngine(Options{TestPairs: []TestPair{pair}}).pairTests(records)
+
+	if got := records[0].TestedBy; !slices.Equal(got, []string{"checks/Http/KernelCheck.php"}) {
+		t.Errorf("Kernel.php TestedBy = %v", got)
+	}
+	if got := records[1].TestedBy; !slices.Equal(got, []string{"checks/RouterCheck.php"}) {
+		t.Errorf("Router.php TestedBy = %v, want the mapping without {dir}", got)
+	}
+}
+
+func TestNewTestPair_Invalid(t *testing.T) {
+	tests := []struct {
+		name, source, test string
+	}{
+		{"missing test", "src/{name}.go", ""},
+		{"unknown placeholder", "src/{name}.go", "test/{file}_test.go"},
+		{"source placeholder not in test", "src/{dir}/{name}.go", "test/{name}_test.go"},
+		{"placeholder used twice", "src/{name}.go", "test/{name}/{name}_test.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTestPair(tt.source, tt.test); err == nil {
+				t.Errorf("NewTestPair(%q, %q) succeeded, want an error", tt.source, tt.test)
+			}
+		})
+	}
+}
@@ -50,6 +50,11 @@ var PathRules = []PathRule{
 	{"/samples/", model.RoleExamples, 0.55},
 	{"/demo/", model.RoleExamples, 0.55},
 
+	// Migrations paths
+	{"/migrations/", model.RoleMigrations, 0.60},
+	{"/db/migrate/", model.RoleMigrations, 0.70},
+	{"/db/migration/", model.RoleMigrations, 0.70},
+
 	// Vendor paths (high confidence)
 	{"/vendor/", model.RoleVendor, 0.90},
 	{"/third_party/", model.RoleVendor, 0.90},
@@ -68,7 +73,7 @@ type FilenameRule struct {
 	Pattern   string
 	MatchType string // "suffix", "prefix", "contains"
 	Role      model.Role
-	SubRole   model.TestKind
+	SubRole   model.SubRole
 	Weight    float32
 }
 
@@ -127,6 +132,9 @@ var ExtensionRules = []ExtensionRule{
 	{".mdx", model.RoleDocs, 0.20},
 	{".rst", model.RoleDocs, 0.20},
 	{".adoc", model.RoleDocs, 0.20},
+	{".org", model.RoleDocs, 0.20},
+	{".rmd", model.RoleDocs, 0.20},
+	{".qmd", model.RoleDocs, 0.20},
 
 	// Config extensions (weak signal)
 	{".yaml", model.RoleConfig, 0.15},
@@ -9,14 +9,20 @@ import (
 type RoleScore struct {
 	Weights  map[model.Role]float32
 	Signals  map[model.Role][]model.Signal
-	SubRoles map[model.Role]model.TestKind
+	SubRoles map[model.Role]model.SubRole
+
+	excluded map[model.Role]bool // roles ruled out, e.g. by -linguist-vendored
+
+	tracing bool
+	trace   []model.RuleHit
+	skipped []string
 }
 
 func NewRoleScore() *RoleScore {
 	return &RoleScore{
 		Weights:  make(map[model.Role]float32),
 		Signals:  make(map[model.Role][]model.Signal),
-		SubRoles: make(map[model.Role]model.TestKind),
+		SubRoles: make(map[model.Role]model.SubRole),
 	}
 }
 
@@ -25,13 +31,47 @@ func (s *RoleScore) Add(role model.Role, weight float32, signal model.Signal) {
 	s.Signals[role] = append(s.Signals[role], signal)
 }
 
-func (s *RoleScore) AddWithSubRole(role model.Role, subRole model.TestKind, weight float32, signal model.Signal) {
+func (s *RoleScore) AddWithSubRole(role model.Role, subRole model.SubRole, weight float32, signal model.Signal) {
 	s.Add(role, weight, signal)
 	if subRole != "" {
 		s.SubRoles[role] = subRole
 	}
 }
 
+// addRule adds a rule's evidence, recording it when tracing
+func (s *RoleScore) addRule(role model.Role, subRole model.SubRole, weight float32, signal model.Signal, pattern string, custom bool) {
+	if s.excluded[role] {
+		return
+	}
+	s.AddWithSubRole(role, subRole, weight, signal)
+	if s.tracing {
+		s.trace = append(s.trace, model.RuleHit{
+			Signal:  signal,
+			Pattern: pattern,
+			Role:    role,
+			SubRole: subRole,
+			Weight:  weight,
+			Custom:  custom,
+		})
+	}
+}
+
+// skip records a rule stage that was not evaluated, when tracing
+func (s *RoleScore) skip(reason string) {
+	if s.tracing {
+		s.skipped = append(s.skipped, reason)
+	}
+}
+
+// exclude rules a role out: evidence for it is ignored from now on
+func (s *RoleScore) exclude(role model.Role, reason string) {
+	if s.excluded == nil {
+		s.excluded = make(map[model.Role]bool)
+	}
+	s.excluded[role] = true
+	s.skip(reason)
+}
+
 func (s *RoleScore) MaxWeight() float32 {
 	var max float32
 	for _, w := range s.Weights {
@@ -47,9 +87,25 @@ type rankedRole struct {
 	Weight float32
 }
 
-func (s *RoleScore) Resolve() (model.Role, model.TestKind, float32, []model.Signal) {
+// resolution is the outcome of Resolve with the factors that produced it
+type resolution struct {
+	role             model.Role
+	subRole          model.SubRole
+	confidence       float32
+	signals          []model.Signal
+	runnerUp         model.Role
+	ambiguityPenalty float32
+	agreementFactor  float32
+}
+
+func (s *RoleScore) Resolve() (model.Role, model.SubRole, float32, []model.Signal) {
+	r := s.resolve()
+	return r.role, r.subRole, r.confidence, r.signals
+}
+
+func (s *RoleScore) resolve() resolution {
 	if len(s.Weights) == 0 {
-		return model.RoleCore, "", 0.30, nil
+		return resolution{role: model.RoleCore, confidence: 0.30, ambiguityPenalty: 1}
 	}
 
 	// Sort by weight descending
@@ -59,7 +115,11 @@ func (s *RoleScore) Resolve() (model.Role, model.TestKind, float32, []model.Sign
 	}
 	sort.Slice(ranked, func(i, j int) bool {
 		if ranked[i].Weight == ranked[j].Weight {
-			return rolePriority(ranked[i].Role) < rolePriority(ranked[j].Role)
+			pi, pj := rolePriority(ranked[i].Role), rolePriority(ranked[j].Role)
+			if pi == pj {
+				return ranked[i].Role < ranked[j].Role // custom roles may share a priority
+			}
+			return pi < pj
 		}
 		return ranked[i].Weight > ranked[j].Weight
 	})
@@ -69,12 +129,16 @@ func (s *RoleScore) Resolve() (model.Role, model.TestKind, float32, []model.Sign
 	confidence := topWeight
 
 	// Ambiguity penalty
+	var runnerUp model.Role
+	penalty := float32(1.0)
 	if len(ranked) > 1 {
+		runnerUp = ranked[1].Role
 		secondWeight := ranked[1].Weight
 		if topWeight-secondWeight < 0.15 {
-			confidence *= 0.8 // 20% penalty
+			penalty = 0.8 // 20% penalty
 		}
 	}
+	confidence *= penalty
 
 	// Agreement bonus
 	signals := s.Signals[topRole]
@@ -87,34 +151,24 @@ func (s *RoleScore) Resolve() (model.Role, model.TestKind, float32, []model.Sign
 		confidence = 1.0
 	}
 
-	// Get sub-role for test
-	var subRole model.TestKind
-	if topRole == model.RoleTest {
-		subRole = s.SubRoles[topRole]
-		if subRole == "" {
-			subRole = model.TestUnit
-		}
+	// Sub-role of the top role; tests default to unit
+	subRole := s.SubRoles[topRole]
+	if topRole == model.RoleTest && subRole == "" {
+		subRole = model.TestUnit
 	}
 
-	return topRole, subRole, confidence, signals
+	return resolution{
+		role:             topRole,
+		subRole:          subRole,
+		confidence:       confidence,
+		signals:          signals,
+		runnerUp:         runnerUp,
+		ambiguityPenalty: penalty,
+		agreementFactor:  agreementFactor,
+	}
 }
 
 // rolePriority returns the tie-break priority (lower is higher priority)
 func rolePriority(role model.Role) int {
-	priorities := map[model.Role]int{
-		model.RoleVendor:     1,
-		model.RoleGenerated:  2,
-		model.RoleTest:       3,
-		model.RoleInfra:      4,
-		model.RoleCore:       5,
-		model.RoleDocs:       6,
-		model.RoleConfig:     7,
-		model.RoleScripts:    8,
-		model.RoleExamples:   9,
-		model.RoleDeprecated: 10,
-	}
-	if p, ok := priorities[role]; ok {
-		return p
-	}
-	return 100
+	return role.Info().Priority
 }
@@ -256,3 +256,18 @@ func TestRolePriority_Unknown(t *testing.T) {
 		t.Errorf("rolePriority(unknown) = %v, want 100", got)
 	}
 }
+
+func TestRoleScoreResolve_TieBreak_CustomRole(t *testing.T) {
+	defer model.ResetRoles()
+	if err := model.RegisterRole(model.RoleInfo{Role: "seeds", Priority: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	score := NewRoleScore()
+	score.Add(model.RoleCore, 0.60, model.SignalPath)
+	score.Add("seeds", 0.60, model.SignalPath)
+
+	if role, _, _, _ := score.Resolve(); role != "seeds" {
+		t.Errorf("Role = %v, want seeds (priority 2 beats core's 5)", role)
+	}
+}
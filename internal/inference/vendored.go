@@ -0,0 +1,298 @@
+package inference
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+// vendoredTree is a directory copied from an upstream project
+type vendoredTree struct {
+	Dir      string // slash-separated, relative to the root
+	Upstream string // e.g. "github.com/pkg/errors v0.9.1"; "" if unknown
+	Source   string // what gave it away, e.g. "vendor/modules.txt"
+	Weight   float32
+}
+
+// knownLibrary recognizes a copied library by its file name
+type knownLibrary struct {
+	Name    string // upstream name; "" takes it from the first group
+	Pattern *regexp.Regexp
+}
+
+// knownLibraries match lower-case base names. Web libraries are only
+// recognized with a version or minified, as their plain names are common.
+var knownLibraries = []knownLibrary{
+	{"SQLite", regexp.MustCompile(`^sqlite3(?:ext)?\.[ch]$`)},
+	{"stb", regexp.MustCompile(`^stb_[a-z_]+\.h$`)},
+	{"miniz", regexp.MustCompile(`^miniz\.[ch]$`)},
+	{"cJSON", regexp.MustCompile(`^cjson\.[ch]$`)},
+	{"Duktape", regexp.MustCompile(`^duktape\.[ch]$`)},
+	{"TinyXML-2", regexp.MustCompile(`^tinyxml2\.(?:cpp|h)$`)},
+	{"pugixml", regexp.MustCompile(`^pugixml\.(?:cpp|hpp)$`)},
+	{"LZ4", regexp.MustCompile(`^lz4(?:hc)?\.[ch]$`)},
+	{"xxHash", regexp.MustCompile(`^xxhash\.[ch]$`)},
+	{"Dear ImGui", regexp.MustCompile(`^imgui(?:_[a-z]+)?\.(?:cpp|h)$`)},
+	{"", regexp.MustCompile(`^(jquery(?:-ui)?|bootstrap|lodash|underscore|backbone|angular|react-dom|vue|d3|moment|popper|select2|handlebars|modernizr|knockout|leaflet|htmx|alpine)\.min\.(?:js|css)$`)},
+	{"", regexp.MustCompile(`^(jquery(?:-ui)?)\.js$`)},
+	// name-1.2.3.js, name.v1.2.min.css
+	{"", regexp.MustCompile(`^([a-z][a-z0-9]*(?:[.-][a-z][a-z0-9]*)*?)[.-]v?(\d+\.\d+(?:\.\d+)?)(?:\.min)?\.(?:js|css)$`)},
+}
+
+// libraryBannerRE matches the license banner minifiers keep at the top of
+// a library, e.g. "/*! jQuery v3.6.0 | (c) OpenJS Foundation"
+var libraryBannerRE = regexp.MustCompile(`^\s*/\*[!*]\s*(?:\*\s*)?(?:@license\s+)?([A-Za-z][\w.\-]*(?: [A-Za-z][\w.\-]*){0,2})\s+v?(\d+\.\d+(?:\.\d+)?)\b`)
+
+var (
+	licenseNameRE    = regexp.MustCompile(`^(?:licen[cs]e|copying|unlicense)(?:[.-].*)?$`)
+	tomlNameRE       = regexp.MustCompile(`(?m)^name\s*=\s*["']([^"']+)["']`)
+	tomlVersionRE    = regexp.MustCompile(`(?m)^version\s*=\s*["']([^"']+)["']`)
+	setupNameRE      = regexp.MustCompile(`\bname\s*=\s*["']([^"']+)["']`)
+	gemspecNameRE    = regexp.MustCompile(`\.name\s*=\s*["']([^"']+)["']`)
+	goModModuleRE    = regexp.MustCompile(`(?m)^module\s+(\S+)`)
+	nonLetterRE      = regexp.MustCompile(`[^a-z]+`)
+	packageManifests = []string{"package.json", "composer.json", "bower.json", "cargo.toml", "pyproject.toml", "setup.py", "go.mod"}
+)
+
+// findVendoredTrees finds the directories copied from upstream projects:
+// the modules listed in Go's vendor/modules.txt, top-level vendor/ and
+// third_party/ directories, and directories whose license differs from the
+// one above them or whose package.json was written by a package manager.
+// Deeper trees come first.
+func (e *Engine) findVendoredTrees(files []*model.RawFile) []vendoredTree {
+	var trees []vendoredTree
+	dirs := make(map[string]bool)
+	for _, f := range files {
+		p := filepath.ToSlash(f.Path)
+		for d := path.Dir(p); d != "." && d != "/" && !dirs[d]; d = path.Dir(d) {
+			dirs[d] = true
+		}
+		if path.Base(p) == "modules.txt" && path.Base(path.Dir(p)) == "vendor" {
+			if content, err := e.content(f); err == nil {
+				trees = append(trees, goVendorModules(path.Dir(p), content)...)
+			}
+		}
+	}
+	for _, top := range []string{"vendor", "third_party"} {
+		if dirs[top] {
+			trees = append(trees, vendoredTree{Dir: top, Source: top + "/", Weight: 0.90})
+		}
+	}
+
+	// walk down the tree so each directory's license is compared with the
+	// closest one above it
+	sorted := make([]string, 0, len(dirs))
+	for d := range dirs {
+		sorted = append(sorted, d)
+	}
+	slices.SortFunc(sorted, func(a, b string) int {
+		if c := strings.Count(a, "/") - strings.Count(b, "/"); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+	licenses := map[string]string{"": e.licenseText("")}
+	inherited := func(d string) string {
+		for d = path.Dir(d); d != "."; d = path.Dir(d) {
+			if l, ok := licenses[d]; ok {
+				return l
+			}
+		}
+		return licenses[""]
+	}
+	for _, d := range sorted {
+		license := e.licenseText(d)
+		if license != "" {
+			licenses[d] = license
+		}
+		name, manifest, installed, private := e.readManifest(d)
+		switch {
+		case private:
+		case installed:
+			trees = append(trees, vendoredTree{Dir: d, Upstream: name, Source: manifest + " written by a package manager", Weight: 0.90})
+		case license != "" && license != inherited(d):
+			if name == "" {
+				name = path.Base(d)
+			}
+			source := "own license"
+			if manifest != "" {
+				source += " and " + manifest
+			}
+			trees = append(trees, vendoredTree{Dir: d, Upstream: name, Source: source, Weight: 0.85})
+		}
+	}
+
+	slices.SortStableFunc(trees, func(a, b vendoredTree) int {
+		return strings.Count(b.Dir, "/") - strings.Count(a.Dir, "/")
+	})
+	return trees
+}
+
+// goVendorModules reads the "# module version" lines of a vendor/modules.txt
+func goVendorModules(vendorDir string, content []byte) []vendoredTree {
+	var trees []vendoredTree
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "#" {
+			continue
+		}
+		upstream := fields[1]
+		if len(fields) > 2 && fields[2] != "=>" {
+			upstream += " " + fields[2]
+		}
+		trees = append(trees, vendoredTree{
+			Dir:      vendorDir + "/" + fields[1],
+			Upstream: upstream,
+			Source:   vendorDir + "/modules.txt",
+			Weight:   0.95,
+		})
+	}
+	return trees
+}
+
+// dirNames lists the entries of a directory relative to the root
+func (e *Engine) dirNames(dir string) []string {
+	f, err := os.Open(e.abs(filepath.FromSlash(dir)))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	names, _ := f.Readdirnames(-1)
+	slices.Sort(names)
+	return names
+}
+
+// licenseText returns the license files of a directory reduced to their
+// letters, so that years and layout do not tell copies apart, or ""
+func (e *Engine) licenseText(dir string) string {
+	var b strings.Builder
+	for _, name := range e.dirNames(dir) {
+		if !licenseNameRE.MatchString(strings.ToLower(name)) {
+			continue
+		}
+		content, err := os.ReadFile(e.abs(filepath.FromSlash(path.Join(dir, name))))
+		if err == nil {
+			b.WriteString(nonLetterRE.ReplaceAllString(strings.ToLower(string(content)), ""))
+		}
+	}
+	return b.String()
+}
+
+// readManifest returns the upstream name and version declared by the first
+// package manifest in a directory, the manifest's name, and whether it is a
+// package.json installed by npm or marked private
+func (e *Engine) readManifest(dir string) (name, manifest string, installed, private bool) {
+	var names []string
+	for _, n := range e.dirNames(dir) {
+		lower := strings.ToLower(n)
+		if slices.Contains(packageManifests, lower) || strings.HasSuffix(lower, ".gemspec") {
+			names = append(names, n)
+		}
+	}
+	for _, n := range names {
+		content, err := os.ReadFile(e.abs(filepath.FromSlash(path.Join(dir, n))))
+		if err != nil {
+			continue
+		}
+		var version string
+		switch lower := strings.ToLower(n); {
+		case strings.HasSuffix(lower, ".json"):
+			var pkg struct {
+				Name     string `json:"name"`
+				Version  string `json:"version"`
+				Private  bool   `json:"private"`
+				Resolved string `json:"_resolved"`
+				From     string `json:"_from"`
+				ID       string `json:"_id"`
+			}
+			if json.Unmarshal(content, &pkg) != nil {
+				continue
+			}
+			name, version, private = pkg.Name, pkg.Version, pkg.Private
+			installed = lower == "package.json" && (pkg.Resolved != "" || pkg.From != "" || pkg.ID != "")
+		case strings.HasSuffix(lower, ".toml"):
+			name, version = submatch(tomlNameRE, content), submatch(tomlVersionRE, content)
+		case lower == "setup.py":
+			name = submatch(setupNameRE, content)
+		case lower == "go.mod":
+			name = submatch(goModModuleRE, content)
+		default:
+			name = submatch(gemspecNameRE, content)
+		}
+		if name == "" {
+			continue
+		}
+		if version != "" {
+			name += " " + version
+		}
+		return name, n, installed, private
+	}
+	return "", "", false, false
+}
+
+func submatch(re *regexp.Regexp, content []byte) string {
+	if m := re.FindSubmatch(content); m != nil {
+		return string(m[1])
+	}
+	return ""
+}
+
+// applyVendorRules adds evidence for files in a vendored tree and for known
+// libraries, returning the upstream project the file came from, if known
+func (e *Engine) applyVendorRules(file *model.RawFile, score *RoleScore) string {
+	var upstream string
+	p := filepath.ToSlash(file.Path)
+	for _, t := range e.vendored {
+		if strings.HasPrefix(p, t.Dir+"/") {
+			score.addRule(model.RoleVendor, "", t.Weight, model.SignalPath, t.Dir+"/ ("+t.Source+")", false)
+			upstream = t.Upstream
+			break
+		}
+	}
+
+	base := strings.ToLower(path.Base(p))
+	for _, lib := range knownLibraries {
+		m := lib.Pattern.FindStringSubmatch(base)
+		if m == nil {
+			continue
+		}
+		name := lib.Name
+		if name == "" {
+			name = m[1]
+			if len(m) > 2 {
+				name += " " + m[2]
+			}
+		}
+		score.addRule(model.RoleVendor, "", 0.85, model.SignalFilename, name, false)
+		if upstream == "" {
+			upstream = name
+		}
+		break
+	}
+
+	// minified libraries keep their license banner
+	if e.enableHeaderProbe && file.Asset == nil {
+		switch path.Ext(base) {
+		case ".js", ".css":
+			if header, err := e.head(file, headerProbeBytes); err == nil {
+				if m := libraryBannerRE.FindSubmatch(header); m != nil {
+					name := string(m[1]) + " " + string(m[2])
+					score.addRule(model.RoleVendor, "", 0.80, model.SignalHeader, "/*! "+name, false)
+					if upstream == "" {
+						upstream = name
+					}
+				}
+			}
+		}
+	}
+	return upstream
+}
@@ -0,0 +1,81 @@
+package inference
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+func TestInferBatch_Vendored(t *testing.T) {
+	root := t.TempDir()
+	mit := "MIT License\n\nCopyright (c) %s Acme Inc.\n\nPermission is hereby granted, free of charge...\n"
+	files := map[string]string{
+		"LICENSE":                                strings.ReplaceAll(mit, "%s", "2024"),
+		"src/main.go":                            "package main\n",
+		"vendor/modules.txt":                     "# github.com/pkg/errors v0.9.1\n## explicit\ngithub.com/pkg/errors\n",
+		"vendor/github.com/pkg/errors/errors.go": "package errors\n",
+		"packages/ui/LICENSE":                    strings.ReplaceAll(mit, "%s", "2021"), // ours: same license
+		"packages/ui/package.json":               `{"name": "@acme/ui", "version": "1.0.0"}`,
+		"packages/ui/button.js":                  "export const Button = 1\n",
+		"lib/zlib/README":                        "zlib 1.3\n",
+		"lib/zlib/LICENSE":                       "Copyright (C) 1995-2023 Jean-loup Gailly and Mark Adler\n",
+		"lib/zlib/src/inflate.c":                 "int inflate(void) { return 0; }\n",
+		"web/lib/chart/package.json":             `{"name": "chart.js", "version": "4.4.0", "_resolved": "https://registry.npmjs.org/chart.js/-/chart.js-4.4.0.tgz"}`,
+		"web/lib/chart/dist/chart.umd.js":        "var Chart = 1\n",
+		"web/static/jquery-3.6.0.min.js":         "!function(e){}\n",
+		"native/sqlite3.c":                       "int sqlite3_open(void) { return 0; }\n",
+		"tools/private/LICENSE":                  "Proprietary\n",
+		"tools/private/package.json":             `{"name": "internal-tools", "private": true}`,
+		"tools/private/run.js":                   "run()\n",
+	}
+	var raw []*model.RawFile
+	for rel, content := range files {
+		path := filepath.Join(root, rel)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+		raw = append(raw, &model.RawFile{Path: rel, LOC: 1})
+	}
+
+	records := NewEngine(Options{Root: root}).InferBatch(raw)
+	byPath := make(map[string]*model.FileRecord)
+	for _, r := range records {
+		byPath[r.Path] = r
+	}
+
+	tests := []struct {
+		path     string
+		role     model.Role
+		upstream string
+	}{
+		{"src/main.go", model.RoleCore, ""},
+		{"vendor/github.com/pkg/errors/errors.go", model.RoleVendor, "github.com/pkg/errors v0.9.1"},
+		{"packages/ui/button.js", model.RoleCore, ""},
+		{"lib/zlib/src/inflate.c", model.RoleVendor, "zlib"},
+		{"web/lib/chart/dist/chart.umd.js", model.RoleVendor, "chart.js 4.4.0"},
+		{"web/static/jquery-3.6.0.min.js", model.RoleVendor, "jquery 3.6.0"},
+		{"native/sqlite3.c", model.RoleVendor, "SQLite"},
+		{"tools/private/run.js", model.RoleCore, ""},
+	}
+	for _, tt := range tests {
+		r := byPath[tt.path]
+		if r.Role != tt.role || r.Upstream != tt.upstream {
+			t.Errorf("%s = %v (%q), want %v (%q)", tt.path, r.Role, r.Upstream, tt.role, tt.upstream)
+		}
+	}
+}
+
+func TestEngineInfer_LibraryBanner(t *testing.T) {
+	header := "/*!\n * Bootstrap v4.6.2 (https://getbootstrap.com/)\n */\n.btn{}\n"
+	file := &model.RawFile{Path: "/nonexistent/static/css/theme.css", LOC: 1, Bytes: int64(len(header)), Header: []byte(header)}
+
+	record := NewEngine(Options{HeaderProbe: true}).Infer(file)
+	if record.Role != model.RoleVendor || record.Upstream != "Bootstrap 4.6.2" {
+		t.Errorf("role = %v (%q), want vendor (Bootstrap 4.6.2)", record.Role, record.Upstream)
+	}
+	if record := NewEngine(Options{}).Infer(file); record.Role == model.RoleVendor {
+		t.Errorf("role = %v without header probing, want the banner ignored", record.Role)
+	}
+}
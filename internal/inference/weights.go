@@ -0,0 +1,131 @@
+package inference
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+// Weights are rule weights fitted by aloc learn. Each entry replaces the
+// weight of the built-in rule with the same kind, pattern and role; a weight
+// of 0 disables that rule. Path entries that match no built-in rule are
+// learned directory rules: "/checks/" matches any path with a checks
+// directory, including a top-level one.
+type Weights struct {
+	Examples int                `json:"examples"` // labeled files the weights were fitted on
+	Rules    []model.RuleWeight `json:"rules"`
+}
+
+// LoadWeights reads and validates a weights file written by aloc learn
+func LoadWeights(path string) (*Weights, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var w Weights
+	if err := json.Unmarshal(data, &w); err != nil {
+		return nil, fmt.Errorf("weights %s: %w", path, err)
+	}
+	if err := w.validate(); err != nil {
+		return nil, fmt.Errorf("weights %s: %w", path, err)
+	}
+	return &w, nil
+}
+
+// Write saves the weights as indented JSON
+func (w *Weights) Write(path string) error {
+	data, err := json.MarshalIndent(w, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+func (w *Weights) validate() error {
+	builtin := builtinWeights()
+	for _, r := range w.Rules {
+		kind := RuleKind(r.Kind)
+		if _, ok := ruleSignals[kind]; !ok {
+			return fmt.Errorf("rule %q: unknown kind %q", r.Pattern, r.Kind)
+		}
+		if !slices.Contains(model.AllRoles, r.Role) {
+			return fmt.Errorf("%s rule %q: unknown role %q", r.Kind, r.Pattern, r.Role)
+		}
+		if r.Weight < 0 || r.Weight > 1 {
+			return fmt.Errorf("%s rule %q: weight must be between 0 and 1, got %v", r.Kind, r.Pattern, r.Weight)
+		}
+		if _, ok := builtin[newWeightKey(kind, r.Pattern, r.Role)]; ok {
+			continue
+		}
+		if kind != RuleKindPath {
+			return fmt.Errorf("%s rule %q (%s): no such built-in rule", r.Kind, r.Pattern, r.Role)
+		}
+		if !isDirFragment(r.Pattern) {
+			return fmt.Errorf("path rule %q: learned rules must be a directory such as /checks/", r.Pattern)
+		}
+	}
+	return nil
+}
+
+// weightKey identifies a rule across the built-in tables and a weights file
+type weightKey struct {
+	kind    RuleKind
+	pattern string // lower case
+	role    model.Role
+}
+
+func newWeightKey(kind RuleKind, pattern string, role model.Role) weightKey {
+	return weightKey{kind, strings.ToLower(pattern), role}
+}
+
+// lookup returns the weights by rule; nil weights yield an empty map
+func (w *Weights) lookup() map[weightKey]float32 {
+	m := make(map[weightKey]float32)
+	if w == nil {
+		return m
+	}
+	for _, r := range w.Rules {
+		m[newWeightKey(RuleKind(r.Kind), r.Pattern, r.Role)] = r.Weight
+	}
+	return m
+}
+
+// rules returns the weight entries; nil weights have none
+func (w *Weights) rules() []model.RuleWeight {
+	if w == nil {
+		return nil
+	}
+	return w.Rules
+}
+
+// builtinWeights returns the default weight of every built-in rule
+func builtinWeights() map[weightKey]float32 {
+	m := make(map[weightKey]float32)
+	for _, r := range PathRules {
+		m[newWeightKey(RuleKindPath, r.Fragment, r.Role)] = r.Weight
+	}
+	for _, r := range FilenameRules {
+		m[newWeightKey(RuleKindFilename, r.Pattern, r.Role)] = r.Weight
+	}
+	for _, r := range ExtensionRules {
+		m[newWeightKey(RuleKindExtension, r.Ext, r.Role)] = r.Weight
+	}
+	for _, r := range HeaderRules {
+		m[newWeightKey(RuleKindHeader, r.Pattern, r.Role)] = r.Weight
+	}
+	return m
+}
+
+// isDirFragment reports whether pattern is a single directory like "/checks/"
+func isDirFragment(pattern string) bool {
+	name, ok := strings.CutPrefix(pattern, "/")
+	if !ok {
+		return false
+	}
+	name, ok = strings.CutSuffix(name, "/")
+	return ok && name != "" && !strings.Contains(name, "/")
+}
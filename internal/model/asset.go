@@ -0,0 +1,37 @@
+package model
+
+// AssetInfo describes a binary asset (image, font, model, archive, ...)
+type AssetInfo struct {
+	Type    string `json:"type"`
+	LFS     bool   `json:"lfs,omitempty"`      // file is a Git LFS pointer
+	LFSSize int64  `json:"lfs_size,omitempty"` // size of the object the pointer stands in for
+}
+
+// AssetInventory summarizes binary assets, which carry no LOC but add
+// repository weight (clone size, CI cache size)
+type AssetInventory struct {
+	Files           int          `json:"files"`
+	Bytes           int64        `json:"bytes"` // LFS pointers count the size of their object, as in ByType and ByRole
+	LFSPointers     int          `json:"lfs_pointers,omitempty"`
+	LFSBytes        int64        `json:"lfs_bytes,omitempty"`         // total size of objects behind LFS pointers
+	LFSPointerBytes int64        `json:"lfs_pointer_bytes,omitempty"` // size of the pointer files themselves
+	ByType          []AssetGroup `json:"by_type"`
+	ByRole          []AssetGroup `json:"by_role"`
+	Largest         []AssetFile  `json:"largest"`
+}
+
+// AssetGroup is the file count and size of assets sharing a type or role
+type AssetGroup struct {
+	Name  string `json:"name"`
+	Files int    `json:"files"`
+	Bytes int64  `json:"bytes"`
+}
+
+// AssetFile is a single asset in the largest-assets list
+type AssetFile struct {
+	Path  string `json:"path"`
+	Type  string `json:"type"`
+	Role  Role   `json:"role"`
+	Bytes int64  `json:"bytes"` // object size for LFS pointers
+	LFS   bool   `json:"lfs,omitempty"`
+}
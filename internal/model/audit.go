@@ -0,0 +1,43 @@
+package model
+
+// Audit lists files whose classification is uncertain, grouped by directory,
+// with overrides that would pin them down
+type Audit struct {
+	Threshold float32      `json:"threshold"` // files below this confidence are listed
+	Files     int          `json:"files"`     // uncertain files
+	LOC       int          `json:"loc"`       // LOC in uncertain files
+	TotalLOC  int          `json:"total_loc"`
+	Groups    []AuditGroup `json:"groups"` // largest LOC first
+}
+
+// AuditGroup is the uncertain files directly inside one directory
+type AuditGroup struct {
+	Dir         string               `json:"dir"` // slash-separated; "." for the root
+	LOC         int                  `json:"loc"`
+	Files       []AuditFile          `json:"files"`
+	Suggestions []OverrideSuggestion `json:"suggestions"`
+}
+
+// AuditFile is a file below the confidence threshold or nearly tied between
+// its top two roles
+type AuditFile struct {
+	Path       string  `json:"path"`
+	LOC        int     `json:"loc"`
+	Role       Role    `json:"role"`
+	SubRole    SubRole `json:"sub_role,omitempty"`
+	Confidence float32 `json:"confidence"`
+	RunnerUp   Role    `json:"runner_up,omitempty"`
+	NearTie    bool    `json:"near_tie,omitempty"` // top two roles within the ambiguity margin
+	Suggested  Role    `json:"suggested"`
+}
+
+// OverrideSuggestion proposes override patterns that assign one role and
+// sub-role
+type OverrideSuggestion struct {
+	Role     Role     `json:"role"`
+	SubRole  SubRole  `json:"sub_role,omitempty"` // kept from the files' current classification
+	Patterns []string `json:"patterns"`
+	Files    int      `json:"files"`
+	LOC      int      `json:"loc"`
+	Basis    string   `json:"basis"` // "directory majority" or "current role"
+}
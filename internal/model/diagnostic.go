@@ -0,0 +1,18 @@
+package model
+
+// DiagnosticKind classifies why a file is missing from, or incomplete in, the report
+type DiagnosticKind string
+
+const (
+	DiagnosticSkipped DiagnosticKind = "skipped" // not counted (e.g., over the size limit)
+	DiagnosticPartial DiagnosticKind = "partial" // counting stopped before end of file
+	DiagnosticError   DiagnosticKind = "error"   // file or directory could not be read
+)
+
+// Diagnostic records a file the scanner could not fully count
+type Diagnostic struct {
+	Path   string         `json:"path"`
+	Kind   DiagnosticKind `json:"kind"`
+	Reason string         `json:"reason"`
+	Bytes  int64          `json:"bytes,omitempty"`
+}
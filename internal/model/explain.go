@@ -0,0 +1,43 @@
+package model
+
+// Explanation traces how the inference engine classified one file
+type Explanation struct {
+	Path     string    `json:"path"`
+	Language string    `json:"language"`
+	Rules    []RuleHit `json:"rules"`             // every rule that fired, in evaluation order
+	Skipped  []string  `json:"skipped,omitempty"` // rule stages not evaluated, and why
+
+	Weights          map[Role]float32 `json:"weights"`             // summed evidence per role (RoleScore.Weights)
+	RunnerUp         Role             `json:"runner_up,omitempty"` // second-ranked role, if any
+	AmbiguityPenalty float32          `json:"ambiguity_penalty"`   // confidence multiplier for a near-tie (1 = none)
+	AgreementFactor  float32          `json:"agreement_factor"`    // 0.25 per signal supporting the top role, max 1
+
+	Override     string              `json:"override,omitempty"` // matching override pattern; other rules are skipped
+	Neighborhood *NeighborhoodEffect `json:"neighborhood,omitempty"`
+
+	Role       Role     `json:"role"`
+	SubRole    SubRole  `json:"sub_role,omitempty"`
+	Confidence float32  `json:"confidence"`
+	Signals    []Signal `json:"signals"`
+}
+
+// RuleHit is a single rule that added evidence for a role
+type RuleHit struct {
+	Signal  Signal  `json:"signal"`
+	Pattern string  `json:"pattern"`
+	Role    Role    `json:"role"`
+	SubRole SubRole `json:"sub_role,omitempty"`
+	Weight  float32 `json:"weight"`
+	Custom  bool    `json:"custom,omitempty"` // from aloc.yaml rules
+}
+
+// NeighborhoodEffect records a reclassification by the confident files
+// around a file, in its own directory, below it and in its parents
+type NeighborhoodEffect struct {
+	Dir            string  `json:"dir"`      // directory whose files contributed most to the new role
+	Distance       int     `json:"distance"` // levels from the file's directory up to Dir
+	FromRole       Role    `json:"from_role"`
+	FromConfidence float32 `json:"from_confidence"`
+	DominantRatio  float32 `json:"dominant_ratio"` // share of the decayed votes for the new role
+	Support        float32 `json:"support"`        // all decayed votes
+}
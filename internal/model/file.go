@@ -1,32 +1,84 @@
 package model
 
+import "time"
+
 // LineMetrics contains detailed line counts
 type LineMetrics struct {
-	Total    int `json:"total"`    // raw line count
-	Blanks   int `json:"blanks"`   // empty lines
-	Comments int `json:"comments"` // comment-only lines
-	Code     int `json:"code"`     // code lines (LOC)
+	Total    int `json:"total"`             // raw line count
+	Blanks   int `json:"blanks"`            // empty lines
+	Comments int `json:"comments"`          // comment-only lines
+	Code     int `json:"code"`              // code lines (LOC)
+	Logical  int `json:"logical,omitempty"` // statements regardless of line wrapping (supported languages only)
+
+	InlineTests int `json:"inline_tests,omitempty"` // code lines of tests embedded in source (Rust #[cfg(test)], doctests, ...)
+	Prose       int `json:"prose,omitempty"`        // prose lines of literate files, counted in Code and attributed to docs
 }
 
 // RawFile is the scanner output before semantic inference
 type RawFile struct {
 	Path         string
 	Bytes        int64
-	LOC          int                    // code lines (for backward compat)
-	Lines        LineMetrics            // detailed line metrics
+	LOC          int         // code lines (for backward compat)
+	Lines        LineMetrics // detailed line metrics
 	LanguageHint string
 	Embedded     map[string]LineMetrics // code blocks embedded in this file (e.g., Markdown)
+	Asset        *AssetInfo             // set for binary assets, which are inventoried instead of counted
+	Header       []byte                 // leading bytes read while counting (scanner HeaderSize); nil if not captured
+	Linguist     *Linguist              // .gitattributes linguist attributes; nil if none
 }
 
 // FileRecord is a file with semantic classification
 type FileRecord struct {
-	Path       string                 `json:"path"`
-	LOC        int                    `json:"loc"`
-	Lines      LineMetrics            `json:"lines,omitempty"`
-	Language   string                 `json:"language"`
-	Role       Role                   `json:"role"`
-	SubRole    TestKind               `json:"sub_role,omitempty"`
-	Confidence float32                `json:"confidence"`
-	Signals    []Signal               `json:"signals"`
-	Embedded   map[string]LineMetrics `json:"embedded,omitempty"` // embedded code blocks by language
+	Path            string                 `json:"path"`
+	LOC             int                    `json:"loc"`
+	Lines           LineMetrics            `json:"lines,omitempty"`
+	Language        string                 `json:"language"`
+	Role            Role                   `json:"role"`
+	SubRole         SubRole                `json:"sub_role,omitempty"`
+	Confidence      float32                `json:"confidence"`
+	Signals         []Signal               `json:"signals"`
+	Embedded        map[string]LineMetrics `json:"embedded,omitempty"` // embedded code blocks by language
+	Bytes           int64                  `json:"bytes,omitempty"`
+	Asset           *AssetInfo             `json:"asset,omitempty"`
+	Split           []RoleLOC              `json:"split,omitempty"`            // LOC attributed to roles other than Role (e.g. inline tests)
+	TestFramework   string                 `json:"test_framework,omitempty"`   // e.g. "Playwright", for test files
+	Upstream        string                 `json:"upstream,omitempty"`         // e.g. "jquery 3.6.0", for vendored files
+	NeighborhoodDir string                 `json:"neighborhood_dir,omitempty"` // directory that decided a neighborhood reclassification
+	Linguist        *Linguist              `json:"linguist,omitempty"`         // .gitattributes linguist attributes
+	Deprecated      bool                   `json:"deprecated,omitempty"`       // header carries a deprecation marker
+	Stale           *StaleInfo             `json:"stale,omitempty"`            // set by git analysis when the file looks abandoned
+	TestedBy        []string               `json:"tested_by,omitempty"`        // test files paired with this core file by name or test_pairs
+	Coverage        *FileCoverage          `json:"coverage,omitempty"`         // from --coverage, when the report measured the file
+}
+
+// FileCoverage is a file's measured test coverage
+type FileCoverage struct {
+	Measured   int     `json:"measured"` // lines the report measured (statements for Go)
+	Covered    int     `json:"covered"`
+	Ratio      float64 `json:"ratio"`
+	CoveredLOC int     `json:"covered_loc"` // LOC × Ratio
+}
+
+// StaleInfo says why a file looks abandoned
+type StaleInfo struct {
+	LastModified time.Time     `json:"last_modified,omitzero"` // last commit touching the file; zero if never committed
+	Reasons      []StaleReason `json:"reasons"`
+}
+
+// RoleLOC is a share of a file's LOC attributed to one role
+type RoleLOC struct {
+	Role    Role    `json:"role"`
+	SubRole SubRole `json:"sub_role,omitempty"`
+	LOC     int     `json:"loc"`
+}
+
+// RoleLOCs returns the file's LOC by role: the Split parts plus the
+// remainder under the file's own role
+func (f *FileRecord) RoleLOCs() []RoleLOC {
+	parts := []RoleLOC{{Role: f.Role, SubRole: f.SubRole, LOC: f.LOC}}
+	for _, p := range f.Split {
+		parts[0].LOC -= p.LOC
+		parts = append(parts, p)
+	}
+	return parts
 }
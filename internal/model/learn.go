@@ -0,0 +1,18 @@
+package model
+
+// RuleWeight is a rule weight fitted by aloc learn
+type RuleWeight struct {
+	Kind    string  `json:"kind"` // path, filename, extension or header
+	Pattern string  `json:"pattern"`
+	Role    Role    `json:"role"`
+	Weight  float32 `json:"weight"`            // 0 disables the rule
+	Default float32 `json:"default,omitempty"` // built-in weight; 0 for a learned directory rule
+}
+
+// LearnSummary describes a fit of rule weights to labeled files
+type LearnSummary struct {
+	Examples int          `json:"examples"` // labeled files found in the scan
+	Before   float32      `json:"before"`   // share classified as labeled with the previous weights
+	After    float32      `json:"after"`    // share classified as labeled with the fitted weights
+	Rules    []RuleWeight `json:"rules"`    // weights that differ from the built-in defaults
+}
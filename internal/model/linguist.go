@@ -0,0 +1,37 @@
+package model
+
+// Linguist holds the GitHub Linguist attributes .gitattributes sets on a
+// file. A nil flag is unspecified; false means the attribute was unset
+// (-linguist-vendored) or set to false.
+type Linguist struct {
+	Generated     *bool  `json:"generated,omitempty"`
+	Vendored      *bool  `json:"vendored,omitempty"`
+	Documentation *bool  `json:"documentation,omitempty"`
+	Detectable    *bool  `json:"detectable,omitempty"`
+	Language      string `json:"language,omitempty"` // linguist-language, as aloc names it
+}
+
+// RoleFlags returns the roles the attributes speak to, each with whether
+// the file has it (true) or GitHub must not give it (false)
+func (l *Linguist) RoleFlags() map[Role]bool {
+	flags := make(map[Role]bool)
+	if l == nil {
+		return flags
+	}
+	for role, flag := range map[Role]*bool{
+		RoleGenerated: l.Generated,
+		RoleVendor:    l.Vendored,
+		RoleDocs:      l.Documentation,
+	} {
+		if flag != nil {
+			flags[role] = *flag
+		}
+	}
+	return flags
+}
+
+// Undetectable reports whether linguist-detectable is unset, keeping the
+// file out of the language breakdown
+func (l *Linguist) Undetectable() bool {
+	return l != nil && l.Detectable != nil && !*l.Detectable
+}
@@ -4,37 +4,143 @@ import "time"
 
 // Report is the complete analysis output
 type Report struct {
-	Meta             Meta              `json:"meta"`
-	Summary          Summary           `json:"summary"`
-	Responsibilities []Responsibility  `json:"responsibilities"`
-	Ratios           Ratios            `json:"ratios"`
-	Languages        []LanguageComp    `json:"languages"`
-	Trend            *Trend            `json:"trend,omitempty"`
-	Confidence       ConfidenceInfo    `json:"confidence"`
-	Effort           *EffortEstimates  `json:"effort,omitempty"`
-	Git              *GitMetrics       `json:"git,omitempty"`
-	GitHint          *GitHint          `json:"git_hint,omitempty"`
-	Engineer         *EngineerMetrics  `json:"engineer,omitempty"`
-	Files            []*FileRecord     `json:"files,omitempty"`
+	Meta             Meta                `json:"meta"`
+	Summary          Summary             `json:"summary"`
+	Responsibilities []Responsibility    `json:"responsibilities"`
+	Ratios           Ratios              `json:"ratios"`
+	Languages        []LanguageComp      `json:"languages"`
+	Trend            *Trend              `json:"trend,omitempty"`
+	Confidence       ConfidenceInfo      `json:"confidence"`
+	Effort           *EffortEstimates    `json:"effort,omitempty"`
+	Git              *GitMetrics         `json:"git,omitempty"`
+	GitHint          *GitHint            `json:"git_hint,omitempty"`
+	Engineer         *EngineerMetrics    `json:"engineer,omitempty"`
+	Assets           *AssetInventory     `json:"assets,omitempty"`
+	TestFrameworks   []TestFrameworkStat `json:"test_frameworks,omitempty"`
+	Vendored         []VendoredStat      `json:"vendored,omitempty"`
+	Abandoned        *AbandonedSurface   `json:"abandoned,omitempty"`
+	Untested         *UntestedReport     `json:"untested,omitempty"`
+	Coverage         *CoverageReport     `json:"coverage,omitempty"`
+	Roles            []RoleInfo          `json:"custom_roles,omitempty"` // user-defined roles, for reading role names and colors
+	Diagnostics      []Diagnostic        `json:"diagnostics,omitempty"`
+	Files            []*FileRecord       `json:"files,omitempty"`
+}
+
+// TestFrameworkStat counts the test files using one framework
+type TestFrameworkStat struct {
+	Name  string `json:"name"`
+	Files int    `json:"files"`
+	LOC   int    `json:"loc"`
+}
+
+// VendoredStat counts the vendored files copied from one upstream project
+type VendoredStat struct {
+	Name  string `json:"name"`
+	Files int    `json:"files"`
+	LOC   int    `json:"loc"`
+}
+
+// CoverageReport is measured test coverage (--coverage) joined with the
+// classification. LOC counts every file of a group, so files the report
+// did not measure count as uncovered. Languages and directories count core
+// files only.
+type CoverageReport struct {
+	Source      string         `json:"source"`
+	Format      string         `json:"format"`              // go, lcov, cobertura or jacoco
+	Files       int            `json:"files"`               // scanned files the report measured
+	Unmatched   int            `json:"unmatched,omitempty"` // report entries that match no scanned file
+	ByRole      []CoverageStat `json:"by_role"`
+	ByLanguage  []CoverageStat `json:"by_language"`
+	ByDirectory []CoverageStat `json:"by_directory"`
+}
+
+// CoverageStat is the covered LOC of a role, language or directory
+type CoverageStat struct {
+	Name          string  `json:"name"`
+	Files         int     `json:"files"`
+	MeasuredFiles int     `json:"measured_files"`
+	LOC           int     `json:"loc"`
+	CoveredLOC    int     `json:"covered_loc"`
+	Coverage      float64 `json:"coverage"` // CoveredLOC / LOC
+}
+
+// UntestedReport is the core code no test file is paired with
+type UntestedReport struct {
+	CoreFiles     int            `json:"core_files"`
+	CoreLOC       int            `json:"core_loc"`
+	UntestedFiles int            `json:"untested_files"`
+	UntestedLOC   int            `json:"untested_loc"`
+	Directories   []UntestedDir  `json:"directories"` // most untested LOC first
+	Largest       []UntestedFile `json:"largest"`
+}
+
+// UntestedDir is the core code of one directory, not counting subdirectories
+type UntestedDir struct {
+	Path          string `json:"path"`
+	CoreFiles     int    `json:"core_files"`
+	CoreLOC       int    `json:"core_loc"`
+	UntestedFiles int    `json:"untested_files"`
+	UntestedLOC   int    `json:"untested_loc"`
+}
+
+// UntestedFile is a core file without a paired test
+type UntestedFile struct {
+	Path string `json:"path"`
+	LOC  int    `json:"loc"`
+}
+
+// StaleReason is why a file is counted as abandoned
+type StaleReason string
+
+const (
+	StaleUntouched      StaleReason = "untouched"       // no commit for StaleMonths
+	StaleInactiveAuthor StaleReason = "inactive_author" // its only author made no commit for InactiveMonths
+	StaleDeprecated     StaleReason = "deprecated"      // carries a deprecation marker
+)
+
+// AbandonedSurface is the code nobody seems to maintain: deletion candidates
+type AbandonedSurface struct {
+	StaleMonths    int                 `json:"stale_months"`
+	InactiveMonths int                 `json:"inactive_months"`
+	Files          int                 `json:"files"`
+	LOC            int                 `json:"loc"`
+	ByRole         []AbandonedRole     `json:"by_role"`
+	ByReason       map[StaleReason]int `json:"by_reason"` // LOC; a file can have several reasons
+	Directories    []StaleDir          `json:"directories,omitempty"`
+}
+
+// AbandonedRole is the abandoned LOC of one role
+type AbandonedRole struct {
+	Role  Role `json:"role"`
+	Files int  `json:"files"`
+	LOC   int  `json:"loc"`
+}
+
+// StaleDir is a directory in which no file changed for StaleMonths
+type StaleDir struct {
+	Path         string    `json:"path"`
+	Files        int       `json:"files"`
+	LOC          int       `json:"loc"`
+	LastModified time.Time `json:"last_modified"`
 }
 
 // GitMetrics contains git-derived codebase dynamics
 type GitMetrics struct {
-	ChurnConcentration     GitChurnStat            `json:"churn_concentration"`
-	StableCore             float64                 `json:"stable_core"`
-	VolatileSurface        float64                 `json:"volatile_surface"`
-	RewritePressure        float64                 `json:"rewrite_pressure"`
-	OwnershipConcentration float64                 `json:"ownership_concentration"`
-	ParallelismSignal      string                  `json:"parallelism_signal"`
-	ChurnSeries            map[Role]GitSparkline   `json:"churn_series,omitempty"`
-	AITimeline             []bool                  `json:"ai_timeline,omitempty"`  // AI-assisted commit markers per bucket
-	HasAnyAI               bool                    `json:"has_any_ai,omitempty"`   // true if any AI-assisted commit in window
-	Adjustments            []GitEffortAdjustment   `json:"adjustments,omitempty"`
-	NetAdjustment          float64                 `json:"net_adjustment"`
-	WindowMonths           int                     `json:"window_months"`
-	BucketCount            int                     `json:"bucket_count"`
-	CommitCount            int                     `json:"commit_count"`
-	AnalysisNote           string                  `json:"analysis_note,omitempty"`
+	ChurnConcentration     GitChurnStat          `json:"churn_concentration"`
+	StableCore             float64               `json:"stable_core"`
+	VolatileSurface        float64               `json:"volatile_surface"`
+	RewritePressure        float64               `json:"rewrite_pressure"`
+	OwnershipConcentration float64               `json:"ownership_concentration"`
+	ParallelismSignal      string                `json:"parallelism_signal"`
+	ChurnSeries            map[Role]GitSparkline `json:"churn_series,omitempty"`
+	AITimeline             []bool                `json:"ai_timeline,omitempty"` // AI-assisted commit markers per bucket
+	HasAnyAI               bool                  `json:"has_any_ai,omitempty"`  // true if any AI-assisted commit in window
+	Adjustments            []GitEffortAdjustment `json:"adjustments,omitempty"`
+	NetAdjustment          float64               `json:"net_adjustment"`
+	WindowMonths           int                   `json:"window_months"`
+	BucketCount            int                   `json:"bucket_count"`
+	CommitCount            int                   `json:"commit_count"`
+	AnalysisNote           string                `json:"analysis_note,omitempty"`
 }
 
 // GitChurnStat represents churn concentration metrics
@@ -110,12 +216,12 @@ type Summary struct {
 
 // Responsibility contains LOC breakdown by role
 type Responsibility struct {
-	Role       Role                 `json:"role"`
-	LOC        int                  `json:"loc"`
-	Files      int                  `json:"files"`
-	Confidence float32              `json:"confidence"`
-	Breakdown  map[TestKind]float32 `json:"breakdown,omitempty"`
-	Notes      []string             `json:"notes,omitempty"`
+	Role       Role                `json:"role"`
+	LOC        int                 `json:"loc"`
+	Files      int                 `json:"files"`
+	Confidence float32             `json:"confidence"`
+	Breakdown  map[SubRole]float32 `json:"breakdown,omitempty"`
+	Notes      []string            `json:"notes,omitempty"`
 }
 
 // Ratios contains pre-calculated key ratios
@@ -125,21 +231,33 @@ type Ratios struct {
 	DocsToCore      float32 `json:"docs_to_core"`
 	GeneratedToCore float32 `json:"generated_to_core"`
 	ConfigToCore    float32 `json:"config_to_core"`
+
+	CoveredCoreToCore *float32 `json:"covered_core_to_core,omitempty"` // with --coverage
+
+	Roles []RoleRatio `json:"roles,omitempty"` // custom roles with ratio_to_core
+}
+
+// RoleRatio is a custom role's LOC relative to core, with its healthy range
+type RoleRatio struct {
+	Role   Role        `json:"role"`
+	Name   string      `json:"name"`
+	ToCore float32     `json:"to_core"`
+	Target RatioTarget `json:"target"`
 }
 
 // LanguageComp contains language composition data
 type LanguageComp struct {
-	Language         string                  `json:"language"`
-	Category         string                  `json:"category,omitempty"`
-	LOCTotal         int                     `json:"loc_total"`
-	Files            int                     `json:"files"`
-	Code             int                     `json:"code"`
-	Comments         int                     `json:"comments"`
-	Blanks           int                     `json:"blanks"`
-	Tests            int                     `json:"tests"`
-	Config           int                     `json:"config"`
-	Responsibilities map[Role]int            `json:"responsibilities"`
-	Embedded         map[string]LineMetrics  `json:"embedded,omitempty"` // for container languages (Markdown, etc.)
+	Language         string                 `json:"language"`
+	Category         string                 `json:"category,omitempty"`
+	LOCTotal         int                    `json:"loc_total"`
+	Files            int                    `json:"files"`
+	Code             int                    `json:"code"`
+	Comments         int                    `json:"comments"`
+	Blanks           int                    `json:"blanks"`
+	Tests            int                    `json:"tests"`
+	Config           int                    `json:"config"`
+	Responsibilities map[Role]int           `json:"responsibilities"`
+	Embedded         map[string]LineMetrics `json:"embedded,omitempty"` // for container languages (Markdown, etc.)
 }
 
 // Trend contains historical trend data
@@ -162,8 +280,8 @@ type EffortEstimates struct {
 	Human           *HumanEffort            `json:"human,omitempty"`
 	AI              *AIEffort               `json:"ai,omitempty"`
 	Comparison      *CostComparison         `json:"comparison,omitempty"`
-	Conventional    *TeamEstimate           `json:"conventional,omitempty"`    // Market Replacement (Conventional Team)
-	Agentic         *TeamEstimate           `json:"agentic,omitempty"`         // AI-Native Team (Agentic/Parallel)
+	Conventional    *TeamEstimate           `json:"conventional,omitempty"` // Market Replacement (Conventional Team)
+	Agentic         *TeamEstimate           `json:"agentic,omitempty"`      // AI-Native Team (Agentic/Parallel)
 	HybridBreakdown []HybridSavings         `json:"hybrid_breakdown,omitempty"`
 	QuickActions    []QuickAction           `json:"quick_actions,omitempty"`
 	EliteReference  *EliteOperatorReference `json:"elite_reference,omitempty"`
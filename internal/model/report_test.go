@@ -210,7 +210,7 @@ func TestResponsibilityWithBreakdown(t *testing.T) {
 		LOC:        10000,
 		Files:      50,
 		Confidence: 0.9,
-		Breakdown: map[TestKind]float32{
+		Breakdown: map[SubRole]float32{
 			TestUnit:        0.6,
 			TestIntegration: 0.3,
 			TestE2E:         0.1,
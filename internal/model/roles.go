@@ -0,0 +1,151 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// RoleInfo describes how a role is displayed, ranked and counted
+type RoleInfo struct {
+	Role     Role          `json:"role"`
+	Name     string        `json:"name"`                    // display name
+	Color    SemanticColor `json:"color"`                   // semantic color token
+	Priority int           `json:"priority"`                // tie-break when roles score equally; lower wins
+	Ratio    *RatioTarget  `json:"ratio_to_core,omitempty"` // report LOC relative to core in the health ratios
+	Effort   bool          `json:"effort"`                  // LOC counts toward effort estimates
+	Custom   bool          `json:"custom,omitempty"`        // defined in the config rather than built in
+	SubRoles []SubRole     `json:"sub_roles,omitempty"`     // finer kinds a file of this role can have
+}
+
+// RatioTarget is the healthy range of a role's LOC relative to core
+type RatioTarget struct {
+	Min float32 `json:"min"`
+	Max float32 `json:"max"`
+}
+
+// DefaultCustomPriority ranks custom roles after the built-ins on ties
+const DefaultCustomPriority = 50
+
+// roleInfo holds the built-in roles and any registered with RegisterRole
+var roleInfo = map[Role]RoleInfo{
+	RoleVendor:    {Role: RoleVendor, Color: ColorExternal, Priority: 1, Effort: true},
+	RoleGenerated: {Role: RoleGenerated, Color: ColorLowEmphasis, Priority: 2, Effort: true},
+	RoleTest:      {Role: RoleTest, Color: ColorSafety, Priority: 3, Effort: true, SubRoles: AllTestKinds},
+	RoleInfra: {Role: RoleInfra, Color: ColorOperational, Priority: 4, Effort: true, SubRoles: []SubRole{
+		InfraKubernetes, InfraHelm, InfraCloudFormation, InfraAnsible, InfraGitHubActions, InfraCompose,
+	}},
+	RoleCore:       {Role: RoleCore, Color: ColorPrimary, Priority: 5, Effort: true},
+	RoleDocs:       {Role: RoleDocs, Color: ColorKnowledge, Priority: 6, Effort: true},
+	RoleConfig:     {Role: RoleConfig, Color: ColorFragility, Priority: 7, Effort: true},
+	RoleScripts:    {Role: RoleScripts, Color: ColorPrimary, Priority: 8, Effort: true},
+	RoleExamples:   {Role: RoleExamples, Color: ColorKnowledge, Priority: 9, Effort: true},
+	RoleDeprecated: {Role: RoleDeprecated, Color: ColorWarning, Priority: 10, Effort: true},
+	RoleMigrations: {Role: RoleMigrations, Color: ColorOperational, Priority: 11, Effort: true, SubRoles: []SubRole{
+		MigrationSQL, MigrationFlyway, MigrationLiquibase, MigrationAlembic, MigrationDjango, MigrationRails, MigrationPrisma,
+	}},
+	RoleL10n: {Role: RoleL10n, Color: ColorKnowledge, Priority: 12, Effort: true, SubRoles: []SubRole{
+		L10nGettext, L10nXLIFF, L10nApple, L10nAndroid, L10nARB, L10nBundle,
+	}},
+	RoleSchemas: {Role: RoleSchemas, Color: ColorFragility, Priority: 13, Effort: true, SubRoles: []SubRole{
+		SchemaOpenAPI, SchemaJSON, SchemaGraphQL, SchemaAvro,
+	}},
+}
+
+// SemanticColors are the color tokens a role can take
+var SemanticColors = []SemanticColor{
+	ColorPrimary,
+	ColorSafety,
+	ColorOperational,
+	ColorKnowledge,
+	ColorFragility,
+	ColorLowEmphasis,
+	ColorExternal,
+	ColorWarning,
+}
+
+// ParseSemanticColor accepts a color token with or without its "semantic."
+// prefix, e.g. "knowledge" or "semantic.low_emphasis"
+func ParseSemanticColor(s string) (SemanticColor, bool) {
+	c := SemanticColor("semantic." + strings.TrimPrefix(strings.ToLower(s), "semantic."))
+	return c, slices.Contains(SemanticColors, c)
+}
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// RegisterRole adds a user-defined role, or redefines one added before, and
+// appends it to AllRoles. Unset fields default to the role's own name, the
+// primary color and DefaultCustomPriority. Built-in roles cannot be
+// redefined. It must be called before classification starts.
+func RegisterRole(info RoleInfo) error {
+	role := info.Role
+	if !roleNamePattern.MatchString(string(role)) {
+		return fmt.Errorf("role %q: names are lower case letters, digits, - and _", role)
+	}
+	if existing, ok := roleInfo[role]; ok && !existing.Custom {
+		return fmt.Errorf("role %q: built-in roles cannot be redefined", role)
+	}
+	if info.Color == "" {
+		info.Color = ColorPrimary
+	}
+	if !slices.Contains(SemanticColors, info.Color) {
+		return fmt.Errorf("role %q: unknown color %q", role, info.Color)
+	}
+	if info.Priority == 0 {
+		info.Priority = DefaultCustomPriority
+	}
+	if r := info.Ratio; r != nil && (r.Min < 0 || r.Max < r.Min) {
+		return fmt.Errorf("role %q: ratio_to_core needs 0 <= min <= max, got %v-%v", role, r.Min, r.Max)
+	}
+	info.Custom = true
+	info.SubRoles = nil
+
+	if !slices.Contains(AllRoles, role) {
+		AllRoles = append(AllRoles, role)
+	}
+	roleInfo[role] = info
+	return nil
+}
+
+// Info returns how the role is displayed, ranked and counted. Unknown roles
+// rank last, in the primary color, and count toward effort.
+func (r Role) Info() RoleInfo {
+	info, ok := roleInfo[r]
+	if !ok {
+		info = RoleInfo{Role: r, Color: ColorPrimary, Priority: 100, Effort: true}
+	}
+	if info.Name == "" {
+		info.Name = string(r)
+	}
+	return info
+}
+
+// DisplayName returns the name the role is shown under
+func (r Role) DisplayName() string {
+	return r.Info().Name
+}
+
+// CustomRoles returns the registered user-defined roles in AllRoles order
+func CustomRoles() []RoleInfo {
+	var roles []RoleInfo
+	for _, r := range AllRoles {
+		if info := r.Info(); info.Custom {
+			roles = append(roles, info)
+		}
+	}
+	return roles
+}
+
+// ResetRoles removes the roles added with RegisterRole
+func ResetRoles() {
+	for role, info := range roleInfo {
+		if info.Custom {
+			delete(roleInfo, role)
+		}
+	}
+	AllRoles = slices.DeleteFunc(AllRoles, func(r Role) bool {
+		_, ok := roleInfo[r]
+		return !ok
+	})
+}
@@ -14,9 +14,13 @@ const (
 	RoleScripts    Role = "scripts"
 	RoleExamples   Role = "examples"
 	RoleDeprecated Role = "deprecated"
+	RoleMigrations Role = "migrations"
+	RoleL10n       Role = "l10n"
+	RoleSchemas    Role = "schemas"
 )
 
-// AllRoles contains all possible roles for iteration
+// AllRoles contains all possible roles for iteration: the built-ins, then
+// any added with RegisterRole
 var AllRoles = []Role{
 	RoleCore,
 	RoleTest,
@@ -28,10 +32,18 @@ var AllRoles = []Role{
 	RoleScripts,
 	RoleExamples,
 	RoleDeprecated,
+	RoleMigrations,
+	RoleL10n,
+	RoleSchemas,
 }
 
-// TestKind represents the type of test
-type TestKind string
+// SubRole refines a role: the kind of a test, or the tool or format of an
+// infra, migration, localization or schema file. Role.Info().SubRoles lists
+// the sub-roles each role accepts.
+type SubRole string
+
+// TestKind is a sub-role of the test role
+type TestKind = SubRole
 
 const (
 	TestUnit        TestKind = "unit"
@@ -39,6 +51,9 @@ const (
 	TestE2E         TestKind = "e2e"
 	TestContract    TestKind = "contract"
 	TestFixture     TestKind = "fixture"
+	TestBenchmark   TestKind = "benchmark"
+	TestFuzz        TestKind = "fuzz"
+	TestExample     TestKind = "example"
 )
 
 // AllTestKinds contains all possible test kinds
@@ -48,8 +63,41 @@ var AllTestKinds = []TestKind{
 	TestE2E,
 	TestContract,
 	TestFixture,
+	TestBenchmark,
+	TestFuzz,
+	TestExample,
 }
 
+// Sub-roles of the infra, migrations, l10n and schemas roles
+const (
+	InfraKubernetes     SubRole = "kubernetes"
+	InfraHelm           SubRole = "helm"
+	InfraCloudFormation SubRole = "cloudformation"
+	InfraAnsible        SubRole = "ansible"
+	InfraGitHubActions  SubRole = "github-actions"
+	InfraCompose        SubRole = "compose"
+
+	MigrationSQL       SubRole = "sql"
+	MigrationFlyway    SubRole = "flyway"
+	MigrationLiquibase SubRole = "liquibase"
+	MigrationAlembic   SubRole = "alembic"
+	MigrationDjango    SubRole = "django"
+	MigrationRails     SubRole = "rails"
+	MigrationPrisma    SubRole = "prisma"
+
+	L10nGettext SubRole = "gettext"
+	L10nXLIFF   SubRole = "xliff"
+	L10nApple   SubRole = "apple"
+	L10nAndroid SubRole = "android"
+	L10nARB     SubRole = "arb"
+	L10nBundle  SubRole = "bundle" // key-value files under locales/, i18n/ and the like
+
+	SchemaOpenAPI SubRole = "openapi"
+	SchemaJSON    SubRole = "json-schema"
+	SchemaGraphQL SubRole = "graphql"
+	SchemaAvro    SubRole = "avro"
+)
+
 // Signal represents the source of classification evidence
 type Signal string
 
@@ -60,6 +108,8 @@ const (
 	SignalNeighborhood Signal = "neighborhood"
 	SignalHeader       Signal = "header"
 	SignalOverride     Signal = "override"
+	SignalSyntax       Signal = "syntax"     // parsed source, e.g. Go build constraints
+	SignalAttributes   Signal = "attributes" // .gitattributes linguist attributes
 )
 
 // AllSignals contains all possible signals
@@ -70,6 +120,8 @@ var AllSignals = []Signal{
 	SignalNeighborhood,
 	SignalHeader,
 	SignalOverride,
+	SignalSyntax,
+	SignalAttributes,
 }
 
 // SemanticColor represents a semantic color token for rendering
@@ -88,30 +140,7 @@ const (
 
 // Color returns the semantic color for a role
 func (r Role) Color() SemanticColor {
-	switch r {
-	case RoleCore:
-		return ColorPrimary
-	case RoleTest:
-		return ColorSafety
-	case RoleInfra:
-		return ColorOperational
-	case RoleDocs:
-		return ColorKnowledge
-	case RoleConfig:
-		return ColorFragility
-	case RoleGenerated:
-		return ColorLowEmphasis
-	case RoleVendor:
-		return ColorExternal
-	case RoleScripts:
-		return ColorPrimary
-	case RoleExamples:
-		return ColorKnowledge
-	case RoleDeprecated:
-		return ColorWarning
-	default:
-		return ColorPrimary
-	}
+	return r.Info().Color
 }
 
 // String returns the string representation of a role
@@ -119,8 +148,8 @@ func (r Role) String() string {
 	return string(r)
 }
 
-// String returns the string representation of a test kind
-func (t TestKind) String() string {
+// String returns the string representation of a sub-role
+func (t SubRole) String() string {
 	return string(t)
 }
 
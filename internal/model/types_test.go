@@ -2,6 +2,7 @@ package model
 
 import (
 	"encoding/json"
+	"slices"
 	"testing"
 )
 
@@ -83,20 +84,43 @@ func TestRoleJSONSerialization(t *testing.T) {
 }
 
 func TestAllRolesComplete(t *testing.T) {
-	if len(AllRoles) != 10 {
-		t.Errorf("AllRoles has %d roles, want 10", len(AllRoles))
+	if len(AllRoles) != 13 {
+		t.Errorf("AllRoles has %d roles, want 13", len(AllRoles))
+	}
+}
+
+func TestRoleSubRoles(t *testing.T) {
+	for _, role := range AllRoles {
+		seen := make(map[TestKind]bool)
+		for _, kind := range role.Info().SubRoles {
+			if seen[kind] {
+				t.Errorf("%s: duplicate sub-role %q", role, kind)
+			}
+			seen[kind] = true
+		}
+	}
+	if !slices.Equal(RoleTest.Info().SubRoles, AllTestKinds) {
+		t.Errorf("test sub-roles = %v, want AllTestKinds", RoleTest.Info().SubRoles)
+	}
+	for _, role := range []Role{RoleMigrations, RoleL10n, RoleSchemas} {
+		if len(role.Info().SubRoles) == 0 {
+			t.Errorf("%s has no sub-roles", role)
+		}
+	}
+	if len(RoleCore.Info().SubRoles) != 0 {
+		t.Errorf("core sub-roles = %v, want none", RoleCore.Info().SubRoles)
 	}
 }
 
 func TestAllSignalsComplete(t *testing.T) {
-	if len(AllSignals) != 6 {
-		t.Errorf("AllSignals has %d signals, want 6", len(AllSignals))
+	if len(AllSignals) != 8 {
+		t.Errorf("AllSignals has %d signals, want 8", len(AllSignals))
 	}
 }
 
 func TestAllTestKindsComplete(t *testing.T) {
-	if len(AllTestKinds) != 5 {
-		t.Errorf("AllTestKinds has %d kinds, want 5", len(AllTestKinds))
+	if len(AllTestKinds) != 8 {
+		t.Errorf("AllTestKinds has %d kinds, want 8", len(AllTestKinds))
 	}
 }
 
@@ -110,6 +134,9 @@ func TestTestKindString(t *testing.T) {
 		{TestE2E, "e2e"},
 		{TestContract, "contract"},
 		{TestFixture, "fixture"},
+		{TestBenchmark, "benchmark"},
+		{TestFuzz, "fuzz"},
+		{TestExample, "example"},
 	}
 
 	for _, tt := range tests {
@@ -130,6 +157,8 @@ func TestSignalString(t *testing.T) {
 		{SignalNeighborhood, "neighborhood"},
 		{SignalHeader, "header"},
 		{SignalOverride, "override"},
+		{SignalSyntax, "syntax"},
+		{SignalAttributes, "attributes"},
 	}
 
 	for _, tt := range tests {
@@ -167,3 +196,57 @@ func TestSemanticColorValues(t *testing.T) {
 		seen[c] = true
 	}
 }
+
+func TestRegisterRole(t *testing.T) {
+	defer ResetRoles()
+
+	if err := RegisterRole(RoleInfo{Role: "seeds", Name: "Seeds", Color: ColorOperational, Effort: true}); err != nil {
+		t.Fatal(err)
+	}
+	info := Role("seeds").Info()
+	if info.Name != "Seeds" || info.Color != ColorOperational || info.Priority != DefaultCustomPriority || !info.Custom {
+		t.Errorf("Info() = %+v", info)
+	}
+	if AllRoles[len(AllRoles)-1] != "seeds" {
+		t.Errorf("AllRoles = %v, want seeds appended", AllRoles)
+	}
+	// redefining a custom role replaces it without listing it twice
+	if err := RegisterRole(RoleInfo{Role: "seeds", Priority: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(AllRoles); n != 14 || Role("seeds").Info().Priority != 3 {
+		t.Errorf("after redefining: %d roles, priority %d", n, Role("seeds").Info().Priority)
+	}
+
+	for _, bad := range []RoleInfo{
+		{Role: RoleTest},
+		{Role: RoleMigrations},
+		{Role: "Schemas"},
+		{Role: "benchmarks", Color: "semantic.neon"},
+		{Role: "fixtures", Ratio: &RatioTarget{Min: 0.5, Max: 0.1}},
+	} {
+		if err := RegisterRole(bad); err == nil {
+			t.Errorf("RegisterRole(%+v) error = nil", bad)
+		}
+	}
+
+	ResetRoles()
+	if len(AllRoles) != 13 || Role("seeds").Info().Custom {
+		t.Errorf("ResetRoles left %v", AllRoles)
+	}
+}
+
+func TestParseSemanticColor(t *testing.T) {
+	for in, want := range map[string]SemanticColor{
+		"knowledge":         ColorKnowledge,
+		"Low_Emphasis":      ColorLowEmphasis,
+		"semantic.external": ColorExternal,
+	} {
+		if got, ok := ParseSemanticColor(in); !ok || got != want {
+			t.Errorf("ParseSemanticColor(%q) = %v, %v", in, got, ok)
+		}
+	}
+	if _, ok := ParseSemanticColor("neon"); ok {
+		t.Error(`ParseSemanticColor("neon") ok = true`)
+	}
+}
@@ -233,27 +233,25 @@ func NewNoColorTheme() *Theme {
 	}
 }
 
-// ForRole returns the style for a semantic role
+// ForRole returns the style for a semantic role, by its color
 func (t *Theme) ForRole(role model.Role) lipgloss.Style {
-	switch role {
-	case model.RoleCore:
+	switch role.Color() {
+	case model.ColorPrimary:
 		return t.Core
-	case model.RoleTest:
-		return t.Test
-	case model.RoleInfra:
-		return t.Infra
-	case model.RoleDocs, model.RoleExamples:
-		return t.Docs
-	case model.RoleConfig:
-		return t.Config
-	case model.RoleGenerated:
-		return t.Generated
-	case model.RoleVendor:
-		return t.Vendor
-	case model.RoleDeprecated:
+	case model.ColorSafety:
+		return t.Safety
+	case model.ColorOperational:
+		return t.Operational
+	case model.ColorKnowledge:
+		return t.Knowledge
+	case model.ColorFragility:
+		return t.Fragility
+	case model.ColorLowEmphasis:
+		return t.LowEmphasis
+	case model.ColorExternal:
+		return t.External
+	case model.ColorWarning:
 		return t.Deprecated
-	case model.RoleScripts:
-		return t.Core
 	default:
 		return t.Primary
 	}
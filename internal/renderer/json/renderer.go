@@ -27,3 +27,21 @@ func (r *JSONRenderer) Render(report *model.Report) error {
 	}
 	return enc.Encode(report)
 }
+
+// RenderExplanations writes classification traces produced by aloc explain
+func (r *JSONRenderer) RenderExplanations(explanations []*model.Explanation) error {
+	enc := json.NewEncoder(r.writer)
+	if r.pretty {
+		enc.SetIndent("", "  ")
+	}
+	return enc.Encode(explanations)
+}
+
+// RenderAudit writes the uncertain classifications found by aloc audit
+func (r *JSONRenderer) RenderAudit(audit *model.Audit) error {
+	enc := json.NewEncoder(r.writer)
+	if r.pretty {
+		enc.SetIndent("", "  ")
+	}
+	return enc.Encode(audit)
+}
@@ -0,0 +1,146 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/modern-tooling/aloc/internal/model"
+	"github.com/modern-tooling/aloc/internal/renderer"
+)
+
+// maxStaleDirRows caps the stale directory list in the TUI (JSON has up to 10)
+const maxStaleDirRows = 5
+
+// staleReasonLabels are the short forms of the stale reasons, in display order
+var staleReasonLabels = []struct {
+	reason model.StaleReason
+	label  string
+}{
+	{model.StaleUntouched, "untouched"},
+	{model.StaleInactiveAuthor, "sole author inactive"},
+	{model.StaleDeprecated, "deprecated"},
+}
+
+// RenderAbandoned renders the abandoned surface: LOC by role, by reason and
+// the largest stale directories. With --files the stale files are listed as
+// deletion candidates.
+func RenderAbandoned(surface *model.AbandonedSurface, files []*model.FileRecord, totalLOC int, theme *renderer.Theme) string {
+	if surface == nil {
+		return ""
+	}
+
+	var b strings.Builder
+
+	b.WriteString(theme.PrimaryBold.Render("Abandoned Surface") + theme.Dim.Render(" (git)") + "\n")
+	b.WriteString(theme.Dim.Render(strings.Repeat("─", 80)) + "\n")
+
+	if surface.Files == 0 {
+		b.WriteString(theme.Dim.Render(fmt.Sprintf("No files untouched for %d months, left by an author inactive for %d months, or marked deprecated",
+			surface.StaleMonths, surface.InactiveMonths)) + "\n")
+		return b.String()
+	}
+
+	pct := 0.0
+	if totalLOC > 0 {
+		pct = float64(surface.LOC) / float64(totalLOC) * 100
+	}
+	fmt.Fprintf(&b, "%s LOC %s in %s\n",
+		theme.Accent.Render(formatNumber(surface.LOC)),
+		theme.Dim.Render(fmt.Sprintf("(%.1f%%)", pct)),
+		pluralFiles(surface.Files))
+
+	// by role table
+	var rows []tableRow
+	for _, r := range surface.ByRole {
+		role := r.Role
+		rows = append(rows, tableRow{
+			cells: []tableCell{
+				{text: "  " + role.DisplayName(), style: func(text string, theme *renderer.Theme) string {
+					return theme.ForRole(role).Render(text)
+				}},
+				{text: formatNumber(r.LOC)},
+				{text: pluralFiles(r.Files), style: styleDim},
+			},
+		})
+	}
+	renderAlignedTable(&b, rows, tableSpec{
+		alignments: []alignColumn{alignLeft, alignRight, alignRight},
+		colWidths:  computeColumnWidths(rows, 3),
+	}, theme)
+
+	// by reason, inline
+	var parts []string
+	for _, l := range staleReasonLabels {
+		if loc := surface.ByReason[l.reason]; loc > 0 {
+			parts = append(parts, fmt.Sprintf("%s %s", l.label, formatNumber(loc)))
+		}
+	}
+	b.WriteString(theme.Dim.Render(fmt.Sprintf("By reason (untouched %d+ months, author inactive %d+ months): ",
+		surface.StaleMonths, surface.InactiveMonths)) + strings.Join(parts, theme.Dim.Render(" · ")) + "\n")
+
+	// largest stale directories
+	if len(surface.Directories) > 0 {
+		b.WriteString(theme.Dim.Render("Stale directories") + "\n")
+		shown := surface.Directories
+		if len(shown) > maxStaleDirRows {
+			shown = shown[:maxStaleDirRows]
+		}
+		var dirRows []tableRow
+		for _, d := range shown {
+			dirRows = append(dirRows, tableRow{
+				cells: []tableCell{
+					{text: "  " + truncate(d.Path+"/", 50)},
+					{text: formatNumber(d.LOC) + " LOC"},
+					{text: pluralFiles(d.Files), style: styleDim},
+					{text: "last change " + d.LastModified.Format("Jan 2006"), style: styleDim},
+				},
+			})
+		}
+		renderAlignedTable(&b, dirRows, tableSpec{
+			alignments: []alignColumn{alignLeft, alignRight, alignRight, alignLeft},
+			colWidths:  computeColumnWidths(dirRows, 4),
+		}, theme)
+	}
+
+	// deletion candidates, only with --files
+	var candidates []tableRow
+	for _, f := range files {
+		if f.Stale == nil {
+			continue
+		}
+		lastChange := "never committed"
+		if !f.Stale.LastModified.IsZero() {
+			lastChange = f.Stale.LastModified.Format("Jan 2006")
+		}
+		candidates = append(candidates, tableRow{
+			cells: []tableCell{
+				{text: "  " + truncate(f.Path, 50)},
+				{text: formatNumber(f.LOC)},
+				{text: lastChange, style: styleDim},
+				{text: staleReasonText(f.Stale.Reasons), style: styleDim},
+			},
+		})
+	}
+	if len(candidates) > 0 {
+		b.WriteString(theme.Dim.Render("Deletion candidates") + "\n")
+		renderAlignedTable(&b, candidates, tableSpec{
+			alignments: []alignColumn{alignLeft, alignRight, alignLeft, alignLeft},
+			colWidths:  computeColumnWidths(candidates, 4),
+		}, theme)
+	}
+
+	return b.String()
+}
+
+// staleReasonText joins the short forms of a file's stale reasons
+func staleReasonText(reasons []model.StaleReason) string {
+	var labels []string
+	for _, l := range staleReasonLabels {
+		for _, r := range reasons {
+			if r == l.reason {
+				labels = append(labels, l.label)
+			}
+		}
+	}
+	return strings.Join(labels, ", ")
+}
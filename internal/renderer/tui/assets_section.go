@@ -0,0 +1,85 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/modern-tooling/aloc/internal/model"
+	"github.com/modern-tooling/aloc/internal/renderer"
+)
+
+// maxAssetRows caps the largest-assets list in the TUI (JSON has the full top list)
+const maxAssetRows = 5
+
+// RenderAssets renders the binary asset inventory: totals, bytes by type and
+// role, and the largest files. LFS pointers are sized by the object they
+// stand in for and marked "lfs".
+func RenderAssets(assets *model.AssetInventory, theme *renderer.Theme) string {
+	if assets == nil || assets.Files == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+
+	b.WriteString(theme.PrimaryBold.Render("Assets") +
+		theme.Dim.Render(fmt.Sprintf(" (%s · %s", pluralFiles(assets.Files), formatBytes(assets.Bytes))))
+	if assets.LFSPointers > 0 {
+		b.WriteString(theme.Dim.Render(fmt.Sprintf(" · %s in Git LFS, %s of pointers",
+			formatBytes(assets.LFSBytes), formatBytes(assets.LFSPointerBytes))))
+	}
+	b.WriteString(theme.Dim.Render(")") + "\n")
+	b.WriteString(theme.Dim.Render(strings.Repeat("─", 80)) + "\n")
+
+	// by type table
+	var rows []tableRow
+	for _, g := range assets.ByType {
+		rows = append(rows, tableRow{
+			cells: []tableCell{
+				{text: "  " + g.Name},
+				{text: pluralFiles(g.Files), style: styleDim},
+				{text: formatBytes(g.Bytes)},
+			},
+		})
+	}
+	renderAlignedTable(&b, rows, tableSpec{
+		alignments: []alignColumn{alignLeft, alignRight, alignRight},
+		colWidths:  computeColumnWidths(rows, 3),
+	}, theme)
+
+	// by role, inline
+	var parts []string
+	for _, g := range assets.ByRole {
+		parts = append(parts, theme.ForRole(model.Role(g.Name)).Render(fmt.Sprintf("%s %s", g.Name, formatBytes(g.Bytes))))
+	}
+	b.WriteString(theme.Dim.Render("By role: ") + strings.Join(parts, theme.Dim.Render(" · ")) + "\n")
+
+	// largest files
+	if len(assets.Largest) > 0 {
+		b.WriteString(theme.Dim.Render("Largest") + "\n")
+		shown := assets.Largest
+		if len(shown) > maxAssetRows {
+			shown = shown[:maxAssetRows]
+		}
+		sizeWidth := 0
+		for _, f := range shown {
+			sizeWidth = max(sizeWidth, len(formatBytes(f.Bytes)))
+		}
+		for _, f := range shown {
+			fmt.Fprintf(&b, "  %*s  %s", sizeWidth, formatBytes(f.Bytes), truncate(f.Path, 60))
+			if f.LFS {
+				b.WriteString(theme.Dim.Render(" (lfs)"))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
+
+// pluralFiles formats a file count with the right noun
+func pluralFiles(n int) string {
+	if n == 1 {
+		return "1 file"
+	}
+	return formatNumber(n) + " files"
+}
@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/modern-tooling/aloc/internal/model"
+	"github.com/modern-tooling/aloc/internal/renderer"
+)
+
+// RenderAuditSummary renders the header of aloc audit: how many files and
+// how much LOC fall below the confidence threshold or are nearly tied
+func RenderAuditSummary(audit *model.Audit, theme *renderer.Theme) string {
+	var b strings.Builder
+
+	b.WriteString(theme.PrimaryBold.Render("Classification Audit") + "\n")
+	b.WriteString(theme.Dim.Render(strings.Repeat("─", 80)) + "\n")
+
+	if audit.Files == 0 {
+		b.WriteString(fmt.Sprintf("All files are at or above %.2f confidence with a clear top role.\n", audit.Threshold))
+		return b.String()
+	}
+	share := 0.0
+	if audit.TotalLOC > 0 {
+		share = float64(audit.LOC) / float64(audit.TotalLOC) * 100
+	}
+	b.WriteString(fmt.Sprintf("%s (%s LOC, %.0f%% of total) below %.2f confidence or nearly tied, in %d %s\n",
+		pluralFiles(audit.Files), formatNumber(audit.LOC), share, audit.Threshold,
+		len(audit.Groups), pluralize(len(audit.Groups), "directory", "directories")))
+	return b.String()
+}
+
+// RenderAuditGroup renders the uncertain files of one directory with their
+// current and suggested roles
+func RenderAuditGroup(group model.AuditGroup, theme *renderer.Theme) string {
+	var b strings.Builder
+
+	b.WriteString(theme.PrimaryBold.Render(group.Dir+"/") +
+		theme.Dim.Render(fmt.Sprintf(" %s · %s LOC", pluralFiles(len(group.Files)), formatNumber(group.LOC))) + "\n")
+
+	var rows []tableRow
+	for _, f := range group.Files {
+		role := string(f.Role)
+		if f.SubRole != "" {
+			role += "/" + string(f.SubRole)
+		}
+		why := fmt.Sprintf("%.2f", f.Confidence)
+		if f.NearTie {
+			why += " tie with " + string(f.RunnerUp)
+		}
+		suggested := "keep"
+		if f.Suggested != f.Role {
+			suggested = "→ " + string(f.Suggested)
+		}
+		rows = append(rows, tableRow{
+			cells: []tableCell{
+				{text: "  " + truncate(strings.TrimPrefix(f.Path, group.Dir+"/"), 40)},
+				{text: formatNumber(f.LOC), style: styleDim},
+				{text: role, style: styleRole(f.Role)},
+				{text: why, style: styleDim},
+				{text: suggested, style: styleRole(f.Suggested)},
+			},
+		})
+	}
+	renderAlignedTable(&b, rows, tableSpec{
+		alignments: []alignColumn{alignLeft, alignRight, alignLeft, alignLeft, alignLeft},
+		colWidths:  computeColumnWidths(rows, 5),
+	}, theme)
+	return b.String()
+}
+
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
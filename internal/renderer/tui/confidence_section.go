@@ -23,8 +23,8 @@ func RenderConfidenceLine(confidence model.ConfidenceInfo, theme *renderer.Theme
 	return fmt.Sprintf("Classification confidence: %s\n", level)
 }
 
-// RenderConfidenceSection renders detailed classification confidence (for --explain mode)
+// RenderConfidenceSection renders classification confidence. Per-file detail
+// is available from `aloc explain <path>`.
 func RenderConfidenceSection(confidence model.ConfidenceInfo, effort *model.EffortEstimates, theme *renderer.Theme) string {
-	// Detailed version moved to --explain mode
 	return RenderConfidenceLine(confidence, theme)
 }
@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/modern-tooling/aloc/internal/model"
+	"github.com/modern-tooling/aloc/internal/renderer"
+)
+
+// TUI caps for the coverage breakdown (JSON lists every group)
+const (
+	maxCoverageLanguageRows = 5
+	maxCoverageDirRows      = 8
+)
+
+// RenderCoverage renders measured coverage by role, and for core code by
+// language and directory: covered LOC, LOC and the covered share
+func RenderCoverage(report *model.CoverageReport, theme *renderer.Theme) string {
+	if report == nil {
+		return ""
+	}
+
+	var b strings.Builder
+
+	b.WriteString(theme.PrimaryBold.Render("Coverage") +
+		theme.Dim.Render(fmt.Sprintf(" (%s · %s · %s measured", report.Format, filepath.Base(report.Source), pluralFiles(report.Files))))
+	if report.Unmatched > 0 {
+		b.WriteString(theme.Dim.Render(fmt.Sprintf(" · %d report entries not found", report.Unmatched)))
+	}
+	b.WriteString(theme.Dim.Render(")") + "\n")
+	b.WriteString(theme.Dim.Render(strings.Repeat("─", 80)) + "\n")
+
+	var rows []tableRow
+	addRows := func(title string, stats []model.CoverageStat, limit int, label func(string) tableCell) {
+		if len(stats) == 0 {
+			return
+		}
+		rows = append(rows, tableRow{isHeader: true, headerText: title})
+		for i, s := range stats {
+			if i == limit {
+				rows = append(rows, tableRow{cells: []tableCell{{text: fmt.Sprintf("  %d more", len(stats)-limit), style: styleDim}}})
+				break
+			}
+			rows = append(rows, tableRow{
+				cells: []tableCell{
+					label(s.Name),
+					{text: fmt.Sprintf("%s of %s LOC", formatNumber(s.CoveredLOC), formatNumber(s.LOC))},
+					{text: fmt.Sprintf("%.1f%%", s.Coverage*100)},
+					{text: fmt.Sprintf("%d of %s measured", s.MeasuredFiles, pluralFiles(s.Files)), style: styleDim},
+				},
+			})
+		}
+	}
+
+	addRows("By role", report.ByRole, -1, func(name string) tableCell {
+		role := model.Role(name)
+		return tableCell{text: "  " + role.DisplayName(), style: func(text string, theme *renderer.Theme) string {
+			return theme.ForRole(role).Render(text)
+		}}
+	})
+	addRows("Core by language", report.ByLanguage, maxCoverageLanguageRows, func(name string) tableCell {
+		return tableCell{text: "  " + name}
+	})
+	addRows("Core by directory", report.ByDirectory, maxCoverageDirRows, func(name string) tableCell {
+		return tableCell{text: "  " + truncate(name+"/", 40)}
+	})
+
+	renderAlignedTable(&b, rows, tableSpec{
+		alignments: []alignColumn{alignLeft, alignRight, alignRight, alignLeft},
+		colWidths:  computeColumnWidths(rows, 4),
+	}, theme)
+
+	return b.String()
+}
@@ -0,0 +1,40 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/modern-tooling/aloc/internal/model"
+	"github.com/modern-tooling/aloc/internal/renderer"
+)
+
+// RenderDiagnostics renders a marginal note about files that were not fully counted.
+// The full list is only available in JSON output to keep the TUI uncluttered.
+func RenderDiagnostics(diags []model.Diagnostic, theme *renderer.Theme) string {
+	if len(diags) == 0 {
+		return ""
+	}
+
+	counts := make(map[model.DiagnosticKind]int)
+	for _, d := range diags {
+		counts[d.Kind]++
+	}
+
+	var parts []string
+	for _, kind := range []model.DiagnosticKind{model.DiagnosticSkipped, model.DiagnosticPartial, model.DiagnosticError} {
+		if n := counts[kind]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, kind))
+		}
+	}
+
+	noun := "files"
+	if len(diags) == 1 {
+		noun = "file"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(theme.Dim.Render(fmt.Sprintf("%d %s not fully counted (%s) · see `--format json` diagnostics",
+		len(diags), noun, strings.Join(parts, ", "))))
+	sb.WriteString("\n")
+	return sb.String()
+}
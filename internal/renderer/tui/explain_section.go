@@ -0,0 +1,127 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modern-tooling/aloc/internal/model"
+	"github.com/modern-tooling/aloc/internal/renderer"
+)
+
+// RenderExplanation renders the classification trace for one file: the rules
+// that fired, evidence per role, the confidence factors and the final role
+func RenderExplanation(ex *model.Explanation, theme *renderer.Theme) string {
+	var b strings.Builder
+
+	b.WriteString(theme.PrimaryBold.Render(ex.Path) + theme.Dim.Render(" ("+ex.Language+")") + "\n")
+	b.WriteString(theme.Dim.Render(strings.Repeat("─", 80)) + "\n")
+
+	if ex.Override != "" {
+		b.WriteString(fmt.Sprintf("Override %q → %s ", ex.Override, ex.Rules[0].Role) +
+			theme.Dim.Render("(weight 1.00, other rules skipped)") + "\n")
+	} else {
+		b.WriteString(theme.Dim.Render("Rules fired") + "\n")
+		if len(ex.Rules) == 0 {
+			b.WriteString(theme.Dim.Render("  none (defaults to core)") + "\n")
+		}
+		var rows []tableRow
+		for _, hit := range ex.Rules {
+			pattern := hit.Pattern
+			if hit.Custom {
+				pattern += " (aloc.yaml)"
+			}
+			role := string(hit.Role)
+			if hit.SubRole != "" {
+				role += "/" + string(hit.SubRole)
+			}
+			rows = append(rows, tableRow{
+				cells: []tableCell{
+					{text: "  " + string(hit.Signal), style: styleDim},
+					{text: truncate(pattern, 40)},
+					{text: role, style: styleRole(hit.Role)},
+					{text: fmt.Sprintf("%.2f", hit.Weight)},
+				},
+			})
+		}
+		renderAlignedTable(&b, rows, tableSpec{
+			alignments: []alignColumn{alignLeft, alignLeft, alignLeft, alignRight},
+			colWidths:  computeColumnWidths(rows, 4),
+		}, theme)
+		for _, reason := range ex.Skipped {
+			b.WriteString(theme.Dim.Render("  skipped "+reason) + "\n")
+		}
+	}
+
+	// evidence per role, strongest first
+	if len(ex.Weights) > 0 {
+		b.WriteString(theme.Dim.Render("Evidence") + "\n")
+		roles := make([]model.Role, 0, len(ex.Weights))
+		for role := range ex.Weights {
+			roles = append(roles, role)
+		}
+		sort.Slice(roles, func(i, j int) bool {
+			if ex.Weights[roles[i]] == ex.Weights[roles[j]] {
+				return roles[i] < roles[j]
+			}
+			return ex.Weights[roles[i]] > ex.Weights[roles[j]]
+		})
+		var rows []tableRow
+		for _, role := range roles {
+			rows = append(rows, tableRow{
+				cells: []tableCell{
+					{text: "  " + string(role), style: styleRole(role)},
+					{text: fmt.Sprintf("%.2f", ex.Weights[role])},
+				},
+			})
+		}
+		renderAlignedTable(&b, rows, tableSpec{
+			alignments: []alignColumn{alignLeft, alignRight},
+			colWidths:  computeColumnWidths(rows, 2),
+		}, theme)
+	}
+
+	// confidence factors
+	b.WriteString(theme.Dim.Render("Confidence") + "\n")
+	if len(ex.Weights) == 0 {
+		b.WriteString("  no evidence: core by default\n")
+	} else {
+		writeConfidenceFactors(&b, ex)
+	}
+	if n := ex.Neighborhood; n != nil {
+		from := "in " + n.Dir
+		if n.Distance > 0 {
+			from = fmt.Sprintf("mostly from %s, %d %s up", n.Dir, n.Distance, pluralize(n.Distance, "level", "levels"))
+		}
+		fmt.Fprintf(&b, "  neighborhood       %s %.2f → %s (%.0f%% of %.1f decayed votes, %s)\n",
+			n.FromRole, n.FromConfidence, ex.Role, n.DominantRatio*100, n.Support, from)
+	}
+
+	role := string(ex.Role)
+	if ex.SubRole != "" {
+		role += "/" + string(ex.SubRole)
+	}
+	b.WriteString("Role " + theme.ForRole(ex.Role).Render(role) +
+		theme.Dim.Render(fmt.Sprintf(" · confidence %.2f", ex.Confidence)) + "\n")
+
+	return b.String()
+}
+
+// writeConfidenceFactors explains the multipliers applied to the top weight
+func writeConfidenceFactors(b *strings.Builder, ex *model.Explanation) {
+	penalty := "none"
+	if ex.AmbiguityPenalty < 1 {
+		penalty = fmt.Sprintf("×%.2f (near-tie with %s)", ex.AmbiguityPenalty, ex.RunnerUp)
+	} else if ex.RunnerUp != "" {
+		penalty = fmt.Sprintf("none (clear of %s)", ex.RunnerUp)
+	}
+	fmt.Fprintf(b, "  ambiguity penalty  %s\n", penalty)
+	fmt.Fprintf(b, "  agreement factor   ×%.2f (0.25 per supporting signal, max 1)\n", ex.AgreementFactor)
+}
+
+// styleRole colors a cell with the role's semantic color
+func styleRole(role model.Role) func(string, *renderer.Theme) string {
+	return func(text string, theme *renderer.Theme) string {
+		return theme.ForRole(role).Render(text)
+	}
+}
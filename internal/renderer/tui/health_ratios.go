@@ -3,6 +3,7 @@ package tui
 import (
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/modern-tooling/aloc/internal/model"
 	"github.com/modern-tooling/aloc/internal/renderer"
@@ -106,34 +107,67 @@ func RenderHealthRatiosWithGauges(ratios model.Ratios, lines model.LineMetrics,
 	b.WriteString(theme.PrimaryBold.Render("Health Ratios") + "\n")
 	b.WriteString(theme.Dim.Render(strings.Repeat("─", 80)) + "\n")
 
+	// labels line up with the longest custom role's
+	width := len("Comment / Code")
+	for _, r := range ratios.Roles {
+		width = max(width, utf8.RuneCountInString(r.Name+" / Core"))
+	}
+	if ratios.CoveredCoreToCore != nil {
+		width = max(width, len("Covered core / Core"))
+	}
+
 	// Test/Core with gauge
 	testHealth := assessTestRatio(ratios.TestToCore)
-	b.WriteString(renderRatioWithGauge("Test / Core", float64(ratios.TestToCore), 0.5, 0.8, testHealth, theme))
+	b.WriteString(renderRatioWithGauge("Test / Core", width, float64(ratios.TestToCore), 0.5, 0.8, testHealth, theme))
+
+	// Covered core/Core with gauge (measured coverage, with --coverage)
+	if ratios.CoveredCoreToCore != nil {
+		covered := *ratios.CoveredCoreToCore
+		b.WriteString(renderRatioWithGauge("Covered core / Core", width, float64(covered), 0.7, 0.9, assessCoverageRatio(covered), theme))
+	}
 
 	// Comment/Code ratio with gauge
 	if lines.Code > 0 {
 		commentRatio := float32(lines.Comments) / float32(lines.Code)
 		commentHealth := assessCommentRatio(commentRatio)
-		b.WriteString(renderRatioWithGauge("Comment / Code", float64(commentRatio), 0.15, 0.35, commentHealth, theme))
+		b.WriteString(renderRatioWithGauge("Comment / Code", width, float64(commentRatio), 0.15, 0.35, commentHealth, theme))
 	}
 
 	// Docs/Core with gauge
 	docsHealth := assessDocsRatio(ratios.DocsToCore)
-	b.WriteString(renderRatioWithGauge("Docs / Core", float64(ratios.DocsToCore), 0.2, 0.5, docsHealth, theme))
+	b.WriteString(renderRatioWithGauge("Docs / Core", width, float64(ratios.DocsToCore), 0.2, 0.5, docsHealth, theme))
 
 	// Infra/Core with gauge (lower is better)
 	infraHealth := assessInfraRatio(ratios.InfraToCore)
-	b.WriteString(renderRatioWithGauge("Infra / Core", float64(ratios.InfraToCore), 0.0, 0.1, infraHealth, theme))
+	b.WriteString(renderRatioWithGauge("Infra / Core", width, float64(ratios.InfraToCore), 0.0, 0.1, infraHealth, theme))
 
 	// Config/Core with gauge (lower is better)
 	configHealth := assessConfigRatio(ratios.ConfigToCore)
-	b.WriteString(renderRatioWithGauge("Config / Core", float64(ratios.ConfigToCore), 0.0, 0.05, configHealth, theme))
+	b.WriteString(renderRatioWithGauge("Config / Core", width, float64(ratios.ConfigToCore), 0.0, 0.05, configHealth, theme))
+
+	// Custom roles with a ratio_to_core target
+	for _, r := range ratios.Roles {
+		health := assessTargetRatio(r.ToCore, r.Target)
+		b.WriteString(renderRatioWithGauge(r.Name+" / Core", width, float64(r.ToCore), float64(r.Target.Min), float64(r.Target.Max), health, theme))
+	}
 
 	return b.String()
 }
 
+// assessTargetRatio judges a custom role's ratio against its configured range
+func assessTargetRatio(ratio float32, target model.RatioTarget) RatioHealth {
+	switch {
+	case ratio >= target.Min && ratio <= target.Max:
+		return RatioHealth{"✓", "within target", true, false}
+	case ratio > target.Max:
+		return RatioHealth{"⚠", "above target", false, true}
+	default:
+		return RatioHealth{"◦", "below target", false, false}
+	}
+}
+
 // renderRatioWithGauge renders a ratio with a visual range indicator
-func renderRatioWithGauge(label string, value, targetMin, targetMax float64, health RatioHealth, theme *renderer.Theme) string {
+func renderRatioWithGauge(label string, width int, value, targetMin, targetMax float64, health RatioHealth, theme *renderer.Theme) string {
 	var b strings.Builder
 
 	var symbolStyle = theme.Dim
@@ -146,8 +180,8 @@ func renderRatioWithGauge(label string, value, targetMin, targetMax float64, hea
 	// Render gauge bar (shorter for visual restraint)
 	gauge := renderGauge(value, targetMin, targetMax, 18, theme)
 
-	b.WriteString(fmt.Sprintf("  %-14s %5.2f  %s  %s %s\n",
-		label,
+	b.WriteString(fmt.Sprintf("  %-*s %5.2f  %s  %s %s\n",
+		width, label,
 		value,
 		gauge,
 		symbolStyle.Render(health.Symbol),
@@ -211,6 +245,19 @@ func renderGauge(value, targetMin, targetMax float64, width int, theme *renderer
 	return result.String()
 }
 
+func assessCoverageRatio(ratio float32) RatioHealth {
+	switch {
+	case ratio > 0.9:
+		return RatioHealth{"✓", "thorough coverage", true, false}
+	case ratio >= 0.7:
+		return RatioHealth{"✓", "healthy coverage", true, false}
+	case ratio >= 0.5:
+		return RatioHealth{"◦", "partial coverage", false, false}
+	default:
+		return RatioHealth{"⚠", "most core code never runs in tests", false, true}
+	}
+}
+
 func assessCommentRatio(ratio float32) RatioHealth {
 	switch {
 	case ratio >= 0.15 && ratio <= 0.35:
@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/modern-tooling/aloc/internal/model"
+	"github.com/modern-tooling/aloc/internal/renderer"
+)
+
+// RenderLearnSummary renders the outcome of aloc learn: how many labeled
+// files the engine gets right before and after, and each fitted weight,
+// largest change first
+func RenderLearnSummary(summary *model.LearnSummary, theme *renderer.Theme) string {
+	var b strings.Builder
+
+	b.WriteString(theme.PrimaryBold.Render("Learned Rule Weights") + "\n")
+	b.WriteString(theme.Dim.Render(strings.Repeat("─", 80)) + "\n")
+	b.WriteString(fmt.Sprintf("Fitted on %s: %.0f%% → %.0f%% classified as labeled\n",
+		pluralize(summary.Examples, "1 labeled file", formatNumber(summary.Examples)+" labeled files"),
+		summary.Before*100, summary.After*100))
+
+	if len(summary.Rules) == 0 {
+		b.WriteString("The built-in weights already fit the labels; no rule changed.\n")
+		return b.String()
+	}
+
+	rules := slices.Clone(summary.Rules)
+	slices.SortStableFunc(rules, func(a, c model.RuleWeight) int {
+		return cmp.Compare(math.Abs(float64(c.Weight-c.Default)), math.Abs(float64(a.Weight-a.Default)))
+	})
+
+	var rows []tableRow
+	for _, r := range rules {
+		from := fmt.Sprintf("%.2f", r.Default)
+		if r.Default == 0 {
+			from = "new"
+		}
+		to := fmt.Sprintf("%.2f", r.Weight)
+		if r.Weight == 0 {
+			to = "off"
+		}
+		rows = append(rows, tableRow{
+			cells: []tableCell{
+				{text: "  " + r.Kind, style: styleDim},
+				{text: truncate(r.Pattern, 40)},
+				{text: string(r.Role), style: styleRole(r.Role)},
+				{text: from, style: styleDim},
+				{text: "→ " + to},
+			},
+		})
+	}
+	renderAlignedTable(&b, rows, tableSpec{
+		alignments: []alignColumn{alignLeft, alignLeft, alignLeft, alignRight, alignLeft},
+		colWidths:  computeColumnWidths(rows, 5),
+	}, theme)
+	return b.String()
+}
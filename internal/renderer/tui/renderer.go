@@ -47,21 +47,43 @@ func (r *TUIRenderer) Render(report *model.Report) error {
 	sections = append(sections, RenderScaleAndEffort(report, r.theme))
 
 	// 2. Responsibility Balance (role distribution)
-	sections = append(sections, RenderResponsibilityBalance(report.Responsibilities, report.Summary.LOCTotal, r.theme))
+	sections = append(sections, RenderResponsibilityBalance(report.Responsibilities, report.Summary.LOCTotal, r.theme)+
+		RenderTestFrameworks(report.TestFrameworks, r.theme)+
+		RenderVendored(report.Vendored, r.theme))
 
 	// 3. Language Breakdown (supporting evidence)
 	if len(report.Languages) > 0 {
 		sections = append(sections, RenderLanguageLedger(report.Languages, r.theme, r.noEmbedded))
 	}
 
+	// 3a. Assets (binary inventory, only if any were found)
+	if report.Assets != nil {
+		sections = append(sections, RenderAssets(report.Assets, r.theme))
+	}
+
 	// 4. Health Ratios (interpretive layer - ratios comparing roles)
 	sections = append(sections, RenderHealthRatiosWithGauges(report.Ratios, report.Summary.Lines, r.theme))
 
+	// 4a. Untested Core (core files without a paired test)
+	if report.Untested != nil {
+		sections = append(sections, RenderUntested(report.Untested, r.theme))
+	}
+
+	// 4b. Coverage (measured, with --coverage)
+	if report.Coverage != nil {
+		sections = append(sections, RenderCoverage(report.Coverage, r.theme))
+	}
+
 	// 5. Git Dynamics (optional, after Health Ratios)
 	if report.Git != nil {
 		sections = append(sections, RenderGitDynamics(report.Git, r.theme, r.width))
 	}
 
+	// 5a. Abandoned Surface (git, stale and deprecated code)
+	if report.Abandoned != nil {
+		sections = append(sections, RenderAbandoned(report.Abandoned, report.Files, report.Summary.LOCTotal, r.theme))
+	}
+
 	// 6. Effort Comparison (economics - last, with git adjustment inlined)
 	if report.Effort != nil && report.Effort.Comparison != nil {
 		sections = append(sections, RenderDevelopmentCost(report.Effort, report.Git, r.theme))
@@ -72,6 +94,11 @@ func (r *TUIRenderer) Render(report *model.Report) error {
 		sections = append(sections, RenderGitHint(report.GitHint, r.theme))
 	}
 
+	// 8. Diagnostics (marginal note, only if some files were not fully counted)
+	if len(report.Diagnostics) > 0 {
+		sections = append(sections, RenderDiagnostics(report.Diagnostics, r.theme))
+	}
+
 	output := strings.Join(sections, "\n")
 	_, err := r.writer.Write([]byte(output + "\n"))
 	return err
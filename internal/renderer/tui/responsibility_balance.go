@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 
@@ -38,7 +39,7 @@ func RenderResponsibilityBalance(responsibilities []model.Responsibility, totalL
 		pct := float64(r.LOC) / float64(totalLOC) * 100
 		if pct >= 1.0 {
 			roleStyle := theme.ForRole(r.Role)
-			parts = append(parts, roleStyle.Render(fmt.Sprintf("%s %.0f%%", r.Role, pct)))
+			parts = append(parts, roleStyle.Render(fmt.Sprintf("%s %.0f%%", r.Role.DisplayName(), pct)))
 		} else {
 			otherPct += pct
 		}
@@ -50,5 +51,94 @@ func RenderResponsibilityBalance(responsibilities []model.Responsibility, totalL
 
 	b.WriteString(strings.Join(parts, theme.Dim.Render(" · ")) + "\n")
 
+	for _, r := range sorted {
+		switch {
+		case r.Role == model.RoleTest:
+			b.WriteString(renderTestBreakdown(r.Breakdown, theme))
+		case r.Role == model.RoleInfra && len(r.Breakdown) > 0:
+			b.WriteString(theme.Dim.Render("infra: "+strings.Join(breakdownParts(r.Breakdown), " · ")) + "\n")
+		case r.LOC > 0 && slices.Contains(familyRoles, r.Role):
+			b.WriteString(renderFamilyLine(r, totalLOC, theme))
+		}
+	}
+
 	return b.String()
 }
+
+// renderTestBreakdown renders the test sub-role mix when there is more than
+// one kind of test
+func renderTestBreakdown(breakdown map[model.SubRole]float32, theme *renderer.Theme) string {
+	if len(breakdown) < 2 {
+		return ""
+	}
+	return theme.Dim.Render("test: "+strings.Join(breakdownParts(breakdown), " · ")) + "\n"
+}
+
+// familyRoles get a line of their own in the balance
+var familyRoles = []model.Role{model.RoleMigrations, model.RoleL10n, model.RoleSchemas}
+
+// renderFamilyLine renders a line of its own for a role with formats, such as
+// migrations or l10n, however small its share: its size and its format mix
+func renderFamilyLine(r model.Responsibility, totalLOC int, theme *renderer.Theme) string {
+	pct := float64(r.LOC) / float64(totalLOC) * 100
+	line := theme.ForRole(r.Role).Render(fmt.Sprintf("%s %.1f%%", r.Role.DisplayName(), pct))
+	detail := pluralFiles(r.Files)
+	if parts := breakdownParts(r.Breakdown); len(parts) > 0 {
+		detail += ": " + strings.Join(parts, " · ")
+	}
+	return line + theme.Dim.Render(" · "+detail) + "\n"
+}
+
+// breakdownParts formats a sub-role mix, largest share first
+func breakdownParts(breakdown map[model.SubRole]float32) []string {
+	kinds := make([]model.SubRole, 0, len(breakdown))
+	for kind := range breakdown {
+		kinds = append(kinds, kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool {
+		if breakdown[kinds[i]] == breakdown[kinds[j]] {
+			return kinds[i] < kinds[j]
+		}
+		return breakdown[kinds[i]] > breakdown[kinds[j]]
+	})
+
+	var parts []string
+	for _, kind := range kinds {
+		if pct := breakdown[kind] * 100; pct >= 0.5 {
+			parts = append(parts, fmt.Sprintf("%s %.0f%%", kind, pct))
+		}
+	}
+	return parts
+}
+
+// RenderTestFrameworks renders the test frameworks found, as a marginal line
+// under the responsibility balance
+func RenderTestFrameworks(frameworks []model.TestFrameworkStat, theme *renderer.Theme) string {
+	if len(frameworks) == 0 {
+		return ""
+	}
+	parts := make([]string, len(frameworks))
+	for i, f := range frameworks {
+		parts[i] = fmt.Sprintf("%s (%s)", f.Name, pluralFiles(f.Files))
+	}
+	return theme.Dim.Render("test frameworks: "+strings.Join(parts, " · ")) + "\n"
+}
+
+// maxVendoredShown caps the upstream projects named under the balance
+const maxVendoredShown = 5
+
+// RenderVendored renders the upstream projects of vendored code, as a
+// marginal line under the responsibility balance
+func RenderVendored(vendored []model.VendoredStat, theme *renderer.Theme) string {
+	if len(vendored) == 0 {
+		return ""
+	}
+	var parts []string
+	for _, v := range vendored[:min(len(vendored), maxVendoredShown)] {
+		parts = append(parts, fmt.Sprintf("%s (%s)", v.Name, pluralFiles(v.Files)))
+	}
+	if more := len(vendored) - maxVendoredShown; more > 0 {
+		parts = append(parts, fmt.Sprintf("%d more", more))
+	}
+	return theme.Dim.Render("vendored: "+strings.Join(parts, " · ")) + "\n"
+}
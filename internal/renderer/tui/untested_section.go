@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/modern-tooling/aloc/internal/model"
+	"github.com/modern-tooling/aloc/internal/renderer"
+)
+
+// TUI caps for the untested report (JSON lists every directory)
+const (
+	maxUntestedDirRows  = 8
+	maxUntestedFileRows = 5
+)
+
+// RenderUntested renders the core code without a paired test: shares of core
+// files and LOC, the directories with the most untested LOC and the largest
+// untested files
+func RenderUntested(report *model.UntestedReport, theme *renderer.Theme) string {
+	if report == nil {
+		return ""
+	}
+
+	var b strings.Builder
+
+	b.WriteString(theme.PrimaryBold.Render("Untested Core") + theme.Dim.Render(" (no paired test file)") + "\n")
+	b.WriteString(theme.Dim.Render(strings.Repeat("─", 80)) + "\n")
+
+	fmt.Fprintf(&b, "%s of core files · %s of core LOC %s\n",
+		theme.Accent.Render(formatShare(report.UntestedFiles, report.CoreFiles)),
+		theme.Accent.Render(formatShare(report.UntestedLOC, report.CoreLOC)),
+		theme.Dim.Render(fmt.Sprintf("(%s of %s LOC)", formatNumber(report.UntestedLOC), formatNumber(report.CoreLOC))))
+
+	if report.UntestedFiles == 0 {
+		return b.String()
+	}
+
+	// directories with the most untested LOC
+	var rows []tableRow
+	for _, d := range report.Directories {
+		if d.UntestedLOC == 0 || len(rows) == maxUntestedDirRows {
+			break
+		}
+		rows = append(rows, tableRow{
+			cells: []tableCell{
+				{text: "  " + truncate(d.Path+"/", 44)},
+				{text: fmt.Sprintf("%d of %s", d.UntestedFiles, pluralFiles(d.CoreFiles)), style: styleDim},
+				{text: fmt.Sprintf("%s of %s LOC", formatNumber(d.UntestedLOC), formatNumber(d.CoreLOC))},
+				{text: formatShare(d.UntestedLOC, d.CoreLOC)},
+			},
+		})
+	}
+	renderAlignedTable(&b, rows, tableSpec{
+		alignments: []alignColumn{alignLeft, alignRight, alignRight, alignRight},
+		colWidths:  computeColumnWidths(rows, 4),
+	}, theme)
+	if more := countUntestedDirs(report) - len(rows); more > 0 {
+		b.WriteString(theme.Dim.Render(fmt.Sprintf("  %d more directories", more)) + "\n")
+	}
+
+	// largest untested files
+	b.WriteString(theme.Dim.Render("Largest untested") + "\n")
+	shown := report.Largest
+	if len(shown) > maxUntestedFileRows {
+		shown = shown[:maxUntestedFileRows]
+	}
+	locWidth := 0
+	for _, f := range shown {
+		locWidth = max(locWidth, len(formatNumber(f.LOC)))
+	}
+	for _, f := range shown {
+		fmt.Fprintf(&b, "  %*s  %s\n", locWidth, formatNumber(f.LOC), truncate(f.Path, 60))
+	}
+
+	return b.String()
+}
+
+// countUntestedDirs counts the directories holding untested core code
+func countUntestedDirs(report *model.UntestedReport) int {
+	n := 0
+	for _, d := range report.Directories {
+		if d.UntestedLOC > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// formatShare formats part/total as a percentage
+func formatShare(part, total int) string {
+	if total == 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%.0f%%", float64(part)/float64(total)*100)
+}
@@ -0,0 +1,104 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Asset types reported in the asset inventory
+const (
+	AssetImage    = "image"
+	AssetFont     = "font"
+	AssetAudio    = "audio"
+	AssetVideo    = "video"
+	AssetModel    = "model"
+	AssetArchive  = "archive"
+	AssetDocument = "document"
+	AssetData     = "data"
+	AssetBinary   = "binary"
+)
+
+// assetExtensions maps binary asset extensions to their asset type. Files
+// with these extensions are inventoried by size instead of being counted.
+var assetExtensions = map[string]string{
+	// images (SVG is text and counted as a language)
+	".png": AssetImage, ".jpg": AssetImage, ".jpeg": AssetImage, ".gif": AssetImage,
+	".webp": AssetImage, ".avif": AssetImage, ".bmp": AssetImage, ".ico": AssetImage,
+	".icns": AssetImage, ".tif": AssetImage, ".tiff": AssetImage, ".heic": AssetImage,
+	".psd": AssetImage,
+
+	// fonts
+	".ttf": AssetFont, ".otf": AssetFont, ".woff": AssetFont, ".woff2": AssetFont, ".eot": AssetFont,
+
+	// audio and video
+	".mp3": AssetAudio, ".wav": AssetAudio, ".ogg": AssetAudio, ".flac": AssetAudio, ".aac": AssetAudio,
+	".m4a": AssetAudio,
+	".mp4": AssetVideo, ".mov": AssetVideo, ".webm": AssetVideo, ".mkv": AssetVideo, ".avi": AssetVideo,
+
+	// machine learning models and weights
+	".onnx": AssetModel, ".pt": AssetModel, ".pth": AssetModel, ".h5": AssetModel, ".pb": AssetModel,
+	".tflite": AssetModel, ".ckpt": AssetModel, ".safetensors": AssetModel, ".gguf": AssetModel,
+	".mlmodel": AssetModel, ".pkl": AssetModel, ".joblib": AssetModel,
+
+	// archives and packages
+	".zip": AssetArchive, ".tar": AssetArchive, ".gz": AssetArchive, ".tgz": AssetArchive,
+	".bz2": AssetArchive, ".xz": AssetArchive, ".7z": AssetArchive, ".rar": AssetArchive,
+	".jar": AssetArchive, ".war": AssetArchive, ".whl": AssetArchive, ".nupkg": AssetArchive,
+
+	// office documents
+	".pdf": AssetDocument, ".doc": AssetDocument, ".docx": AssetDocument, ".xls": AssetDocument,
+	".xlsx": AssetDocument, ".ppt": AssetDocument, ".pptx": AssetDocument,
+
+	// binary data
+	".parquet": AssetData, ".sqlite": AssetData, ".sqlite3": AssetData, ".db": AssetData,
+	".npy": AssetData, ".npz": AssetData, ".avro": AssetData, ".bin": AssetData, ".dat": AssetData,
+
+	// compiled artifacts
+	".exe": AssetBinary, ".dll": AssetBinary, ".so": AssetBinary, ".dylib": AssetBinary,
+	".a": AssetBinary, ".o": AssetBinary, ".lib": AssetBinary, ".class": AssetBinary,
+	".wasm": AssetBinary, ".pyc": AssetBinary,
+}
+
+func isAssetExtension(ext string) bool {
+	_, ok := assetExtensions[ext]
+	return ok
+}
+
+// AssetType returns the asset type for path based on its extension,
+// or "" if the extension is not a known asset extension
+func AssetType(path string) string {
+	return assetExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// lfsPointerPrefix starts every Git LFS pointer file
+var lfsPointerPrefix = []byte("version https://git-lfs.github.com/spec/")
+
+// lfsPointerMaxSize is the largest file that can be an LFS pointer (per the spec)
+const lfsPointerMaxSize = 1024
+
+// ReadLFSPointer reports whether path is a Git LFS pointer file and, if so,
+// the size of the object it stands in for
+func ReadLFSPointer(path string) (size int64, ok bool) {
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) > lfsPointerMaxSize {
+		return 0, false
+	}
+	return parseLFSPointer(data)
+}
+
+func parseLFSPointer(data []byte) (size int64, ok bool) {
+	if !bytes.HasPrefix(data, lfsPointerPrefix) {
+		return 0, false
+	}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		if v, found := strings.CutPrefix(sc.Text(), "size "); found {
+			size, _ = strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		}
+	}
+	return size, true
+}
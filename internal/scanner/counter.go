@@ -2,6 +2,8 @@ package scanner
 
 import (
 	"bufio"
+	"bytes"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -11,14 +13,108 @@ import (
 	"github.com/modern-tooling/aloc/internal/model"
 )
 
-// bufferPool reuses 256KB buffers - the documented sweet spot for SSD I/O
-var bufferPool = sync.Pool{
+// readerPool reuses 256KB buffered readers - the documented sweet spot for SSD I/O
+var readerPool = sync.Pool{
 	New: func() any {
-		buf := make([]byte, 256*1024)
-		return &buf
+		return bufio.NewReaderSize(nil, 256*1024)
 	},
 }
 
+// binarySniffLen is how many leading bytes are checked for a NUL byte
+const binarySniffLen = 512
+
+// PartialReadError reports that a read failed after counting had started.
+// The metrics returned alongside it cover the lines read before the failure.
+type PartialReadError struct {
+	Path string
+	Err  error
+}
+
+func (e *PartialReadError) Error() string {
+	return fmt.Sprintf("%s: read stopped early: %v", e.Path, e.Err)
+}
+
+func (e *PartialReadError) Unwrap() error {
+	return e.Err
+}
+
+// openCounted opens path and returns a pooled reader positioned at the start
+// of the file, or binary=true if the file looks binary (or is a Git LFS
+// pointer). Callers must invoke
+// release when done.
+func openCounted(path string) (br *bufio.Reader, binary bool, release func(), err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false, nil, err
+	}
+
+	br = readerPool.Get().(*bufio.Reader)
+	br.Reset(f)
+	release = func() {
+		br.Reset(nil)
+		readerPool.Put(br)
+		f.Close()
+	}
+
+	// Binary check: look for NUL byte in first 512 bytes (Peek does not consume)
+	head, err := br.Peek(binarySniffLen)
+	if err != nil && err != io.EOF && len(head) == 0 {
+		release()
+		return nil, false, nil, err
+	}
+	for _, c := range head {
+		if c == 0 {
+			return br, true, release, nil
+		}
+	}
+	// Git LFS pointers stand in for binary content and are not counted either
+	if bytes.HasPrefix(head, lfsPointerPrefix) {
+		return br, true, release, nil
+	}
+	return br, false, release, nil
+}
+
+// forEachLine calls fn for every line in br without its line terminator.
+// The slice passed to fn is only valid until fn returns. Lines longer than
+// the reader's buffer are accumulated, so there is no maximum line length.
+// It returns the first read error other than io.EOF.
+func forEachLine(br *bufio.Reader, fn func(line []byte)) error {
+	var long []byte
+	for {
+		chunk, err := br.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			long = append(long, chunk...)
+			continue
+		}
+		if len(long) > 0 {
+			long = append(long, chunk...)
+			chunk = long
+		}
+		if len(chunk) > 0 {
+			fn(trimLineEnding(chunk))
+		}
+		long = long[:0]
+
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// trimLineEnding drops a trailing "\n" or "\r\n"
+func trimLineEnding(line []byte) []byte {
+	if n := len(line); n > 0 && line[n-1] == '\n' {
+		line = line[:n-1]
+	}
+	if n := len(line); n > 0 && line[n-1] == '\r' {
+		line = line[:n-1]
+	}
+	return line
+}
+
 // CountLOC counts lines of code, returning 0 if file is binary.
 // This combines binary detection with LOC counting to avoid opening the file twice.
 func CountLOC(path string) (int, error) {
@@ -30,133 +126,182 @@ func CountLOC(path string) (int, error) {
 }
 
 // CountLines counts all line types (total, blanks, comments, code).
-// Returns zero metrics if file is binary.
+// Returns zero metrics if file is binary. If reading fails partway through,
+// the metrics counted so far are returned with a *PartialReadError.
 func CountLines(path string) (model.LineMetrics, error) {
-	f, err := os.Open(path)
+	return countLinesAs(path, "")
+}
+
+// countLinesAs counts lines with the comment syntax of lang, or of the
+// language detected from path when lang is empty
+func countLinesAs(path, lang string) (model.LineMetrics, error) {
+	br, binary, release, err := openCounted(path)
 	if err != nil {
 		return model.LineMetrics{}, err
 	}
-	defer f.Close()
-
-	// Get pooled buffer for binary check
-	bufPtr := bufferPool.Get().(*[]byte)
-	defer bufferPool.Put(bufPtr)
-	buf := *bufPtr
+	defer release()
 
-	// Read first chunk and check for binary
-	n, err := f.Read(buf)
-	if err != nil && err != io.EOF {
-		return model.LineMetrics{}, err
+	if binary {
+		return model.LineMetrics{}, nil // binary file, no metrics
 	}
 
-	// Binary check: look for NUL byte in first 512 bytes
-	checkLen := min(n, 512)
-	for i := 0; i < checkLen; i++ {
-		if buf[i] == 0 {
-			return model.LineMetrics{}, nil // binary file, no metrics
-		}
+	if lang == "" {
+		lang = detectLangFromPath(path)
 	}
-
-	// Seek back to start for line counting
-	if _, err := f.Seek(0, io.SeekStart); err != nil {
-		return model.LineMetrics{}, err
+	metrics, err := countLinesFromReader(br, lang)
+	if err != nil {
+		return metrics, &PartialReadError{Path: path, Err: err}
 	}
-
-	lang := detectLangFromPath(path)
-	return countLinesFromReader(f, lang, bufPtr), nil
+	return metrics, nil
 }
 
-func countLinesFromReader(f *os.File, lang string, bufPtr *[]byte) model.LineMetrics {
-	scanner := bufio.NewScanner(f)
-	scanner.Buffer(*bufPtr, 256*1024)
+func countLinesFromReader(br *bufio.Reader, lang string) (model.LineMetrics, error) {
+	c := newLineClassifier(lang)
+	if c.plain() {
+		return countPlainLines(br)
+	}
 
 	var metrics model.LineMetrics
-	inBlockComment := false
-	blockStart, blockEnd := getBlockCommentMarkers(lang)
-	lineComment := getLineCommentMarker(lang)
-
-	for scanner.Scan() {
-		metrics.Total++
-		line := scanner.Text()
-		trimmed := strings.TrimSpace(line)
-
-		// Empty line
-		if trimmed == "" {
-			metrics.Blanks++
-			continue
+	logical := newLogicalCounter(lang)
+	inline := newInlineTestDetector(lang)
+	err := forEachLine(br, func(line []byte) {
+		code := metrics.Code
+		c.add(&metrics, line)
+		if logical != nil {
+			logical.feed(line)
 		}
+		if inline != nil && metrics.Code > code && inline.isTest(line) {
+			metrics.InlineTests++
+		}
+	})
+	if logical != nil {
+		metrics.Logical = logical.finish()
+	}
+	return metrics, err
+}
 
-		// Track if this line contributes to code
-		isCode := false
-		isComment := false
-
-		// Handle block comments
-		if blockStart != "" {
-			if inBlockComment {
-				// Inside block comment
-				if idx := strings.Index(trimmed, blockEnd); idx >= 0 {
-					inBlockComment = false
-					remainder := strings.TrimSpace(trimmed[idx+len(blockEnd):])
-					if remainder != "" && !strings.HasPrefix(remainder, lineComment) {
-						isCode = true
-					} else if remainder != "" {
-						isComment = true
-					} else {
-						isComment = true
-					}
-				} else {
-					isComment = true
-				}
-			} else if strings.Contains(trimmed, blockStart) {
-				// Check for block comment start
-				startIdx := strings.Index(trimmed, blockStart)
-				beforeComment := strings.TrimSpace(trimmed[:startIdx])
-				endIdx := strings.Index(trimmed[startIdx+len(blockStart):], blockEnd)
-
-				if endIdx >= 0 {
-					// Block comment starts and ends on same line
-					afterComment := strings.TrimSpace(trimmed[startIdx+len(blockStart)+endIdx+len(blockEnd):])
-					if beforeComment != "" || afterComment != "" {
-						isCode = true
-					} else {
-						isComment = true
-					}
-				} else {
-					// Block comment starts but doesn't end
-					inBlockComment = true
-					if beforeComment != "" {
-						isCode = true
-					} else {
-						isComment = true
-					}
-				}
-			} else if lineComment != "" && strings.HasPrefix(trimmed, lineComment) {
-				isComment = true
-			} else {
-				isCode = true
+// countPlainLines is the fast path for languages without comment syntax.
+// Lines are only split into blank and code, so long lines never need to be
+// accumulated and the scan is a memchr over the reader's buffer.
+func countPlainLines(br *bufio.Reader) (model.LineMetrics, error) {
+	var metrics model.LineMetrics
+	inLine := false  // current line has at least one byte
+	hasCode := false // current line has a non-whitespace byte
+
+	for {
+		chunk, err := br.ReadSlice('\n')
+		if len(chunk) > 0 {
+			inLine = true
+			if !hasCode && len(bytes.TrimSpace(chunk)) > 0 {
+				hasCode = true
 			}
-		} else {
-			// No block comment support for this language
-			if lineComment != "" && strings.HasPrefix(trimmed, lineComment) {
-				isComment = true
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if inLine {
+			metrics.Total++
+			if hasCode {
+				metrics.Code++
 			} else {
-				isCode = true
+				metrics.Blanks++
 			}
+			inLine, hasCode = false, false
 		}
 
-		if isCode {
-			metrics.Code++
-		} else if isComment {
-			metrics.Comments++
+		if err == io.EOF {
+			return metrics, nil
+		}
+		if err != nil {
+			return metrics, err
+		}
+	}
+}
+
+// lineClassifier sorts lines into blank, comment and code for one language,
+// carrying block comment state from line to line. It works on byte slices
+// so counting does not allocate per line.
+type lineClassifier struct {
+	lineComment    []byte
+	blockStart     []byte
+	blockEnd       []byte
+	inBlockComment bool
+}
+
+func newLineClassifier(lang string) *lineClassifier {
+	blockStart, blockEnd := getBlockCommentMarkers(lang)
+	return &lineClassifier{
+		lineComment: []byte(getLineCommentMarker(lang)),
+		blockStart:  []byte(blockStart),
+		blockEnd:    []byte(blockEnd),
+	}
+}
+
+// plain reports whether the language has no comment syntax at all
+func (c *lineClassifier) plain() bool {
+	return len(c.lineComment) == 0 && len(c.blockStart) == 0
+}
+
+// add classifies one line and records it in metrics
+func (c *lineClassifier) add(metrics *model.LineMetrics, line []byte) {
+	metrics.Total++
+	trimmed := bytes.TrimSpace(line)
+
+	// Empty line
+	if len(trimmed) == 0 {
+		metrics.Blanks++
+		return
+	}
+
+	if c.isCode(trimmed) {
+		metrics.Code++
+	} else {
+		metrics.Comments++
+	}
+}
+
+// isCode reports whether a non-blank trimmed line contributes code
+// (as opposed to being comment-only)
+func (c *lineClassifier) isCode(trimmed []byte) bool {
+	// No block comment support for this language
+	if len(c.blockStart) == 0 {
+		return !c.isLineComment(trimmed)
+	}
+
+	// Inside block comment
+	if c.inBlockComment {
+		idx := bytes.Index(trimmed, c.blockEnd)
+		if idx < 0 {
+			return false
 		}
+		c.inBlockComment = false
+		remainder := bytes.TrimSpace(trimmed[idx+len(c.blockEnd):])
+		return len(remainder) > 0 && !bytes.HasPrefix(remainder, c.lineComment)
 	}
 
-	return metrics
+	// Check for block comment start
+	startIdx := bytes.Index(trimmed, c.blockStart)
+	if startIdx < 0 {
+		return !c.isLineComment(trimmed)
+	}
+
+	beforeComment := bytes.TrimSpace(trimmed[:startIdx])
+	afterStart := trimmed[startIdx+len(c.blockStart):]
+	endIdx := bytes.Index(afterStart, c.blockEnd)
+
+	if endIdx >= 0 {
+		// Block comment starts and ends on same line
+		afterComment := bytes.TrimSpace(afterStart[endIdx+len(c.blockEnd):])
+		return len(beforeComment) > 0 || len(afterComment) > 0
+	}
+
+	// Block comment starts but doesn't end
+	c.inBlockComment = true
+	return len(beforeComment) > 0
 }
 
-// countLOCFromReader is kept for backward compatibility
-func countLOCFromReader(f *os.File, lang string, bufPtr *[]byte) int {
-	return countLinesFromReader(f, lang, bufPtr).Code
+func (c *lineClassifier) isLineComment(trimmed []byte) bool {
+	return len(c.lineComment) > 0 && bytes.HasPrefix(trimmed, c.lineComment)
 }
 
 func getLineCommentMarker(lang string) string {
@@ -186,124 +331,173 @@ func getBlockCommentMarkers(lang string) (string, string) {
 }
 
 func detectLangFromPath(path string) string {
+	if lang, ok := filenameToLang[strings.ToLower(filepath.Base(path))]; ok {
+		return lang
+	}
 	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
 	return extToLanguage(ext)
 }
 
-// CountLinesWithEmbedded counts lines and extracts embedded code blocks (for Markdown/MDX)
-func CountLinesWithEmbedded(path string) (model.LineMetrics, map[string]model.LineMetrics, error) {
-	f, err := os.Open(path)
+// countedFile is what a single read of a text file yields
+type countedFile struct {
+	lang     string // detected (shebang included) or given language
+	lines    model.LineMetrics
+	embedded map[string]model.LineMetrics
+	header   []byte // leading bytes, up to the requested header size
+	binary   bool
+}
+
+// countFile opens path once to detect its language, keep its first
+// headerSize bytes and count its lines. An empty lang is detected from the
+// path, or for files without an extension from the shebang; lines are then
+// counted with the comment syntax of the path's language, like CountLines.
+// Binary files are reported without lines or header.
+func countFile(path, lang string, headerSize int) (countedFile, error) {
+	br, binary, release, err := openCounted(path)
 	if err != nil {
-		return model.LineMetrics{}, nil, err
+		return countedFile{}, err
 	}
-	defer f.Close()
+	defer release()
 
-	// Get pooled buffer for binary check
-	bufPtr := bufferPool.Get().(*[]byte)
-	defer bufferPool.Put(bufPtr)
-	buf := *bufPtr
+	// openCounted has already peeked this much, so it is buffered
+	head, _ := br.Peek(max(headerSize, binarySniffLen))
 
-	// Read first chunk and check for binary
-	n, err := f.Read(buf)
-	if err != nil && err != io.EOF {
-		return model.LineMetrics{}, nil, err
+	counted := countedFile{lang: lang, binary: binary}
+	countAs := lang
+	if lang == "" {
+		counted.lang = detectLanguageFromHead(path, head)
+		countAs = detectLangFromPath(path)
+	}
+	if binary {
+		return counted, nil
+	}
+	if headerSize > 0 {
+		counted.header = bytes.Clone(head[:min(len(head), headerSize)])
 	}
 
-	// Binary check: look for NUL byte in first 512 bytes
-	checkLen := min(n, 512)
-	for i := 0; i < checkLen; i++ {
-		if buf[i] == 0 {
-			return model.LineMetrics{}, nil, nil // binary file
-		}
+	if HasEmbeddedCode(counted.lang) && HasEmbeddedCode(countAs) {
+		counted.lines, counted.embedded, err = literateCounters[countAs](br)
+	} else {
+		counted.lines, err = countLinesFromReader(br, countAs)
+	}
+	if err != nil {
+		return counted, &PartialReadError{Path: path, Err: err}
 	}
+	return counted, nil
+}
 
-	// Seek back to start
-	if _, err := f.Seek(0, io.SeekStart); err != nil {
+// CountLinesWithEmbedded counts lines and extracts embedded code blocks
+// (for Markdown and other literate languages, see HasEmbeddedCode)
+func CountLinesWithEmbedded(path string) (model.LineMetrics, map[string]model.LineMetrics, error) {
+	return countLinesWithEmbeddedAs(path, "")
+}
+
+// countLinesWithEmbeddedAs is CountLinesWithEmbedded for lang, or for the
+// language detected from path when lang is empty
+func countLinesWithEmbeddedAs(path, lang string) (model.LineMetrics, map[string]model.LineMetrics, error) {
+	br, binary, release, err := openCounted(path)
+	if err != nil {
 		return model.LineMetrics{}, nil, err
 	}
+	defer release()
 
-	lang := detectLangFromPath(path)
-	if lang == "Markdown" || lang == "MDX" {
-		return countMarkdownWithEmbedded(f, bufPtr)
+	if binary {
+		return model.LineMetrics{}, nil, nil // binary file
 	}
 
-	// Non-Markdown: use regular counting
-	metrics := countLinesFromReader(f, lang, bufPtr)
-	return metrics, nil, nil
+	var metrics model.LineMetrics
+	var embedded map[string]model.LineMetrics
+
+	if lang == "" {
+		lang = detectLangFromPath(path)
+	}
+	if HasEmbeddedCode(lang) {
+		metrics, embedded, err = literateCounters[lang](br)
+	} else {
+		// Non-literate: use regular counting
+		metrics, err = countLinesFromReader(br, lang)
+	}
+	if err != nil {
+		return metrics, embedded, &PartialReadError{Path: path, Err: err}
+	}
+	return metrics, embedded, nil
 }
 
-// countMarkdownWithEmbedded parses Markdown and extracts fenced code blocks
-func countMarkdownWithEmbedded(f *os.File, bufPtr *[]byte) (model.LineMetrics, map[string]model.LineMetrics, error) {
-	scanner := bufio.NewScanner(f)
-	scanner.Buffer(*bufPtr, 256*1024)
+// fence marks the start and end of a fenced Markdown code block
+var fence = []byte("```")
 
+// htmlCommentStart marks a Markdown comment line
+var htmlCommentStart = []byte("<!--")
+
+// countMarkdownWithEmbedded parses Markdown and extracts fenced code blocks
+func countMarkdownWithEmbedded(br *bufio.Reader) (model.LineMetrics, map[string]model.LineMetrics, error) {
 	var metrics model.LineMetrics
 	embedded := make(map[string]model.LineMetrics)
 
 	inCodeBlock := false
 	codeBlockLang := ""
-	var codeBlockLines []string
+	var block *lineClassifier
+	var blockMetrics model.LineMetrics
 
-	for scanner.Scan() {
+	err := forEachLine(br, func(line []byte) {
 		metrics.Total++
-		line := scanner.Text()
-		trimmed := strings.TrimSpace(line)
+		trimmed := bytes.TrimSpace(line)
 
 		// Check for fenced code block start/end
-		if strings.HasPrefix(trimmed, "```") {
+		if bytes.HasPrefix(trimmed, fence) {
 			if !inCodeBlock {
 				// Starting a code block
 				inCodeBlock = true
-				codeBlockLang = strings.TrimPrefix(trimmed, "```")
-				codeBlockLang = strings.TrimSpace(codeBlockLang)
 				// Normalize language name
-				codeBlockLang = normalizeCodeBlockLang(codeBlockLang)
-				codeBlockLines = nil
+				codeBlockLang = normalizeCodeBlockLang(string(bytes.TrimSpace(trimmed[len(fence):])))
+				block = nil
+				if codeBlockLang != "" {
+					block = newLineClassifier(codeBlockLang)
+				}
+				blockMetrics = model.LineMetrics{}
 				metrics.Code++ // the ``` line itself is "code" in Markdown
 			} else {
 				// Ending a code block
 				inCodeBlock = false
 				metrics.Code++ // the closing ``` line
 
-				// Process accumulated code block
-				if codeBlockLang != "" && len(codeBlockLines) > 0 {
-					blockMetrics := countCodeBlockLines(codeBlockLines, codeBlockLang)
-					existing := embedded[codeBlockLang]
-					existing.Total += blockMetrics.Total
-					existing.Code += blockMetrics.Code
-					existing.Comments += blockMetrics.Comments
-					existing.Blanks += blockMetrics.Blanks
-					embedded[codeBlockLang] = existing
-				}
+				// Record the completed code block
+				addEmbedded(embedded, codeBlockLang, blockMetrics)
 				codeBlockLang = ""
 			}
-			continue
+			return
 		}
 
 		if inCodeBlock {
-			// Inside code block - accumulate for later processing
-			codeBlockLines = append(codeBlockLines, line)
+			// Inside code block - classify with the block's language rules
+			if block != nil {
+				block.add(&blockMetrics, line)
+			}
 			metrics.Code++ // code blocks count as code in Markdown
-		} else if trimmed == "" {
+		} else if len(trimmed) == 0 {
 			metrics.Blanks++
-		} else if strings.HasPrefix(trimmed, "<!--") {
+		} else if bytes.HasPrefix(trimmed, htmlCommentStart) {
 			metrics.Comments++
 		} else {
 			metrics.Code++ // prose is "code" in Markdown
+			metrics.Prose++
 		}
-	}
+	})
 
-	if len(embedded) == 0 {
-		return metrics, nil, nil
-	}
-	return metrics, embedded, nil
+	return metrics, nilIfEmpty(embedded), err
 }
 
 // normalizeCodeBlockLang converts code fence language hints to canonical names
 func normalizeCodeBlockLang(hint string) string {
 	// remove common suffixes/annotations
-	hint = strings.Split(hint, " ")[0] // "typescript jsx" -> "typescript"
-	hint = strings.Trim(hint, "`")     // remove any stray backticks
+	hint = strings.TrimPrefix(hint, "{") // R Markdown/Quarto chunks: "{r setup, echo=FALSE}"
+	fields := strings.FieldsFunc(hint, func(r rune) bool {
+		return r == ' ' || r == ',' || r == '}'
+	})
+	if len(fields) == 0 {
+		return "" // bare fence or "{}"
+	}
+	hint = strings.Trim(fields[0], "`") // "typescript jsx" -> "typescript"; remove stray backticks
 
 	if hint == "" {
 		return ""
@@ -326,79 +520,3 @@ func normalizeCodeBlockLang(hint string) string {
 	// capitalize first letter for unknown languages
 	return strings.ToUpper(hint[:1]) + hint[1:]
 }
-
-// countCodeBlockLines counts lines within a code block using language-specific rules
-func countCodeBlockLines(lines []string, lang string) model.LineMetrics {
-	var metrics model.LineMetrics
-	lineComment := getLineCommentMarker(lang)
-	blockStart, blockEnd := getBlockCommentMarkers(lang)
-	inBlockComment := false
-
-	for _, line := range lines {
-		metrics.Total++
-		trimmed := strings.TrimSpace(line)
-
-		if trimmed == "" {
-			metrics.Blanks++
-			continue
-		}
-
-		isCode := false
-		isComment := false
-
-		// Handle block comments
-		if blockStart != "" {
-			if inBlockComment {
-				if idx := strings.Index(trimmed, blockEnd); idx >= 0 {
-					inBlockComment = false
-					remainder := strings.TrimSpace(trimmed[idx+len(blockEnd):])
-					if remainder != "" && !strings.HasPrefix(remainder, lineComment) {
-						isCode = true
-					} else {
-						isComment = true
-					}
-				} else {
-					isComment = true
-				}
-			} else if strings.Contains(trimmed, blockStart) {
-				startIdx := strings.Index(trimmed, blockStart)
-				beforeComment := strings.TrimSpace(trimmed[:startIdx])
-				endIdx := strings.Index(trimmed[startIdx+len(blockStart):], blockEnd)
-
-				if endIdx >= 0 {
-					afterComment := strings.TrimSpace(trimmed[startIdx+len(blockStart)+endIdx+len(blockEnd):])
-					if beforeComment != "" || afterComment != "" {
-						isCode = true
-					} else {
-						isComment = true
-					}
-				} else {
-					inBlockComment = true
-					if beforeComment != "" {
-						isCode = true
-					} else {
-						isComment = true
-					}
-				}
-			} else if lineComment != "" && strings.HasPrefix(trimmed, lineComment) {
-				isComment = true
-			} else {
-				isCode = true
-			}
-		} else {
-			if lineComment != "" && strings.HasPrefix(trimmed, lineComment) {
-				isComment = true
-			} else {
-				isCode = true
-			}
-		}
-
-		if isCode {
-			metrics.Code++
-		} else if isComment {
-			metrics.Comments++
-		}
-	}
-
-	return metrics
-}
@@ -0,0 +1,148 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+// referenceCountLines is the previous string-based counter (bufio.Scanner plus
+// scanner.Text and strings operations per line). It is kept as an oracle for
+// equivalence tests and as the baseline for the benchmarks below.
+func referenceCountLines(r io.Reader, lang string) model.LineMetrics {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 256*1024), 64*1024*1024)
+
+	var metrics model.LineMetrics
+	inBlockComment := false
+	blockStart, blockEnd := getBlockCommentMarkers(lang)
+	lineComment := getLineCommentMarker(lang)
+
+	for scanner.Scan() {
+		metrics.Total++
+		trimmed := strings.TrimSpace(scanner.Text())
+		if trimmed == "" {
+			metrics.Blanks++
+			continue
+		}
+
+		isCode := false
+		if blockStart != "" {
+			if inBlockComment {
+				if idx := strings.Index(trimmed, blockEnd); idx >= 0 {
+					inBlockComment = false
+					remainder := strings.TrimSpace(trimmed[idx+len(blockEnd):])
+					isCode = remainder != "" && !strings.HasPrefix(remainder, lineComment)
+				}
+			} else if startIdx := strings.Index(trimmed, blockStart); startIdx >= 0 {
+				beforeComment := strings.TrimSpace(trimmed[:startIdx])
+				endIdx := strings.Index(trimmed[startIdx+len(blockStart):], blockEnd)
+				if endIdx >= 0 {
+					afterComment := strings.TrimSpace(trimmed[startIdx+len(blockStart)+endIdx+len(blockEnd):])
+					isCode = beforeComment != "" || afterComment != ""
+				} else {
+					inBlockComment = true
+					isCode = beforeComment != ""
+				}
+			} else {
+				isCode = lineComment == "" || !strings.HasPrefix(trimmed, lineComment)
+			}
+		} else {
+			isCode = lineComment == "" || !strings.HasPrefix(trimmed, lineComment)
+		}
+
+		if isCode {
+			metrics.Code++
+		} else {
+			metrics.Comments++
+		}
+	}
+	return metrics
+}
+
+// syntheticSource builds a source file of roughly n lines mixing code,
+// blanks, line comments and block comments
+func syntheticSource(n int) []byte {
+	var b bytes.Buffer
+	for i := 0; i < n; i++ {
+		switch i % 10 {
+		case 0:
+			b.WriteString("// Package comment line explaining things\n")
+		case 1:
+			b.WriteString("\n")
+		case 2:
+			b.WriteString("/* block comment start\n")
+		case 3:
+			b.WriteString("   still inside the block */\n")
+		case 4:
+			fmt.Fprintf(&b, "x := compute(%d) /* inline */ + 1\n", i)
+		default:
+			fmt.Fprintf(&b, "\tresult%d := someFunction(arg1, arg2, \"string literal %d\")\n", i, i)
+		}
+	}
+	return b.Bytes()
+}
+
+func TestCountLinesFromReader_MatchesReference(t *testing.T) {
+	inputs := map[string]string{
+		"synthetic": string(syntheticSource(500)),
+		"nested":    "a /* b /* c */ d\n/* x\n*/ y // z\n  \n\t// done\n",
+		"no-eol":    "/* open\nstill open",
+		"crlf":      "a\r\n\r\n// c\r\n",
+	}
+	langs := []string{"Go", "Python", "JSON", "Text", "Shell", "unknown"}
+
+	for name, input := range inputs {
+		for _, lang := range langs {
+			want := referenceCountLines(strings.NewReader(input), lang)
+			got, err := countLinesFromReader(bufio.NewReader(strings.NewReader(input)), lang)
+			if err != nil {
+				t.Fatalf("%s/%s: unexpected error: %v", name, lang, err)
+			}
+			got.Logical = 0 // not computed by the reference
+			if got != want {
+				t.Errorf("%s/%s: got %+v, want %+v", name, lang, got, want)
+			}
+		}
+	}
+}
+
+func BenchmarkCountLines(b *testing.B) {
+	src := syntheticSource(20000)
+	plain := bytes.Repeat([]byte("{\"key\": \"value\", \"n\": 1}\n\n"), 10000)
+
+	cases := []struct {
+		name string
+		lang string
+		data []byte
+	}{
+		{"Go", "Go", src},
+		{"JSON", "JSON", plain},
+	}
+
+	for _, tc := range cases {
+		b.Run(tc.name+"/reference", func(b *testing.B) {
+			b.SetBytes(int64(len(tc.data)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				referenceCountLines(bytes.NewReader(tc.data), tc.lang)
+			}
+		})
+		b.Run(tc.name+"/bytes", func(b *testing.B) {
+			br := bufio.NewReaderSize(nil, 256*1024)
+			b.SetBytes(int64(len(tc.data)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				br.Reset(bytes.NewReader(tc.data))
+				if _, err := countLinesFromReader(br, tc.lang); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -3,7 +3,10 @@ package scanner
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/modern-tooling/aloc/internal/model"
 )
 
 func TestCountLOCFromBytes(t *testing.T) {
@@ -158,3 +161,42 @@ func TestCountLOC_TextFileWithNulAfter512(t *testing.T) {
 		t.Errorf("CountLOC for text file with late NUL = %d, want 1", got)
 	}
 }
+
+func TestCountLines_LineLongerThanBuffer(t *testing.T) {
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "bundle.js")
+
+	// a single 1MB line (4x the reader buffer) followed by more code
+	long := strings.Repeat("a", 1024*1024)
+	content := "var x = 1;\n" + long + "\n// trailing comment\nvar y = 2;\n"
+	os.WriteFile(tmpFile, []byte(content), 0644)
+
+	got, err := CountLines(tmpFile)
+	if err != nil {
+		t.Fatalf("CountLines failed: %v", err)
+	}
+	if got.Total != 4 {
+		t.Errorf("Total = %d, want 4", got.Total)
+	}
+	if got.Code != 3 {
+		t.Errorf("Code = %d, want 3", got.Code)
+	}
+	if got.Comments != 1 {
+		t.Errorf("Comments = %d, want 1", got.Comments)
+	}
+}
+
+func TestCountLines_NoTrailingNewlineAndCRLF(t *testing.T) {
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "main.go")
+	os.WriteFile(tmpFile, []byte("package main\r\n\r\n// comment\r\nfunc main() {}"), 0644)
+
+	got, err := CountLines(tmpFile)
+	if err != nil {
+		t.Fatalf("CountLines failed: %v", err)
+	}
+	want := model.LineMetrics{Total: 4, Blanks: 1, Comments: 1, Code: 2, Logical: 2}
+	if got != want {
+		t.Errorf("CountLines = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,322 @@
+package scanner
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+// GitAttributes resolves .gitattributes for the files of a scan. Lookups
+// follow git: the .gitattributes closest to a file wins over its parents',
+// .git/info/attributes over all of them, and later lines over earlier ones.
+// Macros ([attr]name ...) may only be defined at the top level.
+type GitAttributes struct {
+	top    string // work tree root, where the top-level .gitattributes lives
+	prefix string // scan root relative to top, slash-separated ("" if the same)
+	macros map[string][]attrAssign
+	info   []attrRule // .git/info/attributes
+
+	mu   sync.Mutex
+	dirs map[string][]attrRule // .gitattributes rules by directory relative to top
+}
+
+// attrState is the state a line gives an attribute
+type attrState int
+
+const (
+	attrSet         attrState = iota // name
+	attrUnset                        // -name
+	attrValue                        // name=value
+	attrUnspecified                  // !name
+)
+
+type attrAssign struct {
+	name  string
+	state attrState
+	value string
+}
+
+type attrRule struct {
+	dir      string // directory of the .gitattributes relative to top ("" at the top)
+	pattern  string
+	anchored bool // contains a slash, so it matches the path below dir rather than the base name
+	assigns  []attrAssign
+}
+
+// builtinMacros are the macros git defines itself
+var builtinMacros = map[string][]attrAssign{
+	"binary": {{name: "diff", state: attrUnset}, {name: "merge", state: attrUnset}, {name: "text", state: attrUnset}},
+}
+
+// LoadGitAttributes loads the attributes that apply below root: those of the
+// git work tree containing it, else of root itself. Nested .gitattributes are
+// read when a file below them is first looked up.
+func LoadGitAttributes(root string) (*GitAttributes, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	top := workTreeTop(absRoot)
+	prefix, err := filepath.Rel(top, absRoot)
+	if err != nil || prefix == "." {
+		prefix = ""
+	}
+
+	ga := &GitAttributes{
+		top:    top,
+		prefix: filepath.ToSlash(prefix),
+		macros: make(map[string][]attrAssign),
+		dirs:   make(map[string][]attrRule),
+	}
+	for name, assigns := range builtinMacros {
+		ga.macros[name] = assigns
+	}
+
+	rules, err := ga.loadFile(filepath.Join(top, ".gitattributes"), "", true)
+	if err != nil {
+		return nil, err
+	}
+	ga.dirs[""] = rules
+	if ga.info, err = ga.loadFile(filepath.Join(top, ".git", "info", "attributes"), "", true); err != nil {
+		return nil, err
+	}
+	return ga, nil
+}
+
+// workTreeTop returns the closest directory at or above dir holding .git,
+// or dir if there is none
+func workTreeTop(dir string) string {
+	for d := dir; ; {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			return d
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return dir
+		}
+		d = parent
+	}
+}
+
+// loadFile parses one attributes file; a missing file has no rules. Macro
+// definitions are recorded when top is set and ignored otherwise, as git does.
+func (ga *GitAttributes) loadFile(path, dir string, top bool) ([]attrRule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var rules []attrRule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		pattern, assigns := fields[0], parseAssigns(fields[1:])
+		if name, ok := strings.CutPrefix(pattern, "[attr]"); ok {
+			if top {
+				ga.macros[name] = assigns
+			}
+			continue
+		}
+		// negative patterns are forbidden in attributes files; git ignores them
+		if strings.HasPrefix(pattern, "!") {
+			continue
+		}
+		rule := attrRule{dir: dir, assigns: assigns}
+		rule.pattern, rule.anchored = strings.CutPrefix(pattern, "/")
+		rule.anchored = rule.anchored || strings.Contains(rule.pattern, "/")
+		rules = append(rules, rule)
+	}
+	return rules, scanner.Err()
+}
+
+func parseAssigns(fields []string) []attrAssign {
+	assigns := make([]attrAssign, 0, len(fields))
+	for _, f := range fields {
+		var a attrAssign
+		switch {
+		case strings.HasPrefix(f, "-"):
+			a = attrAssign{name: f[1:], state: attrUnset}
+		case strings.HasPrefix(f, "!"):
+			a = attrAssign{name: f[1:], state: attrUnspecified}
+		default:
+			name, value, ok := strings.Cut(f, "=")
+			a = attrAssign{name: name, state: attrSet}
+			if ok {
+				a = attrAssign{name: name, state: attrValue, value: value}
+			}
+		}
+		if a.name != "" {
+			assigns = append(assigns, a)
+		}
+	}
+	return assigns
+}
+
+// rulesFor returns the .gitattributes rules of dir (relative to top),
+// loading them on first use
+func (ga *GitAttributes) rulesFor(dir string) []attrRule {
+	ga.mu.Lock()
+	defer ga.mu.Unlock()
+	rules, ok := ga.dirs[dir]
+	if !ok {
+		rules, _ = ga.loadFile(filepath.Join(ga.top, filepath.FromSlash(dir), ".gitattributes"), dir, false)
+		ga.dirs[dir] = rules
+	}
+	return rules
+}
+
+// Attributes returns the attributes set on a file, by path relative to the
+// scan root; unspecified attributes are absent
+func (ga *GitAttributes) Attributes(relPath string) map[string]attrAssign {
+	p := path.Join(ga.prefix, filepath.ToSlash(relPath))
+
+	// lowest precedence first: the top-level file, then each directory down
+	// to the file's own, then .git/info/attributes
+	dirs := []string{""}
+	for d := path.Dir(p); d != "."; d = path.Dir(d) {
+		dirs = append(dirs, d)
+	}
+	slices.Reverse(dirs[1:])
+	var rules []attrRule
+	for _, d := range dirs {
+		rules = append(rules, ga.rulesFor(d)...)
+	}
+	rules = append(rules, ga.info...)
+
+	attrs := make(map[string]attrAssign)
+	for _, rule := range rules {
+		if rule.matches(p) {
+			ga.apply(attrs, rule.assigns, 0)
+		}
+	}
+	for name, a := range attrs {
+		if a.state == attrUnspecified {
+			delete(attrs, name)
+		}
+	}
+	return attrs
+}
+
+// apply records assignments in order, expanding set macros in place
+func (ga *GitAttributes) apply(attrs map[string]attrAssign, assigns []attrAssign, depth int) {
+	for _, a := range assigns {
+		attrs[a.name] = a
+		if expansion, ok := ga.macros[a.name]; ok && a.state == attrSet && depth < 8 {
+			ga.apply(attrs, expansion, depth+1)
+		}
+	}
+}
+
+// matches reports whether the rule applies to p, a path relative to top.
+// Patterns without a slash match the base name of files anywhere below the
+// rule's directory; others match the path below it, with ** spanning
+// directories. As in git, a pattern with a trailing slash matches no file.
+func (r attrRule) matches(p string) bool {
+	if r.dir != "" {
+		rest, ok := strings.CutPrefix(p, r.dir+"/")
+		if !ok {
+			return false
+		}
+		p = rest
+	}
+	if strings.HasSuffix(r.pattern, "/") {
+		return false
+	}
+	if !r.anchored {
+		ok, _ := path.Match(r.pattern, path.Base(p))
+		return ok
+	}
+	return matchSegments(strings.Split(r.pattern, "/"), strings.Split(p, "/"))
+}
+
+// matchSegments matches path segments against pattern segments, where a
+// "**" segment matches any number of directories
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			if len(pattern) == 1 {
+				return len(segments) > 0
+			}
+			for i := range len(segments) {
+				if matchSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
+
+// Linguist returns the GitHub Linguist attributes of a file, by path
+// relative to the scan root, or nil if it has none. As in Linguist, a set
+// flag or any value but "false" is true, and an unset one (-name) is false.
+func (ga *GitAttributes) Linguist(relPath string) *model.Linguist {
+	if ga == nil {
+		return nil
+	}
+	attrs := ga.Attributes(relPath)
+	var l model.Linguist
+	var found bool
+	flag := func(name string) *bool {
+		a, ok := attrs[name]
+		if !ok {
+			return nil
+		}
+		found = true
+		v := a.state == attrSet || a.state == attrValue && a.value != "false"
+		return &v
+	}
+	l.Generated = flag("linguist-generated")
+	l.Vendored = flag("linguist-vendored")
+	l.Documentation = flag("linguist-documentation")
+	l.Detectable = flag("linguist-detectable")
+	for _, name := range []string{"linguist-language", "linguist-lang"} {
+		if a, ok := attrs[name]; ok && a.state == attrValue && a.value != "" {
+			l.Language, found = LinguistLanguage(a.value), true
+			break
+		}
+	}
+	if !found {
+		return nil
+	}
+	return &l
+}
+
+// LinguistLanguage maps a Linguist language name or alias to the name aloc
+// uses: case and dashes for spaces are ignored ("objective-c", "c++"), and an
+// alias that is an extension resolves through it ("js"). Unknown names are
+// kept as given.
+func LinguistLanguage(name string) string {
+	want := strings.ToLower(name)
+	for lang := range languages {
+		l := strings.ToLower(lang)
+		if l == want || strings.ReplaceAll(l, " ", "-") == want {
+			return lang
+		}
+	}
+	if lang, ok := extToLang[want]; ok {
+		return lang
+	}
+	return name
+}
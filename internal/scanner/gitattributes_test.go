@@ -0,0 +1,135 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGitAttributes_Linguist(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".git/info/attributes": "docs/api.md -linguist-documentation\n",
+		".gitattributes": `# third-party code we ship
+[attr]thirdparty linguist-vendored -linguist-documentation
+third_party/** thirdparty
+third_party/ours/** -linguist-vendored
+*.pb.go linguist-generated=true
+api/*.gen.go linguist-generated
+docs/** linguist-documentation
+docs/ linguist-vendored
+*.inc linguist-language=php
+*.h linguist-language=Objective-C
+*.tpl linguist-language=Jinja2 -linguist-detectable
+!*.go linguist-vendored
+`,
+		"nested/.gitattributes": "*.go linguist-generated=false\n[attr]ignored linguist-vendored\nx.go ignored\n",
+	})
+
+	ga, err := LoadGitAttributes(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path                      string
+		generated, vendored, docs string // "", "true" or "false"
+		detectable, language      string
+	}{
+		{path: "main.go"},
+		{path: "third_party/lib/a.c", vendored: "true", docs: "false"},
+		{path: "third_party/ours/a.c", vendored: "false", docs: "false"},
+		{path: "proto/svc.pb.go", generated: "true"},
+		{path: "api/types.gen.go", generated: "true"},
+		{path: "api/v1/types.gen.go"}, // * does not cross directories
+		{path: "docs/guide/intro.md", docs: "true"},
+		{path: "docs/api.md", docs: "false"}, // .git/info/attributes wins
+		{path: "lib/util.inc", language: "PHP"},
+		{path: "include/view.h", language: "Objective-C"},
+		{path: "web/page.tpl", detectable: "false", language: "Jinja2"},
+		{path: "nested/svc.pb.go", generated: "false"}, // the closer file wins
+		{path: "nested/x.go", generated: "false"},      // macros only at the top level
+	}
+	flag := func(b *bool) string {
+		if b == nil {
+			return ""
+		}
+		if *b {
+			return "true"
+		}
+		return "false"
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			l := ga.Linguist(filepath.FromSlash(tt.path))
+			if tt.generated == "" && tt.vendored == "" && tt.docs == "" && tt.detectable == "" && tt.language == "" {
+				if l != nil {
+					t.Errorf("Linguist() = %+v, want nil", l)
+				}
+				return
+			}
+			if l == nil {
+				t.Fatal("Linguist() = nil")
+			}
+			got := [5]string{flag(l.Generated), flag(l.Vendored), flag(l.Documentation), flag(l.Detectable), l.Language}
+			want := [5]string{tt.generated, tt.vendored, tt.docs, tt.detectable, tt.language}
+			if got != want {
+				t.Errorf("generated, vendored, documentation, detectable, language = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestGitAttributes_ScanRootBelowWorkTree(t *testing.T) {
+	top := t.TempDir()
+	writeFiles(t, top, map[string]string{
+		".git/HEAD":      "ref: refs/heads/main\n",
+		".gitattributes": "/svc/gen/** linguist-generated\n",
+		"svc/gen/a.go":   "package gen\n",
+	})
+
+	ga, err := LoadGitAttributes(filepath.Join(top, "svc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := ga.Linguist(filepath.Join("gen", "a.go")); l == nil || l.Generated == nil || !*l.Generated {
+		t.Errorf("Linguist(gen/a.go) = %+v, want generated from the work tree's .gitattributes", l)
+	}
+}
+
+func TestScan_LinguistLanguage(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".gitattributes": "*.txt linguist-language=Python\nvendor.js linguist-vendored\n",
+		"script.txt":     "# comment\nprint(1)\n",
+		"vendor.js":      "x = 1\n",
+	})
+
+	files, _ := scanAll(t, root, Options{NumWorkers: 1})
+	byPath := make(map[string]string)
+	for _, f := range files {
+		byPath[f.Path] = f.LanguageHint
+		if f.Path == "vendor.js" && (f.Linguist == nil || !*f.Linguist.Vendored) {
+			t.Errorf("vendor.js Linguist = %+v, want vendored", f.Linguist)
+		}
+	}
+	if byPath["script.txt"] != "Python" {
+		t.Errorf("script.txt language = %q, want Python from linguist-language", byPath["script.txt"])
+	}
+	if got := DetectLanguage(filepath.Join(root, "script.txt")); got != "Plain Text" {
+		t.Errorf("DetectLanguage(script.txt) = %q, want Plain Text: attributes apply through the scan", got)
+	}
+}
@@ -0,0 +1,155 @@
+package scanner
+
+import "bytes"
+
+// inlineMode selects how inline test code is recognized for a language
+type inlineMode int
+
+const (
+	inlineNone   inlineMode = iota
+	inlineRust              // #[cfg(test)] items, usually `mod tests { ... }`
+	inlineD                 // unittest { ... } blocks
+	inlineZig               // test "name" { ... } blocks
+	inlinePython            // doctest prompts (>>> and ... lines) in docstrings
+)
+
+// inlineTestModes lists the languages whose source files can embed tests
+var inlineTestModes = map[string]inlineMode{
+	"Rust":   inlineRust,
+	"D":      inlineD,
+	"Zig":    inlineZig,
+	"Python": inlinePython,
+}
+
+// inlineTestDetector finds code lines that belong to tests embedded in a
+// source file. Blocks are tracked by brace depth, skipping strings, character
+// literals and line comments; like logicalCounter it is a heuristic, not a
+// parser.
+type inlineTestDetector struct {
+	mode    inlineMode
+	pending bool // a test marker was seen; its block has not opened yet
+	depth   int  // brace depth inside the test block, 0 outside
+	prompt  bool // previous line was a doctest prompt
+}
+
+// newInlineTestDetector returns nil for languages without inline tests
+func newInlineTestDetector(lang string) *inlineTestDetector {
+	mode := inlineTestModes[lang]
+	if mode == inlineNone {
+		return nil
+	}
+	return &inlineTestDetector{mode: mode}
+}
+
+// isTest reports whether a line (already classified as code) is test code
+func (d *inlineTestDetector) isTest(line []byte) bool {
+	trimmed := bytes.TrimSpace(line)
+	if d.mode == inlinePython {
+		return d.isDoctest(trimmed)
+	}
+
+	if d.depth == 0 && !d.pending {
+		rest, ok := d.marker(trimmed)
+		if !ok {
+			return false
+		}
+		d.pending = true
+		trimmed = rest
+	}
+	d.scan(trimmed)
+	return true
+}
+
+// marker reports whether trimmed starts a test item, returning the rest of
+// the line after the marker
+func (d *inlineTestDetector) marker(trimmed []byte) ([]byte, bool) {
+	switch d.mode {
+	case inlineRust:
+		if bytes.HasPrefix(trimmed, []byte("#[cfg(test)]")) {
+			return trimmed[len("#[cfg(test)]"):], true
+		}
+	case inlineD:
+		// unittest may follow attributes: @safe unittest { ... }
+		for rest := trimmed; len(rest) > 0; {
+			if hasKeyword(rest, "unittest") {
+				return rest[len("unittest"):], true
+			}
+			if rest[0] != '@' && !isIdentByte(rest[0]) {
+				break
+			}
+			space := bytes.IndexByte(rest, ' ')
+			if space < 0 {
+				break
+			}
+			rest = bytes.TrimSpace(rest[space:])
+		}
+	case inlineZig:
+		if hasKeyword(trimmed, "test") {
+			rest := bytes.TrimSpace(trimmed[len("test"):])
+			// test "name" { / test identifier { / test {
+			if len(rest) > 0 && (rest[0] == '"' || rest[0] == '{' || isIdentByte(rest[0])) {
+				return rest, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// scan updates brace depth for one line of a test item
+func (d *inlineTestDetector) scan(line []byte) {
+	for i := 0; i < len(line); i++ {
+		switch b := line[i]; b {
+		case '/':
+			if i+1 < len(line) && line[i+1] == '/' {
+				return
+			}
+		case '"':
+			for i++; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' {
+					i++
+				}
+			}
+		case '\'':
+			// character literals like '{' or '\''; Rust lifetimes ('a) are left alone
+			if i+2 < len(line) && line[i+2] == '\'' {
+				i += 2
+			} else if i+3 < len(line) && line[i+1] == '\\' && line[i+3] == '\'' {
+				i += 3
+			}
+		case '{':
+			d.depth++
+			d.pending = false
+		case '}':
+			if d.depth > 0 {
+				d.depth--
+			}
+		case ';':
+			// an item without a body, e.g. #[cfg(test)] use super::*;
+			if d.pending && d.depth == 0 {
+				d.pending = false
+			}
+		}
+	}
+}
+
+// isDoctest reports whether a docstring line is a doctest prompt or a
+// continuation of one; expected output lines are not counted
+func (d *inlineTestDetector) isDoctest(trimmed []byte) bool {
+	switch {
+	case bytes.HasPrefix(trimmed, []byte(">>>")):
+		d.prompt = true
+	case d.prompt && bytes.HasPrefix(trimmed, []byte("...")):
+	default:
+		d.prompt = false
+	}
+	return d.prompt
+}
+
+// hasKeyword reports whether s starts with keyword followed by a non-identifier byte
+func hasKeyword(s []byte, keyword string) bool {
+	return bytes.HasPrefix(s, []byte(keyword)) && (len(s) == len(keyword) || !isIdentByte(s[len(keyword)]))
+}
+
+func isIdentByte(b byte) bool {
+	return b == '_' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9'
+}
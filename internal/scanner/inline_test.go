@@ -0,0 +1,93 @@
+package scanner
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestInlineTests(t *testing.T) {
+	tests := []struct {
+		name string
+		lang string
+		src  string
+		want int
+	}{
+		{
+			name: "rust cfg(test) module",
+			lang: "Rust",
+			src: `pub fn add(a: i32, b: i32) -> i32 {
+    a + b
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    // braces in comments and strings are ignored: {
+    #[test]
+    fn adds() {
+        assert_eq!(add(1, 2), 3, "{}", '{');
+    }
+}
+
+pub fn sub(a: i32, b: i32) -> i32 { a - b }
+`,
+			want: 8,
+		},
+		{
+			name: "rust attribute on a bodiless item",
+			lang: "Rust",
+			src:  "#[cfg(test)]\nuse std::fs;\nfn main() {}\n",
+			want: 2,
+		},
+		{
+			name: "d unittest blocks",
+			lang: "D",
+			src:  "int twice(int x) { return x * 2; }\n\n@safe unittest\n{\n    assert(twice(2) == 4);\n}\n",
+			want: 4,
+		},
+		{
+			name: "zig test blocks",
+			lang: "Zig",
+			src:  "fn twice(x: i32) i32 {\n    return x * 2;\n}\n\ntest \"twice\" {\n    try expect(twice(2) == 4);\n}\n",
+			want: 3,
+		},
+		{
+			name: "python doctests",
+			lang: "Python",
+			src: `def twice(x):
+    """Double x.
+
+    >>> twice(2)
+    4
+    >>> [twice(i)
+    ...  for i in range(2)]
+    [0, 2]
+    """
+    return x * 2
+`,
+			want: 3,
+		},
+		{
+			name: "go has no inline tests",
+			lang: "Go",
+			src:  "package main\n\n// >>> not a doctest\nfunc main() {}\n",
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := countLinesFromReader(bufio.NewReader(strings.NewReader(tt.src)), tt.lang)
+			if err != nil {
+				t.Fatalf("countLinesFromReader failed: %v", err)
+			}
+			if m.InlineTests != tt.want {
+				t.Errorf("InlineTests = %d, want %d", m.InlineTests, tt.want)
+			}
+			if m.InlineTests > m.Code {
+				t.Errorf("InlineTests = %d exceeds Code = %d", m.InlineTests, m.Code)
+			}
+		})
+	}
+}
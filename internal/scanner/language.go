@@ -2,8 +2,10 @@ package scanner
 
 import (
 	"bufio"
+	"bytes"
 	_ "embed"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -105,8 +107,26 @@ func extractInterpreter(shebang string) string {
 	return filepath.Base(shebang)
 }
 
-// DetectLanguage detects the programming language from a file path
+// DetectLanguage detects the programming language from a file path. It
+// does not read .gitattributes: a scan applies linguist-language through
+// its GitAttributes, and other callers can look it up with
+// GitAttributes.Linguist.
 func DetectLanguage(path string) string {
+	return detectLanguage(path, func() string { return firstLine(path) })
+}
+
+// detectLanguageFromHead is DetectLanguage with the shebang taken from the
+// file's leading bytes, which the caller has already read
+func detectLanguageFromHead(path string, head []byte) string {
+	return detectLanguage(path, func() string {
+		line, _, _ := bytes.Cut(head, []byte("\n"))
+		return string(line)
+	})
+}
+
+// detectLanguage checks the file name, then the extension, then for files
+// without an extension the shebang in the line returned by first
+func detectLanguage(path string, first func() string) string {
 	// check special filenames first
 	base := filepath.Base(path)
 	if lang, ok := filenameToLang[strings.ToLower(base)]; ok {
@@ -121,7 +141,7 @@ func DetectLanguage(path string) string {
 
 	// check shebang for files without extension
 	if ext == "" || filepath.Ext(path) == "" {
-		if lang := detectFromShebang(path); lang != "" {
+		if lang := shebangLanguage(first()); lang != "" {
 			return lang
 		}
 	}
@@ -129,7 +149,8 @@ func DetectLanguage(path string) string {
 	return "unknown"
 }
 
-func detectFromShebang(path string) string {
+// firstLine returns the first line of the file at path, or "" if unreadable
+func firstLine(path string) string {
 	f, err := os.Open(path)
 	if err != nil {
 		return ""
@@ -138,27 +159,30 @@ func detectFromShebang(path string) string {
 
 	scanner := bufio.NewScanner(f)
 	if scanner.Scan() {
-		line := scanner.Text()
-		if strings.HasPrefix(line, "#!") {
-			shebang := strings.TrimPrefix(line, "#!")
-			shebang = strings.TrimSpace(shebang)
-
-			// handle /usr/bin/env
-			if strings.Contains(shebang, "env ") {
-				parts := strings.Fields(shebang)
-				if len(parts) >= 2 {
-					shebang = parts[len(parts)-1]
-				}
-			}
+		return scanner.Text()
+	}
+	return ""
+}
 
-			// extract interpreter name
-			shebang = filepath.Base(shebang)
-			if lang, ok := shebangToLang[shebang]; ok {
-				return lang
-			}
+// shebangLanguage returns the language of the interpreter named by a
+// shebang line, or "" if line is not one or the interpreter is unknown
+func shebangLanguage(line string) string {
+	if !strings.HasPrefix(line, "#!") {
+		return ""
+	}
+	shebang := strings.TrimPrefix(line, "#!")
+	shebang = strings.TrimSpace(shebang)
+
+	// handle /usr/bin/env
+	if strings.Contains(shebang, "env ") {
+		parts := strings.Fields(shebang)
+		if len(parts) >= 2 {
+			shebang = parts[len(parts)-1]
 		}
 	}
-	return ""
+
+	// extract interpreter name
+	return shebangToLang[filepath.Base(shebang)]
 }
 
 func extToLanguage(ext string) string {
@@ -178,3 +202,60 @@ func GetLanguageConfig(lang string) (LanguageConfig, bool) {
 func GetAllLanguages() map[string]LanguageConfig {
 	return languages
 }
+
+// RegisterLanguage adds a language or, if one with the same name exists,
+// overrides the fields that are set in cfg. Extensions and filenames are
+// (re)mapped to the language and extensions are accepted by quick mode.
+// Comment markers given for a language without comments (blank in
+// languages.json, such as JSON) give it comments. It must be called before
+// scanning starts.
+func RegisterLanguage(cfg LanguageConfig) error {
+	if cfg.Name == "" {
+		return fmt.Errorf("language name is required")
+	}
+	if cfg.Category != "" {
+		if _, ok := categoryFromString[cfg.Category]; !ok {
+			return fmt.Errorf("language %q: unknown category %q (want primary, web, infra, data, docs or other)",
+				cfg.Name, cfg.Category)
+		}
+	}
+	for _, pair := range cfg.MultiLineComments {
+		if len(pair) != 2 {
+			return fmt.Errorf("language %q: block comments need a start and end marker, got %v", cfg.Name, pair)
+		}
+	}
+
+	merged, ok := languages[cfg.Name]
+	if !ok {
+		merged = LanguageConfig{Name: cfg.Name, Category: "other"}
+	}
+	if cfg.LineComment != nil {
+		merged.LineComment = cfg.LineComment
+		merged.Blank = false
+	}
+	if cfg.MultiLineComments != nil {
+		merged.MultiLineComments = cfg.MultiLineComments
+		merged.Blank = false
+	}
+	if cfg.Category != "" {
+		merged.Category = cfg.Category
+	}
+
+	for _, ext := range cfg.Extensions {
+		ext = strings.ToLower(strings.TrimPrefix(ext, "."))
+		if ext == "" {
+			continue
+		}
+		merged.Extensions = append(merged.Extensions, ext)
+		extToLang[ext] = cfg.Name
+		knownSourceExtensions["."+ext] = true
+	}
+	for _, fname := range cfg.Filenames {
+		merged.Filenames = append(merged.Filenames, fname)
+		filenameToLang[strings.ToLower(fname)] = cfg.Name
+		knownSourceFilenames[strings.ToLower(fname)] = true
+	}
+
+	languages[cfg.Name] = merged
+	return nil
+}
@@ -1,6 +1,10 @@
 package scanner
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
 
 func TestDetectLanguage_Extensions(t *testing.T) {
 	tests := []struct {
@@ -73,3 +77,99 @@ func TestExtToLanguage(t *testing.T) {
 		t.Error("extToLanguage(xyz) should return unknown")
 	}
 }
+
+func TestRegisterLanguage_UserDefined(t *testing.T) {
+	err := RegisterLanguage(LanguageConfig{
+		Name:        "Pipeline DSL",
+		Extensions:  []string{".pipeline", "rules"},
+		Filenames:   []string{"Pipelinefile"},
+		LineComment: []string{"--"},
+		Category:    "infra",
+	})
+	if err != nil {
+		t.Fatalf("RegisterLanguage failed: %v", err)
+	}
+
+	for _, path := range []string{"build.pipeline", "deploy.RULES", "ci/Pipelinefile"} {
+		if got := DetectLanguage(path); got != "Pipeline DSL" {
+			t.Errorf("DetectLanguage(%q) = %q, want Pipeline DSL", path, got)
+		}
+	}
+	if got := GetLanguageCategory("Pipeline DSL"); got != CategoryInfra {
+		t.Errorf("GetLanguageCategory = %q, want %q", got, CategoryInfra)
+	}
+	if !isKnownSourceExtension(".rules") || !knownSourceFilenames["pipelinefile"] {
+		t.Error("user-defined extensions and filenames should pass quick-mode filtering")
+	}
+
+	// comment markers are used for counting
+	root := t.TempDir()
+	path := filepath.Join(root, "build.pipeline")
+	os.WriteFile(path, []byte("-- stages\nstage build\n"), 0644)
+	got, err := CountLines(path)
+	if err != nil {
+		t.Fatalf("CountLines failed: %v", err)
+	}
+	if got.Comments != 1 || got.Code != 1 {
+		t.Errorf("CountLines = %+v, want 1 comment and 1 code line", got)
+	}
+}
+
+func TestRegisterLanguage_OverridesBuiltin(t *testing.T) {
+	before, _ := GetLanguageConfig("Go")
+	defer func() { languages["Go"] = before }()
+
+	if err := RegisterLanguage(LanguageConfig{Name: "Go", Category: "web"}); err != nil {
+		t.Fatalf("RegisterLanguage failed: %v", err)
+	}
+
+	cfg, _ := GetLanguageConfig("Go")
+	if cfg.Category != "web" {
+		t.Errorf("Category = %q, want web", cfg.Category)
+	}
+	// unset fields keep their built-in values
+	if len(cfg.LineComment) == 0 || cfg.LineComment[0] != "//" {
+		t.Errorf("LineComment = %v, want built-in //", cfg.LineComment)
+	}
+}
+
+func TestRegisterLanguage_CommentsForBlankLanguage(t *testing.T) {
+	before, _ := GetLanguageConfig("JSON")
+	defer func() { languages["JSON"] = before }()
+	if !before.Blank {
+		t.Fatal("JSON should have no comments built in")
+	}
+
+	if err := RegisterLanguage(LanguageConfig{Name: "JSON", LineComment: []string{"//"}}); err != nil {
+		t.Fatalf("RegisterLanguage failed: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "settings.json")
+	os.WriteFile(path, []byte("// editor settings\n{\"tabSize\": 2}\n"), 0644)
+	got, err := CountLines(path)
+	if err != nil {
+		t.Fatalf("CountLines failed: %v", err)
+	}
+	if got.Comments != 1 || got.Code != 1 {
+		t.Errorf("CountLines = %+v, want the // line counted as a comment", got)
+	}
+}
+
+func TestRegisterLanguage_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  LanguageConfig
+	}{
+		{"missing name", LanguageConfig{Extensions: []string{"x1"}}},
+		{"unknown category", LanguageConfig{Name: "X1", Category: "backend"}},
+		{"bad block comment", LanguageConfig{Name: "X1", MultiLineComments: [][]string{{"/*"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := RegisterLanguage(tt.cfg); err == nil {
+				t.Error("RegisterLanguage should fail")
+			}
+		})
+	}
+}
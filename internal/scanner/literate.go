@@ -0,0 +1,211 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+// embeddedCounter counts a literate file, returning metrics for the whole file
+// and for the code embedded in it, keyed by host language
+type embeddedCounter func(br *bufio.Reader) (model.LineMetrics, map[string]model.LineMetrics, error)
+
+// literateCounters maps literate languages (languages.json "literate": true)
+// to the counter for their code block syntax
+var literateCounters = map[string]embeddedCounter{
+	"Markdown":              countMarkdownWithEmbedded,
+	"MDX":                   countMarkdownWithEmbedded,
+	"R Markdown":            countMarkdownWithEmbedded, // ```{r} chunks
+	"Quarto":                countMarkdownWithEmbedded, // ```{python} chunks
+	"Org":                   countOrgWithEmbedded,
+	"Literate Haskell":      countLiterateHaskell,
+	"Literate CoffeeScript": countLiterateCoffeeScript,
+}
+
+// HasEmbeddedCode reports whether lang is a literate language whose code
+// blocks are counted as embedded code in their host language
+func HasEmbeddedCode(lang string) bool {
+	cfg, ok := GetLanguageConfig(lang)
+	return ok && cfg.Literate && literateCounters[lang] != nil
+}
+
+// addEmbedded accumulates block metrics for an embedded language
+func addEmbedded(embedded map[string]model.LineMetrics, lang string, m model.LineMetrics) {
+	if lang == "" || m.Total == 0 {
+		return
+	}
+	existing := embedded[lang]
+	existing.Total += m.Total
+	existing.Code += m.Code
+	existing.Comments += m.Comments
+	existing.Blanks += m.Blanks
+	embedded[lang] = existing
+}
+
+// nilIfEmpty keeps files without code blocks free of an empty embedded map
+func nilIfEmpty(embedded map[string]model.LineMetrics) map[string]model.LineMetrics {
+	if len(embedded) == 0 {
+		return nil
+	}
+	return embedded
+}
+
+// In every literate file, code is counted as embedded code in its host
+// language and non-blank prose lines are LOC recorded in LineMetrics.Prose,
+// which inference attributes to docs. Literate source files (.lhs,
+// .litcoffee) classify their code lines with the host language's rules;
+// literate documents (Org, R Markdown, Quarto) are counted like Markdown.
+
+var (
+	lhsBeginCode = []byte(`\begin{code}`)
+	lhsEndCode   = []byte(`\end{code}`)
+)
+
+// countLiterateHaskell counts both .lhs styles: bird tracks ("> " code lines)
+// and LaTeX \begin{code} ... \end{code} blocks
+func countLiterateHaskell(br *bufio.Reader) (model.LineMetrics, map[string]model.LineMetrics, error) {
+	var prose, code model.LineMetrics
+	host := newLineClassifier("Haskell")
+	inCode := false
+
+	err := forEachLine(br, func(line []byte) {
+		trimmed := bytes.TrimSpace(line)
+		switch {
+		case inCode && bytes.HasPrefix(trimmed, lhsEndCode):
+			inCode = false
+			addProse(&prose, trimmed)
+		case inCode:
+			host.add(&code, line)
+		case bytes.HasPrefix(trimmed, lhsBeginCode):
+			inCode = true
+			addProse(&prose, trimmed)
+		case len(line) > 0 && line[0] == '>':
+			host.add(&code, line[1:])
+		default:
+			addProse(&prose, trimmed)
+		}
+	})
+
+	return mergeLiterate(prose, code), literateEmbedded("Haskell", code), err
+}
+
+// countLiterateCoffeeScript counts Markdown where indented blocks (four
+// spaces or a tab, not interrupting a paragraph) are CoffeeScript
+func countLiterateCoffeeScript(br *bufio.Reader) (model.LineMetrics, map[string]model.LineMetrics, error) {
+	var prose, code model.LineMetrics
+	host := newLineClassifier("CoffeeScript")
+	inCode, prevBlank := false, true
+
+	err := forEachLine(br, func(line []byte) {
+		trimmed := bytes.TrimSpace(line)
+		if len(trimmed) == 0 {
+			prose.Total++
+			prose.Blanks++
+			prevBlank = true
+			return
+		}
+
+		indented := bytes.HasPrefix(line, []byte("    ")) || line[0] == '\t'
+		if indented && (inCode || prevBlank) {
+			inCode = true
+			host.add(&code, line)
+		} else {
+			inCode = false
+			addProse(&prose, trimmed)
+		}
+		prevBlank = false
+	})
+
+	return mergeLiterate(prose, code), literateEmbedded("CoffeeScript", code), err
+}
+
+// addProse records a prose line of a literate source file
+func addProse(m *model.LineMetrics, trimmed []byte) {
+	m.Total++
+	if len(trimmed) == 0 {
+		m.Blanks++
+	} else {
+		m.Code++
+		m.Prose++
+	}
+}
+
+// mergeLiterate combines prose and classified code into whole-file metrics
+func mergeLiterate(prose, code model.LineMetrics) model.LineMetrics {
+	return model.LineMetrics{
+		Total:    prose.Total + code.Total,
+		Blanks:   prose.Blanks + code.Blanks,
+		Comments: prose.Comments + code.Comments,
+		Code:     prose.Code + code.Code,
+		Prose:    prose.Prose,
+	}
+}
+
+func literateEmbedded(host string, code model.LineMetrics) map[string]model.LineMetrics {
+	embedded := make(map[string]model.LineMetrics)
+	addEmbedded(embedded, host, code)
+	return nilIfEmpty(embedded)
+}
+
+var (
+	orgBeginSrc = []byte("#+begin_src")
+	orgEndSrc   = []byte("#+end_src")
+)
+
+// countOrgWithEmbedded counts Org-mode documents, extracting babel source
+// blocks (#+BEGIN_SRC lang ... #+END_SRC) as embedded code
+func countOrgWithEmbedded(br *bufio.Reader) (model.LineMetrics, map[string]model.LineMetrics, error) {
+	var metrics model.LineMetrics
+	embedded := make(map[string]model.LineMetrics)
+
+	inBlock := false
+	blockLang := ""
+	var block *lineClassifier
+	var blockMetrics model.LineMetrics
+
+	err := forEachLine(br, func(line []byte) {
+		metrics.Total++
+		trimmed := bytes.TrimSpace(line)
+
+		if inBlock {
+			if hasPrefixFold(trimmed, orgEndSrc) {
+				inBlock = false
+				addEmbedded(embedded, blockLang, blockMetrics)
+			} else if block != nil {
+				block.add(&blockMetrics, line)
+			}
+			metrics.Code++ // source blocks count as document lines, like Markdown fences
+			return
+		}
+
+		switch {
+		case hasPrefixFold(trimmed, orgBeginSrc):
+			inBlock = true
+			blockLang = ""
+			if fields := bytes.Fields(trimmed[len(orgBeginSrc):]); len(fields) > 0 {
+				blockLang = normalizeCodeBlockLang(string(fields[0]))
+			}
+			block = nil
+			if blockLang != "" {
+				block = newLineClassifier(blockLang)
+			}
+			blockMetrics = model.LineMetrics{}
+			metrics.Code++
+		case len(trimmed) == 0:
+			metrics.Blanks++
+		case trimmed[0] == '#' && (len(trimmed) == 1 || trimmed[1] == ' '):
+			metrics.Comments++ // "# comment" lines
+		default:
+			metrics.Code++ // prose, headings and keywords
+			metrics.Prose++
+		}
+	})
+
+	return metrics, nilIfEmpty(embedded), err
+}
+
+// hasPrefixFold is a case-insensitive bytes.HasPrefix for ASCII prefixes
+func hasPrefixFold(s, prefix []byte) bool {
+	return len(s) >= len(prefix) && bytes.EqualFold(s[:len(prefix)], prefix)
+}
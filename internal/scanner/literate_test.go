@@ -0,0 +1,132 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+func TestCountLinesWithEmbedded_Literate(t *testing.T) {
+	tests := []struct {
+		name         string
+		filename     string
+		content      string
+		want         model.LineMetrics
+		wantEmbedded map[string]model.LineMetrics
+	}{
+		{
+			name:     "literate haskell bird tracks",
+			filename: "Main.lhs",
+			content:  "Intro prose\n\n> module Main where\n> main = print 1\n> -- comment\n\nMore prose\n",
+			want:     model.LineMetrics{Total: 7, Blanks: 2, Comments: 1, Code: 4, Prose: 2},
+			wantEmbedded: map[string]model.LineMetrics{
+				"Haskell": {Total: 3, Comments: 1, Code: 2},
+			},
+		},
+		{
+			name:     "literate haskell latex blocks",
+			filename: "Main.lhs",
+			content:  "\\section{Main}\n\\begin{code}\nmain :: IO ()\nmain = pure ()\n\\end{code}\n",
+			want:     model.LineMetrics{Total: 5, Code: 5, Prose: 3},
+			wantEmbedded: map[string]model.LineMetrics{
+				"Haskell": {Total: 2, Code: 2},
+			},
+		},
+		{
+			name:     "literate coffeescript indented blocks",
+			filename: "square.litcoffee",
+			content:  "# Square\nSome text\n    continued paragraph\n\n    square = (x) -> x * x\n    # explained\n",
+			want:     model.LineMetrics{Total: 6, Blanks: 1, Comments: 1, Code: 4, Prose: 3},
+			wantEmbedded: map[string]model.LineMetrics{
+				"CoffeeScript": {Total: 2, Comments: 1, Code: 1},
+			},
+		},
+		{
+			name:     "org babel blocks",
+			filename: "notes.org",
+			content:  "* Heading\n# note\n#+BEGIN_SRC python :results output\nx = 1\n# c\n#+END_SRC\n",
+			want:     model.LineMetrics{Total: 6, Comments: 1, Code: 5, Prose: 1},
+			wantEmbedded: map[string]model.LineMetrics{
+				"Python": {Total: 2, Comments: 1, Code: 1},
+			},
+		},
+		{
+			name:     "r markdown chunk options",
+			filename: "report.Rmd",
+			content:  "# Report\n\n```{r setup, echo=FALSE}\nx <- 1\n```\n",
+			want:     model.LineMetrics{Total: 5, Blanks: 1, Code: 4, Prose: 1},
+			wantEmbedded: map[string]model.LineMetrics{
+				"R": {Total: 1, Code: 1},
+			},
+		},
+		{
+			name:         "quarto python chunk",
+			filename:     "analysis.qmd",
+			content:      "```{python}\nprint(1)\n```\n",
+			want:         model.LineMetrics{Total: 3, Code: 3},
+			wantEmbedded: map[string]model.LineMetrics{"Python": {Total: 1, Code: 1}},
+		},
+		{
+			name:     "prose only",
+			filename: "empty.lhs",
+			content:  "Nothing to run here.\n",
+			want:     model.LineMetrics{Total: 1, Code: 1, Prose: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.filename)
+			os.WriteFile(path, []byte(tt.content), 0644)
+
+			if lang := DetectLanguage(path); !HasEmbeddedCode(lang) {
+				t.Fatalf("HasEmbeddedCode(%q) = false, want true", lang)
+			}
+
+			got, embedded, err := CountLinesWithEmbedded(path)
+			if err != nil {
+				t.Fatalf("CountLinesWithEmbedded failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("metrics = %+v, want %+v", got, tt.want)
+			}
+			if len(embedded) != len(tt.wantEmbedded) {
+				t.Fatalf("embedded = %+v, want %+v", embedded, tt.wantEmbedded)
+			}
+			for lang, want := range tt.wantEmbedded {
+				if embedded[lang] != want {
+					t.Errorf("embedded[%s] = %+v, want %+v", lang, embedded[lang], want)
+				}
+			}
+		})
+	}
+}
+
+func TestHasEmbeddedCode_NonLiterate(t *testing.T) {
+	for _, lang := range []string{"Go", "Plain Text", "unknown"} {
+		if HasEmbeddedCode(lang) {
+			t.Errorf("HasEmbeddedCode(%q) = true, want false", lang)
+		}
+	}
+}
+
+func TestNormalizeCodeBlockLang(t *testing.T) {
+	tests := []struct {
+		hint string
+		want string
+	}{
+		{"", ""},
+		{"{}", ""},
+		{"go", "Go"},
+		{"typescript jsx", "TypeScript"},
+		{"{r setup, echo=FALSE}", "R"},
+		{"{python}", "Python"},
+	}
+	for _, tt := range tests {
+		if got := normalizeCodeBlockLang(tt.hint); got != tt.want {
+			t.Errorf("normalizeCodeBlockLang(%q) = %q, want %q", tt.hint, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package scanner
+
+import "bytes"
+
+// logicalMode selects how statement boundaries are found for a language
+type logicalMode int
+
+const (
+	logicalNone      logicalMode = iota
+	logicalSemicolon             // statements end at ';' or a brace; newlines are insignificant
+	logicalNewline               // newlines end statements unless the line or the next one continues it
+	logicalPython                // newlines end statements outside brackets; '\' continues
+)
+
+// logicalModes lists the languages with logical line support
+var logicalModes = map[string]logicalMode{
+	"C":           logicalSemicolon,
+	"C Header":    logicalSemicolon,
+	"C++":         logicalSemicolon,
+	"C++ Header":  logicalSemicolon,
+	"C#":          logicalSemicolon,
+	"Java":        logicalSemicolon,
+	"Objective-C": logicalSemicolon,
+	"Go":          logicalNewline,
+	"JavaScript":  logicalNewline,
+	"JSX":         logicalNewline,
+	"TypeScript":  logicalNewline,
+	"TSX":         logicalNewline,
+	"Python":      logicalPython,
+}
+
+// SupportsLogical reports whether logical line counting is implemented for lang
+func SupportsLogical(lang string) bool {
+	return logicalModes[lang] != logicalNone
+}
+
+// logicalCounter counts logical lines (statements) independent of how they
+// are wrapped across physical lines. Brackets collapse multi-line expressions,
+// braces delimit statements, and comments and string contents are skipped.
+// It is a tokenizer-free heuristic: regex literals and macros are not parsed.
+type logicalCounter struct {
+	mode           logicalMode
+	leadingDots    bool   // a line starting with '.', '?', '&&', ... continues the previous one
+	stack          []byte // open '(', '[' and '{'
+	inBlockComment bool
+	quote          byte // quote of an open string, 0 if none
+	triple         bool // open string is a Python triple-quoted string
+	pending        bool // code seen since the last statement boundary
+	deferred       bool // a newline ended a statement that the next line may still continue
+	resumed        bool // rest of a statement whose block argument just closed
+	last, prev     byte // last two significant bytes of the current statement
+	count          int
+}
+
+// newLogicalCounter returns nil for languages without logical line support
+func newLogicalCounter(lang string) *logicalCounter {
+	mode := logicalModes[lang]
+	if mode == logicalNone {
+		return nil
+	}
+	return &logicalCounter{
+		mode:        mode,
+		leadingDots: lang != "Go", // gofmt keeps operators and dots trailing
+	}
+}
+
+// inStatement reports whether the innermost open delimiter is a block (or
+// none), so that terminators end statements rather than sub-expressions
+func (c *logicalCounter) inStatement() bool {
+	if len(c.stack) == 0 {
+		return true
+	}
+	return c.mode != logicalPython && c.stack[len(c.stack)-1] == '{'
+}
+
+// opensBlock reports whether a '{' starts a statement block rather than an
+// object or composite literal: always at statement level, and inside
+// brackets only after a parameter list or arrow (callbacks, func literals)
+func (c *logicalCounter) opensBlock() bool {
+	return c.inStatement() || c.last == ')' || c.last == '>'
+}
+
+// boundary ends the current statement if it contains any code
+func (c *logicalCounter) boundary() {
+	if c.pending {
+		c.count++
+	}
+	c.pending = false
+	c.deferred = false
+	c.resumed = false
+	c.last, c.prev = 0, 0
+}
+
+func (c *logicalCounter) pop(open byte) {
+	for n := len(c.stack); n > 0; n-- {
+		top := c.stack[n-1]
+		c.stack = c.stack[:n-1]
+		if top == open {
+			return
+		}
+	}
+}
+
+func (c *logicalCounter) code(b byte) {
+	c.pending = !c.resumed
+	c.prev, c.last = c.last, b
+}
+
+// feed processes one physical line
+func (c *logicalCounter) feed(line []byte) {
+	first := true
+	for i := 0; i < len(line); {
+		if c.inBlockComment {
+			idx := bytes.Index(line[i:], []byte("*/"))
+			if idx < 0 {
+				break
+			}
+			c.inBlockComment = false
+			i += idx + 2
+			continue
+		}
+		if c.quote != 0 {
+			i = c.scanString(line, i)
+			continue
+		}
+
+		b := line[i]
+		if b == ' ' || b == '\t' || b == '\r' || b == '\f' {
+			i++
+			continue
+		}
+
+		// comments
+		if c.mode == logicalPython {
+			if b == '#' {
+				break
+			}
+		} else if b == '/' && i+1 < len(line) {
+			if line[i+1] == '/' {
+				break
+			}
+			if line[i+1] == '*' {
+				c.inBlockComment = true
+				i += 2
+				continue
+			}
+		}
+
+		if first {
+			first = false
+			if c.deferred {
+				if c.leadingDots && startsContinuation(line[i:]) {
+					c.deferred = false
+				} else {
+					c.boundary()
+				}
+			}
+			// preprocessor directives are one statement per line
+			if b == '#' && c.mode == logicalSemicolon {
+				c.boundary()
+				c.count++
+				return
+			}
+		}
+
+		switch b {
+		case '"', '\'', '`':
+			c.code(b)
+			c.quote = b
+			c.triple = c.mode == logicalPython && i+2 < len(line) && line[i+1] == b && line[i+2] == b
+			if c.triple {
+				i += 2
+			}
+			i = c.scanString(line, i+1)
+			continue
+		case '(', '[':
+			c.code(b)
+			c.stack = append(c.stack, b)
+		case ')':
+			c.code(b)
+			c.pop('(')
+		case ']':
+			c.code(b)
+			c.pop('[')
+		case '{':
+			if c.mode == logicalPython || !c.opensBlock() {
+				c.code(b)
+				c.stack = append(c.stack, '(') // literal braces nest like brackets
+				break
+			}
+			c.boundary()
+			c.stack = append(c.stack, b)
+		case '}':
+			if n := len(c.stack); n > 0 && c.stack[n-1] != '{' {
+				c.code(b)
+				c.pop('(')
+				break
+			}
+			c.boundary()
+			c.pop('{')
+			// the enclosing call was counted when the block opened
+			c.resumed = !c.inStatement()
+		case ';':
+			if c.inStatement() {
+				c.boundary()
+			} else {
+				c.code(b)
+			}
+		default:
+			c.code(b)
+		}
+		i++
+	}
+
+	c.endLine()
+}
+
+// scanString advances past string contents starting at i, closing the
+// string if its terminator is found on this line
+func (c *logicalCounter) scanString(line []byte, i int) int {
+	rawBacktick := c.quote == '`' && !c.leadingDots // Go raw strings have no escapes
+	for i < len(line) {
+		b := line[i]
+		if b == '\\' && !rawBacktick {
+			i += 2
+			continue
+		}
+		if b == c.quote {
+			if !c.triple {
+				c.quote = 0
+				return i + 1
+			}
+			if i+2 < len(line) && line[i+1] == b && line[i+2] == b {
+				c.quote, c.triple = 0, false
+				return i + 3
+			}
+		}
+		i++
+	}
+	return i
+}
+
+// endLine applies newline rules once a physical line is consumed
+func (c *logicalCounter) endLine() {
+	if c.quote != 0 {
+		// only backticks and triple quotes span lines; recover from anything else
+		continued := c.quote == '`' || c.triple || c.last == '\\'
+		if continued {
+			return
+		}
+		c.quote = 0
+	}
+
+	if !c.inStatement() {
+		return
+	}
+	c.resumed = false
+	if !c.pending {
+		return
+	}
+
+	switch c.mode {
+	case logicalNewline:
+		if !endsContinuation(c.prev, c.last) {
+			c.deferred = true
+		}
+	case logicalPython:
+		if c.last != '\\' {
+			c.boundary()
+		}
+	}
+}
+
+// finish closes any open statement and returns the logical line count
+func (c *logicalCounter) finish() int {
+	c.boundary()
+	return c.count
+}
+
+// endsContinuation reports whether a statement ending in prev,last must
+// continue on the next line (a trailing operator, comma or open bracket)
+func endsContinuation(prev, last byte) bool {
+	if (last == '+' || last == '-') && prev == last {
+		return false // x++ / x--
+	}
+	switch last {
+	case ',', '.', '+', '-', '*', '/', '%', '&', '|', '^', '<', '>', '=', '!', '?', '\\':
+		return true
+	}
+	return false
+}
+
+// startsContinuation reports whether a line continues the previous statement,
+// as with method chains and operators that formatters move to the line start
+func startsContinuation(rest []byte) bool {
+	switch {
+	case bytes.HasPrefix(rest, []byte("...")):
+		return false
+	case bytes.HasPrefix(rest, []byte("&&")), bytes.HasPrefix(rest, []byte("||")):
+		return true
+	}
+	switch rest[0] {
+	case '.', '?', ':':
+		return true
+	}
+	return false
+}
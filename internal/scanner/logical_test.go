@@ -0,0 +1,137 @@
+package scanner
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func countLogical(t *testing.T, lang, src string) int {
+	t.Helper()
+	m, err := countLinesFromReader(bufio.NewReader(strings.NewReader(src)), lang)
+	if err != nil {
+		t.Fatalf("countLinesFromReader failed: %v", err)
+	}
+	return m.Logical
+}
+
+func TestLogical_WrappingInvariant(t *testing.T) {
+	tests := []struct {
+		name    string
+		lang    string
+		compact string
+		wrapped string
+		want    int
+	}{
+		{
+			name:    "go call arguments",
+			lang:    "Go",
+			compact: "x := foo(a, b, c)\n",
+			wrapped: "x := foo(\n\ta,\n\tb,\n\tc,\n)\n",
+			want:    1,
+		},
+		{
+			name:    "go function with body",
+			lang:    "Go",
+			compact: "func f(a int, b string) error {\n\treturn nil\n}\n",
+			wrapped: "func f(\n\ta int,\n\tb string,\n) error {\n\treturn nil\n}\n",
+			want:    2,
+		},
+		{
+			name:    "go trailing operator",
+			lang:    "Go",
+			compact: "ok := a && b && c\n",
+			wrapped: "ok := a &&\n\tb &&\n\tc\n",
+			want:    1,
+		},
+		{
+			name:    "typescript method chain",
+			lang:    "TypeScript",
+			compact: "const r = items.filter(x => x.ok).map(x => x.id);\n",
+			wrapped: "const r = items\n  .filter(x => x.ok)\n  .map(x => x.id);\n",
+			want:    1,
+		},
+		{
+			name:    "javascript callback body",
+			lang:    "JavaScript",
+			compact: "run(function () { a(); b(); });\n",
+			wrapped: "run(function () {\n  a();\n  b();\n});\n",
+			want:    3,
+		},
+		{
+			name:    "go func literal argument",
+			lang:    "Go",
+			compact: "x := foo(func() { return 1 }, y)\nz := 2\n",
+			wrapped: "x := foo(func() {\n\treturn 1\n}, y)\nz := 2\n",
+			want:    3,
+		},
+		{
+			name:    "java for loop",
+			lang:    "Java",
+			compact: "for (int i = 0; i < n; i++) { sum += i; }\n",
+			wrapped: "for (int i = 0;\n     i < n;\n     i++)\n{\n    sum +=\n        i;\n}\n",
+			want:    2,
+		},
+		{
+			name:    "python implicit continuation",
+			lang:    "Python",
+			compact: "total = compute(a, b, c)\n",
+			wrapped: "total = compute(\n    a,\n    b,\n    c,\n)\n",
+			want:    1,
+		},
+		{
+			name:    "python backslash continuation",
+			lang:    "Python",
+			compact: "x = a + b\n",
+			wrapped: "x = a + \\\n    b\n",
+			want:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countLogical(t, tt.lang, tt.compact); got != tt.want {
+				t.Errorf("compact Logical = %d, want %d", got, tt.want)
+			}
+			if got := countLogical(t, tt.lang, tt.wrapped); got != tt.want {
+				t.Errorf("wrapped Logical = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogical_IgnoresCommentsAndStrings(t *testing.T) {
+	tests := []struct {
+		name string
+		lang string
+		src  string
+		want int
+	}{
+		{"go comments", "Go", "// a; b; c\nx := 1 /* y; z */\n/*\nfoo()\n*/\n", 1},
+		{"go raw string", "Go", "s := `a;\nb { c`\nt := 2\n", 2},
+		{"c string with braces", "C", "printf(\"{;}\");\n", 1},
+		{"c preprocessor", "C", "#include <stdio.h>\n#define N 10\nint x;\n", 3},
+		{"python docstring", "Python", "def f():\n    \"\"\"Doc;\n    more (\n    \"\"\"\n    return 1\n", 3},
+		{"python hash in string", "Python", "s = '# not a comment'\n", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countLogical(t, tt.lang, tt.src); got != tt.want {
+				t.Errorf("Logical = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogical_UnsupportedLanguage(t *testing.T) {
+	if got := countLogical(t, "Ruby", "puts 1\nputs 2\n"); got != 0 {
+		t.Errorf("Logical for Ruby = %d, want 0", got)
+	}
+	if SupportsLogical("Ruby") {
+		t.Error("SupportsLogical(Ruby) = true, want false")
+	}
+	if !SupportsLogical("Go") {
+		t.Error("SupportsLogical(Go) = false, want true")
+	}
+}
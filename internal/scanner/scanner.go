@@ -2,21 +2,42 @@ package scanner
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 
 	"github.com/modern-tooling/aloc/internal/model"
 )
 
 type Scanner struct {
-	walker *Walker
+	walker      *Walker
+	maxFileSize int64
+	headerSize  int
+	dataHeader  int
+	languageFor func(relPath string) string
+	attributes  *GitAttributes
 }
 
 type Options struct {
-	NumWorkers int
-	Exclude    []string
-	DeepMode   bool
+	NumWorkers  int
+	Exclude     []string
+	DeepMode    bool
+	MaxFileSize int64 // skip files larger than this many bytes (0 = no limit)
+	HeaderSize  int   // leading bytes of each file kept in RawFile.Header (0 = none)
+	ScanVendor  bool  // count vendor directories, which are skipped by default
+
+	// DataHeaderSize is the leading bytes kept for YAML and JSON files when
+	// it is more than HeaderSize, for classifying them by their shape
+	DataHeaderSize int
+
+	// LanguageFor returns a language that replaces the detected one for a
+	// relative path, or "" to keep detection (e.g. language overrides). It
+	// takes precedence over linguist-language in .gitattributes.
+	LanguageFor func(relPath string) string
 }
 
 func NewScanner(root string, opts Options) (*Scanner, error) {
@@ -24,17 +45,28 @@ func NewScanner(root string, opts Options) (*Scanner, error) {
 		NumWorkers: opts.NumWorkers,
 		Exclude:    opts.Exclude,
 		DeepMode:   opts.DeepMode,
+		ScanVendor: opts.ScanVendor,
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &Scanner{walker: walker}, nil
+	attributes, _ := LoadGitAttributes(walker.root) // ignore errors, .gitattributes is optional
+	return &Scanner{
+		walker:      walker,
+		maxFileSize: opts.MaxFileSize,
+		headerSize:  opts.HeaderSize,
+		dataHeader:  opts.DataHeaderSize,
+		languageFor: opts.LanguageFor,
+		attributes:  attributes,
+	}, nil
 }
 
-func (s *Scanner) Scan(ctx context.Context) (<-chan *model.RawFile, <-chan error) {
+// Scan walks the tree and counts every candidate file. Files that are skipped,
+// only partially counted, or unreadable are reported on the diagnostics channel.
+func (s *Scanner) Scan(ctx context.Context) (<-chan *model.RawFile, <-chan model.Diagnostic) {
 	// large buffers for streaming performance
 	results := make(chan *model.RawFile, 8192)
-	errs := make(chan error, 256)
+	diags := make(chan model.Diagnostic, 256)
 
 	paths, walkErrs := s.walker.Walk(ctx)
 
@@ -50,33 +82,67 @@ func (s *Scanner) Scan(ctx context.Context) (<-chan *model.RawFile, <-chan error
 				default:
 				}
 
+				// use relative path for inference rules to work correctly
+				relPath := s.relPath(path)
+				linguist := s.attributes.Linguist(relPath)
+
 				info, statErr := os.Stat(path)
 				if statErr != nil {
-					errs <- statErr
+					diags <- s.errorDiagnostic(statErr)
 					continue
 				}
 
-				lang := DetectLanguage(path)
+				// assets are inventoried by size without being read
+				if assetType := AssetType(path); assetType != "" {
+					asset := newAssetFile(path, relPath, info.Size(), assetType, "unknown")
+					asset.Linguist = linguist
+					results <- asset
+					continue
+				}
 
-				// Use embedded-aware counting for Markdown/MDX
-				var lines model.LineMetrics
-				var embedded map[string]model.LineMetrics
-				var countErr error
+				if s.maxFileSize > 0 && info.Size() > s.maxFileSize {
+					diags <- model.Diagnostic{
+						Path:   relPath,
+						Kind:   model.DiagnosticSkipped,
+						Reason: fmt.Sprintf("exceeds max file size (%d bytes)", s.maxFileSize),
+						Bytes:  info.Size(),
+					}
+					continue
+				}
 
-				if lang == "Markdown" || lang == "MDX" {
-					lines, embedded, countErr = CountLinesWithEmbedded(path)
-				} else {
-					lines, countErr = CountLines(path)
+				lang := "" // detected while counting unless overridden
+				if s.languageFor != nil {
+					lang = s.languageFor(relPath)
 				}
+				if lang == "" && linguist != nil {
+					lang = linguist.Language
+				}
+
+				// one read detects the language, captures the header and
+				// counts lines, embedded code included
+				counted, countErr := countFile(path, lang, s.headerSizeFor(relPath))
+				lines := counted.lines
 				if countErr != nil {
-					errs <- countErr
-					continue
+					var partial *PartialReadError
+					if !errors.As(countErr, &partial) || lines.Total == 0 {
+						diags <- s.errorDiagnostic(countErr)
+						continue
+					}
+					// keep what was counted, but flag the file as incomplete
+					diags <- model.Diagnostic{
+						Path:   relPath,
+						Kind:   model.DiagnosticPartial,
+						Reason: partial.Err.Error(),
+						Bytes:  info.Size(),
+					}
 				}
 
-				// use relative path for inference rules to work correctly
-				relPath, relErr := filepath.Rel(s.walker.root, path)
-				if relErr != nil {
-					relPath = path
+				// non-empty files without lines are binaries or LFS pointers
+				if lines.Total == 0 && info.Size() > 0 {
+					asset := newAssetFile(path, relPath, info.Size(), AssetBinary, counted.lang)
+					asset.Linguist = linguist
+					results <- asset
+					continue
 				}
 
 				results <- &model.RawFile{
@@ -84,24 +150,89 @@ func (s *Scanner) Scan(ctx context.Context) (<-chan *model.RawFile, <-chan error
 					Bytes:        info.Size(),
 					LOC:          lines.Code,
 					Lines:        lines,
-					LanguageHint: lang,
-					Embedded:     embedded,
+					LanguageHint: counted.lang,
+					Embedded:     counted.embedded,
+					Header:       counted.header,
+					Linguist:     linguist,
 				}
 			}
 		}()
 	}
 
+	var forwardWG sync.WaitGroup
+	forwardWG.Add(1)
 	go func() {
+		defer forwardWG.Done()
 		for err := range walkErrs {
-			errs <- err
+			diags <- s.errorDiagnostic(err)
 		}
 	}()
 
 	go func() {
 		wg.Wait()
+		forwardWG.Wait()
 		close(results)
-		close(errs)
+		close(diags)
 	}()
 
-	return results, errs
+	return results, diags
+}
+
+// newAssetFile builds the scanner output for a binary asset, resolving Git LFS
+// pointers to the size of the object they stand in for
+func newAssetFile(path, relPath string, size int64, assetType, lang string) *model.RawFile {
+	asset := &model.AssetInfo{Type: assetType}
+	if size <= lfsPointerMaxSize {
+		if lfsSize, ok := ReadLFSPointer(path); ok {
+			asset.LFS = true
+			asset.LFSSize = lfsSize
+		}
+	}
+	return &model.RawFile{
+		Path:         relPath,
+		Bytes:        size,
+		LanguageHint: lang,
+		Asset:        asset,
+	}
+}
+
+// relPath returns path relative to the scan root, or path itself on failure
+func (s *Scanner) relPath(path string) string {
+	relPath, err := filepath.Rel(s.walker.root, path)
+	if err != nil {
+		return path
+	}
+	return relPath
+}
+
+// errorDiagnostic converts a walk or read error into a diagnostic,
+// extracting the offending path when the error carries one
+func (s *Scanner) errorDiagnostic(err error) model.Diagnostic {
+	d := model.Diagnostic{
+		Kind:   model.DiagnosticError,
+		Reason: err.Error(),
+	}
+
+	var partial *PartialReadError
+	var pathErr *fs.PathError
+	switch {
+	case errors.As(err, &partial):
+		d.Path = s.relPath(partial.Path)
+		d.Reason = partial.Err.Error()
+	case errors.As(err, &pathErr):
+		d.Path = s.relPath(pathErr.Path)
+		d.Reason = pathErr.Err.Error()
+	}
+	return d
+}
+
+// headerSizeFor returns how much of a file to keep as its header
+func (s *Scanner) headerSizeFor(relPath string) int {
+	if s.dataHeader > s.headerSize {
+		switch strings.ToLower(filepath.Ext(relPath)) {
+		case ".yaml", ".yml", ".json":
+			return s.dataHeader
+		}
+	}
+	return s.headerSize
 }
@@ -0,0 +1,213 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modern-tooling/aloc/internal/model"
+)
+
+func scanAll(t *testing.T, root string, opts Options) ([]*model.RawFile, []model.Diagnostic) {
+	t.Helper()
+	s, err := NewScanner(root, opts)
+	if err != nil {
+		t.Fatalf("NewScanner failed: %v", err)
+	}
+
+	rawFiles, diagCh := s.Scan(context.Background())
+	var diags []model.Diagnostic
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for d := range diagCh {
+			diags = append(diags, d)
+		}
+	}()
+
+	var files []*model.RawFile
+	for f := range rawFiles {
+		files = append(files, f)
+	}
+	<-done
+	return files, diags
+}
+
+func TestScan_MaxFileSizeSkip(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "small.go"), []byte("package main\n"), 0644)
+	os.WriteFile(filepath.Join(root, "big.go"), []byte(strings.Repeat("var x = 1\n", 100)), 0644)
+
+	files, diags := scanAll(t, root, Options{NumWorkers: 2, MaxFileSize: 100})
+
+	if len(files) != 1 || files[0].Path != "small.go" {
+		t.Fatalf("files = %v, want only small.go", files)
+	}
+	if len(diags) != 1 {
+		t.Fatalf("diagnostics = %v, want 1", diags)
+	}
+	d := diags[0]
+	if d.Path != "big.go" || d.Kind != model.DiagnosticSkipped || d.Bytes != 1000 {
+		t.Errorf("diagnostic = %+v, want skipped big.go (1000 bytes)", d)
+	}
+}
+
+func TestScan_NoLimitCountsEverything(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "big.go"), []byte(strings.Repeat("var x = 1\n", 100)), 0644)
+
+	files, diags := scanAll(t, root, Options{NumWorkers: 2})
+
+	if len(files) != 1 || files[0].LOC != 100 {
+		t.Errorf("files = %v, want big.go with 100 LOC", files)
+	}
+	if len(diags) != 0 {
+		t.Errorf("diagnostics = %v, want none", diags)
+	}
+}
+
+func TestScan_InventoriesAssets(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0644)
+	os.WriteFile(filepath.Join(root, "logo.png"), []byte("\x89PNG\r\n\x1a\n\x00\x00"), 0644)
+	os.WriteFile(filepath.Join(root, "blob.json"), []byte("{\"a\": \x00}"), 0644)
+	os.WriteFile(filepath.Join(root, "weights.onnx"),
+		[]byte("version https://git-lfs.github.com/spec/v1\noid sha256:4d7a\nsize 734003200\n"), 0644)
+
+	// assets are inventoried even over the size limit and in quick mode
+	files, _ := scanAll(t, root, Options{NumWorkers: 2, MaxFileSize: 5})
+
+	byPath := make(map[string]*model.RawFile)
+	for _, f := range files {
+		byPath[f.Path] = f
+	}
+
+	tests := []struct {
+		path    string
+		typ     string
+		lfs     bool
+		lfsSize int64
+	}{
+		{"logo.png", AssetImage, false, 0},
+		{"weights.onnx", AssetModel, true, 734003200},
+	}
+	for _, tt := range tests {
+		f := byPath[tt.path]
+		if f == nil || f.Asset == nil {
+			t.Errorf("%s: not inventoried as an asset", tt.path)
+			continue
+		}
+		if f.Asset.Type != tt.typ || f.Asset.LFS != tt.lfs || f.Asset.LFSSize != tt.lfsSize {
+			t.Errorf("%s: asset = %+v, want type %s lfs %v size %d", tt.path, f.Asset, tt.typ, tt.lfs, tt.lfsSize)
+		}
+		if f.LOC != 0 {
+			t.Errorf("%s: LOC = %d, want 0", tt.path, f.LOC)
+		}
+	}
+
+	// binary content under a source extension is still over the size limit
+	if f := byPath["blob.json"]; f != nil {
+		t.Errorf("blob.json = %+v, want skipped by max file size", f)
+	}
+	if f := byPath["main.go"]; f != nil {
+		t.Errorf("main.go = %+v, want skipped by max file size", f)
+	}
+
+	files, _ = scanAll(t, root, Options{NumWorkers: 2})
+	for _, f := range files {
+		if f.Path == "blob.json" && (f.Asset == nil || f.Asset.Type != AssetBinary) {
+			t.Errorf("blob.json: asset = %+v, want binary", f.Asset)
+		}
+		if f.Path == "main.go" && f.Asset != nil {
+			t.Errorf("main.go: asset = %+v, want none", f.Asset)
+		}
+	}
+}
+
+func TestScan_LanguageFor(t *testing.T) {
+	root := t.TempDir()
+	// "#" starts a comment in Python but not in C
+	os.WriteFile(filepath.Join(root, "macros.h"), []byte("# note\nx = 1\n"), 0644)
+
+	files, _ := scanAll(t, root, Options{
+		NumWorkers: 1,
+		LanguageFor: func(relPath string) string {
+			if relPath == "macros.h" {
+				return "Python"
+			}
+			return ""
+		},
+	})
+
+	if len(files) != 1 {
+		t.Fatalf("files = %v, want 1", files)
+	}
+	f := files[0]
+	if f.LanguageHint != "Python" || f.Lines.Comments != 1 || f.LOC != 1 {
+		t.Errorf("file = %s, %d comments, %d LOC; want Python, 1 comment, 1 LOC", f.LanguageHint, f.Lines.Comments, f.LOC)
+	}
+}
+
+func TestScan_Header(t *testing.T) {
+	root := t.TempDir()
+	long := "// Code generated by gen. DO NOT EDIT.\n" + strings.Repeat("var x = 1\n", 100)
+	os.WriteFile(filepath.Join(root, "gen.go"), []byte(long), 0644)
+	os.WriteFile(filepath.Join(root, "short.go"), []byte("package x\n"), 0644)
+	// extensionless: the language comes from the shebang in the same read
+	os.WriteFile(filepath.Join(root, "run"), []byte("#!/usr/bin/env python3\nprint(1)\n"), 0644)
+
+	files, _ := scanAll(t, root, Options{NumWorkers: 1, DeepMode: true, HeaderSize: 64})
+
+	byPath := make(map[string]*model.RawFile)
+	for _, f := range files {
+		byPath[f.Path] = f
+	}
+	tests := []struct {
+		path   string
+		header string
+		lang   string
+	}{
+		{"gen.go", long[:64], "Go"},
+		{"short.go", "package x\n", "Go"},
+		{"run", "#!/usr/bin/env python3\nprint(1)\n", "Python"},
+	}
+	for _, tt := range tests {
+		f := byPath[tt.path]
+		if f == nil {
+			t.Fatalf("%s not scanned", tt.path)
+		}
+		if string(f.Header) != tt.header || f.LanguageHint != tt.lang {
+			t.Errorf("%s = %q (%s), want %q (%s)", tt.path, f.Header, f.LanguageHint, tt.header, tt.lang)
+		}
+	}
+	if f := byPath["gen.go"]; f.Lines.Total != 101 {
+		t.Errorf("gen.go lines = %d, want 101 counted past the header", f.Lines.Total)
+	}
+
+	// without a header size nothing is kept
+	files, _ = scanAll(t, root, Options{NumWorkers: 1, DeepMode: true})
+	for _, f := range files {
+		if f.Header != nil {
+			t.Errorf("%s Header = %q, want nil", f.Path, f.Header)
+		}
+	}
+}
+
+func TestScan_DataHeader(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "deploy.yaml"), []byte("apiVersion: v1\nkind: Service\n"), 0644)
+	os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0644)
+
+	files, _ := scanAll(t, root, Options{NumWorkers: 1, DataHeaderSize: 64})
+	for _, f := range files {
+		want := ""
+		if f.Path == "deploy.yaml" {
+			want = "apiVersion: v1\nkind: Service\n"
+		}
+		if string(f.Header) != want {
+			t.Errorf("%s Header = %q, want %q", f.Path, f.Header, want)
+		}
+	}
+}
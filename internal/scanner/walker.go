@@ -3,9 +3,12 @@ package scanner
 import (
 	"context"
 	"io/fs"
+	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 // knownSourceExtensions contains extensions that are recognized as source code
@@ -19,28 +22,44 @@ var knownSourceExtensions = map[string]bool{
 	".yaml": true, ".yml": true, ".json": true, ".xml": true, ".html": true, ".css": true,
 	".scss": true, ".sass": true, ".less": true, ".vue": true, ".svelte": true,
 	".md": true, ".mdx": true, ".rst": true, ".txt": true,
+	".lhs": true, ".litcoffee": true, ".org": true, ".rmd": true, ".qmd": true,
 	".tf": true, ".hcl": true, ".proto": true, ".graphql": true,
 	".lua": true, ".r": true, ".R": true, ".pl": true, ".pm": true,
 	".ex": true, ".exs": true, ".erl": true, ".hs": true, ".clj": true,
-	".toml": true, ".ini": true, ".cfg": true, ".conf": true,
+	".toml": true, ".ini": true, ".cfg": true, ".conf": true, ".properties": true,
+	".po": true, ".pot": true, ".xliff": true, ".xlf": true, ".strings": true, ".stringsdict": true,
+	".xcstrings": true, ".arb": true, ".avsc": true, ".avpr": true, ".graphqls": true, ".gql": true,
 }
 
 func isKnownSourceExtension(ext string) bool {
 	return knownSourceExtensions[ext]
 }
 
+// knownSourceFilenames contains lowercase filenames of user-defined languages
+// that quick mode accepts regardless of extension
+var knownSourceFilenames = map[string]bool{}
+
+// maxListingsAhead caps the directories listed but not yet emitted, so a
+// wide tree is not held in memory ahead of a slow consumer
+const maxListingsAhead = 1024
+
 type Walker struct {
-	root       string
-	numWorkers int
-	exclude    []string
-	deepMode   bool
-	gitignore  *GitIgnore
+	root        string
+	numWorkers  int
+	walkWorkers int
+	readAhead   int // directories listed ahead of emission
+	exclude     []string
+	deepMode    bool
+	scanVendor  bool
+	gitignore   *GitIgnore
 }
 
 type WalkOptions struct {
-	NumWorkers int
-	Exclude    []string
-	DeepMode   bool
+	NumWorkers  int
+	WalkWorkers int // concurrent directory readers (0 = same as NumWorkers)
+	Exclude     []string
+	DeepMode    bool
+	ScanVendor  bool // descend into vendor directories
 }
 
 func NewWalker(root string, opts WalkOptions) (*Walker, error) {
@@ -48,12 +67,17 @@ func NewWalker(root string, opts WalkOptions) (*Walker, error) {
 		// adaptive worker count: min(32, 4*GOMAXPROCS) per impl-ideas.txt
 		opts.NumWorkers = min(32, 4*runtime.GOMAXPROCS(0))
 	}
+	if opts.WalkWorkers <= 0 {
+		// directory reads are latency-bound (especially on network filesystems),
+		// so the same oversubscription as counting pays off here
+		opts.WalkWorkers = opts.NumWorkers
+	}
 	absRoot, err := filepath.Abs(root)
 	if err != nil {
 		return nil, err
 	}
 
-	// Resolve symlinks so WalkDir can traverse the actual directory
+	// Resolve symlinks so the traversal starts at the actual directory
 	absRoot, err = filepath.EvalSymlinks(absRoot)
 	if err != nil {
 		return nil, err
@@ -62,113 +86,323 @@ func NewWalker(root string, opts WalkOptions) (*Walker, error) {
 	gitignore, _ := LoadGitIgnore(absRoot) // ignore errors, gitignore is optional
 
 	return &Walker{
-		root:       absRoot,
-		numWorkers: opts.NumWorkers,
-		exclude:    opts.Exclude,
-		deepMode:   opts.DeepMode,
-		gitignore:  gitignore,
+		root:        absRoot,
+		numWorkers:  opts.NumWorkers,
+		walkWorkers: opts.WalkWorkers,
+		readAhead:   maxListingsAhead,
+		exclude:     opts.Exclude,
+		deepMode:    opts.DeepMode,
+		scanVendor:  opts.ScanVendor,
+		gitignore:   gitignore,
 	}, nil
 }
 
+// skipDirNames contains directory names that are never descended into,
+// except vendor with ScanVendor
+var skipDirNames = map[string]bool{
+	".git": true, "vendor": true, "node_modules": true,
+	// package manager caches
+	".pnpm-store": true, ".yarn": true, ".npm": true,
+	// build/cache directories
+	".terraform": true, ".terragrunt-cache": true,
+	".nx": true, ".turbo": true, ".next": true, ".nuxt": true, ".cache": true,
+	".venv": true, "venv": true, "__pycache__": true, ".pytest_cache": true,
+	".gradle": true, ".m2": true,
+	// IDE directories
+	".idea": true, ".vscode": true,
+	// OS directories
+	".DS_Store": true,
+	// git hooks
+	".husky": true,
+	// other caches
+	"dist": true, "build": true, "target": true, "out": true,
+	".angular": true, ".svelte-kit": true,
+	// generated/temp directories
+	"generated": true, "tmp": true,
+	// iOS/macOS build directories
+	"Pods": true, "xcuserdata": true, "DerivedData": true, "Carthage": true,
+	// Android/mobile build directories
+	".cxx": true, ".kotlin": true, ".expo": true,
+	// Ruby bundler (used by CocoaPods)
+	".bundle": true,
+}
+
+// skip reports whether an entry is filtered out by gitignore, exclude
+// patterns, the skipped directory list, or (in quick mode) its extension
+func (w *Walker) skip(path string, d fs.DirEntry) bool {
+	// Check gitignore
+	if w.gitignore != nil && w.gitignore.Match(path, d.IsDir()) {
+		return true
+	}
+
+	// skip excluded patterns
+	relPath, _ := filepath.Rel(w.root, path)
+	for _, pattern := range w.exclude {
+		if matched, _ := filepath.Match(pattern, relPath); matched {
+			return true
+		}
+		if strings.Contains(relPath, strings.TrimSuffix(strings.TrimPrefix(pattern, "**/"), "/**")) {
+			return true
+		}
+	}
+
+	// skip common cache, build, and dependency directories
+	if d.IsDir() {
+		if d.Name() == "vendor" && w.scanVendor {
+			return false
+		}
+		return skipDirNames[d.Name()]
+	}
+
+	// in quick mode, only process files with known source or asset extensions
+	// (skip extensionless files which are usually binaries or generated)
+	if !w.deepMode && !knownSourceFilenames[strings.ToLower(d.Name())] {
+		ext := strings.ToLower(filepath.Ext(path))
+		if ext == "" || !(isKnownSourceExtension(ext) || isAssetExtension(ext)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// dirNode is one directory in the traversal. Whoever claims it, a worker or
+// the emitter, fills in entries and closes done; the emitter waits on done to
+// stream files in lexical order.
+type dirNode struct {
+	path    string
+	entries []dirEntry
+	done    chan struct{}
+	claimed atomic.Bool
+	ahead   bool // listed by a worker holding a read-ahead slot
+}
+
+// dirEntry is either a file path or a subdirectory still to be listed
+type dirEntry struct {
+	file string
+	dir  *dirNode
+}
+
+func newDirNode(path string) *dirNode {
+	return &dirNode{path: path, done: make(chan struct{})}
+}
+
+// claim reports whether the caller is the first to take the node for reading
+func (n *dirNode) claim() bool {
+	return n.claimed.CompareAndSwap(false, true)
+}
+
+// dirDeque is a per-worker double-ended queue. The owner pushes and pops at
+// the bottom (depth-first, cache friendly); idle workers steal from the top,
+// which holds the shallowest and therefore largest pending subtrees.
+type dirDeque struct {
+	mu    sync.Mutex
+	nodes []*dirNode
+}
+
+func (q *dirDeque) push(n *dirNode) {
+	q.mu.Lock()
+	q.nodes = append(q.nodes, n)
+	q.mu.Unlock()
+}
+
+func (q *dirDeque) pop() *dirNode {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.nodes) == 0 {
+		return nil
+	}
+	n := q.nodes[len(q.nodes)-1]
+	q.nodes = q.nodes[:len(q.nodes)-1]
+	return n
+}
+
+func (q *dirDeque) steal() *dirNode {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.nodes) == 0 {
+		return nil
+	}
+	n := q.nodes[0]
+	q.nodes = q.nodes[1:]
+	return n
+}
+
+// walkQueue hands queued directories to the workers. Idle workers block on
+// cond until a directory is queued or the last one has been listed.
+type walkQueue struct {
+	deques  []*dirDeque
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queued  int // nodes in the deques, including ones the emitter claimed
+	pending int // directories queued or being read; zero means done
+}
+
+func newWalkQueue(workers int) *walkQueue {
+	q := &walkQueue{deques: make([]*dirDeque, workers)}
+	for i := range q.deques {
+		q.deques[i] = &dirDeque{}
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push queues a directory on a worker's deque
+func (q *walkQueue) push(id int, n *dirNode) {
+	q.deques[id].push(n)
+	q.mu.Lock()
+	q.queued++
+	q.pending++
+	q.mu.Unlock()
+	q.cond.Signal()
+}
+
+// next blocks until a directory is queued and claims it, popping the
+// worker's own deque before stealing; it returns nil once all are listed
+func (q *walkQueue) next(id int) *dirNode {
+	for {
+		q.mu.Lock()
+		for q.queued == 0 && q.pending > 0 {
+			q.cond.Wait()
+		}
+		if q.queued == 0 {
+			q.mu.Unlock()
+			return nil
+		}
+		q.queued-- // reserves one of the queued nodes
+		q.mu.Unlock()
+
+		var node *dirNode
+		for node == nil {
+			node = q.deques[id].pop()
+			for j := 1; node == nil && j < len(q.deques); j++ {
+				node = q.deques[(id+j)%len(q.deques)].steal()
+			}
+		}
+		if node.claim() {
+			return node
+		}
+	}
+}
+
+// finish marks a claimed directory as listed
+func (q *walkQueue) finish(n *dirNode) {
+	close(n.done)
+	q.mu.Lock()
+	q.pending--
+	last := q.pending == 0
+	q.mu.Unlock()
+	if last {
+		q.cond.Broadcast()
+	}
+}
+
+// Walk traverses the tree with a pool of work-stealing directory readers and
+// streams file paths in the same lexical order as filepath.WalkDir, so output
+// is deterministic regardless of scheduling. Workers list at most readAhead
+// directories ahead of emission; the emitter lists the directory it waits on
+// itself when no worker has claimed it yet.
 func (w *Walker) Walk(ctx context.Context) (<-chan string, <-chan error) {
 	// large buffer prevents walker from stalling on slow consumers
 	paths := make(chan string, 8192)
 	errs := make(chan error, 256)
 
-	go func() {
-		defer close(paths)
-		defer close(errs)
-
-		err := filepath.WalkDir(w.root, func(path string, d fs.DirEntry, err error) error {
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			default:
-			}
+	queue := newWalkQueue(w.walkWorkers)
+	root := newDirNode(w.root)
+	queue.push(0, root)
 
-			if err != nil {
-				errs <- err
-				return nil
-			}
+	// a slot is taken before listing a directory and freed once it is emitted
+	ahead := make(chan struct{}, max(w.readAhead, 1))
 
-			// Check gitignore
-			if w.gitignore != nil && w.gitignore.Match(path, d.IsDir()) {
-				if d.IsDir() {
-					return filepath.SkipDir
+	var wg sync.WaitGroup
+	for i := range queue.deques {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for {
+				select {
+				case ahead <- struct{}{}:
+				case <-ctx.Done():
+					return
 				}
-				return nil
-			}
-
-			// skip excluded patterns
-			relPath, _ := filepath.Rel(w.root, path)
-			for _, pattern := range w.exclude {
-				if matched, _ := filepath.Match(pattern, relPath); matched {
-					if d.IsDir() {
-						return filepath.SkipDir
-					}
-					return nil
-				}
-				if strings.Contains(relPath, strings.TrimSuffix(strings.TrimPrefix(pattern, "**/"), "/**")) {
-					if d.IsDir() {
-						return filepath.SkipDir
-					}
-					return nil
+				node := queue.next(id)
+				if node == nil {
+					<-ahead
+					return
 				}
+				node.ahead = true
+				w.readDir(ctx, node, func(child *dirNode) { queue.push(id, child) }, errs)
+				queue.finish(node)
 			}
+		}(i)
+	}
 
-			// skip directories
-			if d.IsDir() {
-				name := d.Name()
-				// skip common cache, build, and dependency directories
-				switch name {
-				case ".git", "vendor", "node_modules",
-					// package manager caches
-					".pnpm-store", ".yarn", ".npm",
-					// build/cache directories
-					".terraform", ".terragrunt-cache",
-					".nx", ".turbo", ".next", ".nuxt", ".cache",
-					".venv", "venv", "__pycache__", ".pytest_cache",
-					".gradle", ".m2",
-					// IDE directories
-					".idea", ".vscode",
-					// OS directories
-					".DS_Store",
-					// git hooks
-					".husky",
-					// other caches
-					"dist", "build", "target", "out",
-					".angular", ".svelte-kit",
-					// generated/temp directories
-					"generated", "tmp",
-					// iOS/macOS build directories
-					"Pods", "xcuserdata", "DerivedData", "Carthage",
-					// Android/mobile build directories
-					".cxx", ".kotlin", ".expo",
-					// Ruby bundler (used by CocoaPods)
-					".bundle":
-					return filepath.SkipDir
-				}
-				return nil
-			}
+	go func() {
+		defer close(paths)
+		w.emit(ctx, root, queue, ahead, paths, errs)
+		wg.Wait()
+		close(errs)
+	}()
 
-			// in quick mode, only process files with known source extensions
-			// (skip extensionless files which are usually binaries or generated)
-			if !w.deepMode {
-				ext := strings.ToLower(filepath.Ext(path))
-				if ext == "" || !isKnownSourceExtension(ext) {
-					return nil
-				}
-			}
+	return paths, errs
+}
 
-			// binary check moved to CountLOC for single file open
-			paths <- path
-			return nil
-		})
+// readDir lists one directory, queueing its subdirectories
+func (w *Walker) readDir(ctx context.Context, node *dirNode, queue func(*dirNode), errs chan<- error) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	// os.ReadDir returns entries sorted by name, matching WalkDir order;
+	// on error it still returns whatever was read
+	entries, err := os.ReadDir(node.path)
+	if err != nil {
+		errs <- err
+	}
 
-		if err != nil && err != context.Canceled {
-			errs <- err
+	node.entries = make([]dirEntry, 0, len(entries))
+	for _, d := range entries {
+		path := filepath.Join(node.path, d.Name())
+		if w.skip(path, d) {
+			continue
 		}
-	}()
+		if d.IsDir() {
+			child := newDirNode(path)
+			queue(child)
+			node.entries = append(node.entries, dirEntry{dir: child})
+			continue
+		}
+		// binary check moved to CountLOC for single file open
+		node.entries = append(node.entries, dirEntry{file: path})
+	}
+}
 
-	return paths, errs
+// emit streams files depth-first in lexical order, waiting for each
+// directory listing to complete before descending into it
+func (w *Walker) emit(ctx context.Context, node *dirNode, queue *walkQueue, ahead <-chan struct{}, paths chan<- string, errs chan<- error) {
+	if node.claim() {
+		w.readDir(ctx, node, func(child *dirNode) { queue.push(0, child) }, errs)
+		queue.finish(node)
+	}
+	select {
+	case <-node.done:
+	case <-ctx.Done():
+		return
+	}
+
+	for _, e := range node.entries {
+		if e.dir != nil {
+			w.emit(ctx, e.dir, queue, ahead, paths, errs)
+			continue
+		}
+		select {
+		case paths <- e.file:
+		case <-ctx.Done():
+			return
+		}
+	}
+	node.entries = nil // release emitted subtree
+	if node.ahead {
+		<-ahead
+	}
 }
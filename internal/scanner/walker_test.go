@@ -0,0 +1,171 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// buildSyntheticTree creates a tree with the given fan-out per level and
+// files per directory, returning the number of files written
+func buildSyntheticTree(tb testing.TB, root string, depth, fanout, filesPerDir int) int {
+	tb.Helper()
+	count := 0
+	var build func(dir string, level int)
+	build = func(dir string, level int) {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			tb.Fatal(err)
+		}
+		for i := 0; i < filesPerDir; i++ {
+			name := filepath.Join(dir, fmt.Sprintf("file%02d.go", i))
+			if err := os.WriteFile(name, []byte("package x\n"), 0644); err != nil {
+				tb.Fatal(err)
+			}
+			count++
+		}
+		if level == depth {
+			return
+		}
+		for i := 0; i < fanout; i++ {
+			build(filepath.Join(dir, fmt.Sprintf("dir%02d", i)), level+1)
+		}
+	}
+	build(root, 0)
+	return count
+}
+
+func collectWalk(tb testing.TB, w *Walker) []string {
+	tb.Helper()
+	paths, errs := w.Walk(context.Background())
+	go func() {
+		for range errs {
+		}
+	}()
+	var got []string
+	for p := range paths {
+		got = append(got, p)
+	}
+	return got
+}
+
+func TestWalk_MatchesWalkDirOrder(t *testing.T) {
+	root := t.TempDir()
+	buildSyntheticTree(t, root, 3, 4, 3)
+	root, _ = filepath.EvalSymlinks(root)
+
+	var want []string
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if !d.IsDir() {
+			want = append(want, path)
+		}
+		return nil
+	})
+
+	w, err := NewWalker(root, WalkOptions{WalkWorkers: 8})
+	if err != nil {
+		t.Fatalf("NewWalker failed: %v", err)
+	}
+
+	// repeated runs must produce identical order despite concurrent reads
+	for run := 0; run < 5; run++ {
+		got := collectWalk(t, w)
+		if !slices.Equal(got, want) {
+			t.Fatalf("run %d: got %d paths out of order, want %d in WalkDir order", run, len(got), len(want))
+		}
+	}
+}
+
+func TestWalk_BoundedReadAhead(t *testing.T) {
+	root := t.TempDir()
+	buildSyntheticTree(t, root, 2, 12, 2)
+	root, _ = filepath.EvalSymlinks(root)
+
+	var want []string
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if !d.IsDir() {
+			want = append(want, path)
+		}
+		return nil
+	})
+
+	// with fewer slots than workers, the emitter lists what it waits on
+	for _, readAhead := range []int{1, 2, 16} {
+		w, err := NewWalker(root, WalkOptions{WalkWorkers: 8})
+		if err != nil {
+			t.Fatalf("NewWalker failed: %v", err)
+		}
+		w.readAhead = readAhead
+		if got := collectWalk(t, w); !slices.Equal(got, want) {
+			t.Fatalf("read-ahead %d: got %d paths, want %d in WalkDir order", readAhead, len(got), len(want))
+		}
+	}
+}
+
+func TestWalk_HonorsIgnoresAndExcludes(t *testing.T) {
+	root := t.TempDir()
+	files := []string{
+		"main.go",
+		"ignored/skip.go",
+		"fixtures/data.go",
+		"node_modules/lib/index.js",
+		"pkg/keep.go",
+		"pkg/debug.log",
+	}
+	for _, f := range files {
+		path := filepath.Join(root, f)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("x\n"), 0644)
+	}
+	os.WriteFile(filepath.Join(root, ".gitignore"), []byte("ignored/\n"), 0644)
+
+	w, err := NewWalker(root, WalkOptions{WalkWorkers: 4, Exclude: []string{"fixtures/**"}})
+	if err != nil {
+		t.Fatalf("NewWalker failed: %v", err)
+	}
+
+	var got []string
+	for _, p := range collectWalk(t, w) {
+		rel, _ := filepath.Rel(w.root, p)
+		got = append(got, filepath.ToSlash(rel))
+	}
+
+	want := []string{"main.go", "pkg/keep.go"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Walk = %v, want %v", got, want)
+	}
+}
+
+func BenchmarkWalk(b *testing.B) {
+	shapes := []struct {
+		name                       string
+		depth, fanout, filesPerDir int
+	}{
+		{"wide", 2, 40, 10},  // 1,641 dirs
+		{"deep", 6, 3, 5},    // 1,093 dirs
+		{"flat", 1, 4, 2000}, // few large directories
+	}
+
+	for _, shape := range shapes {
+		root := b.TempDir()
+		files := buildSyntheticTree(b, root, shape.depth, shape.fanout, shape.filesPerDir)
+
+		for _, workers := range []int{1, 4, 16} {
+			b.Run(fmt.Sprintf("%s/workers=%d", shape.name, workers), func(b *testing.B) {
+				w, err := NewWalker(root, WalkOptions{WalkWorkers: workers})
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if got := len(collectWalk(b, w)); got != files {
+						b.Fatalf("walked %d files, want %d", got, files)
+					}
+				}
+			})
+		}
+	}
+}
@@ -9,14 +9,90 @@ import (
 )
 
 type Config struct {
-	Overrides map[model.Role][]string `yaml:"overrides"`
-	Exclude   []string                `yaml:"exclude"`
-	Options   Options                 `yaml:"options"`
+	Overrides Overrides           `yaml:"overrides"`
+	Exclude   []string            `yaml:"exclude"`
+	Options   Options             `yaml:"options"`
+	Languages map[string]Language `yaml:"languages"`
+	Rules     Rules               `yaml:"rules"`
+	Roles     map[string]Role     `yaml:"roles"`
+	TestPairs []TestPair          `yaml:"test_pairs"`
+}
+
+// TestPair maps test files to the source they test, for layouts the naming
+// conventions miss. {dir} stands for any directories and {name} for one
+// path segment.
+type TestPair struct {
+	Source string `yaml:"source"` // e.g. "lib/{dir}/{name}.rb"
+	Test   string `yaml:"test"`   // e.g. "spec/{dir}/{name}_spec.rb"
+}
+
+// Role defines a custom role, keyed by the name overrides and rules assign
+// (e.g. "fixtures")
+type Role struct {
+	Name        string       `yaml:"name"`          // display name (default: the key)
+	Color       string       `yaml:"color"`         // primary, safety, operational, knowledge, fragility, low_emphasis, external, warning
+	Priority    int          `yaml:"priority"`      // tie-break, lower wins; built-ins are 1 (vendor) to 13 (schemas), default 50
+	RatioToCore *RatioTarget `yaml:"ratio_to_core"` // show LOC relative to core in the health ratios
+	Effort      *bool        `yaml:"effort"`        // counts toward effort estimates (default true)
+}
+
+// RatioTarget is the healthy range of a role's LOC relative to core
+type RatioTarget struct {
+	Min float32 `yaml:"min"`
+	Max float32 `yaml:"max"`
+}
+
+// Rules adds weighted classification rules that combine with the built-in
+// heuristics, and disables built-in rules by kind and pattern
+type Rules struct {
+	Path      []Rule   `yaml:"path"`      // matched against the relative path
+	Filename  []Rule   `yaml:"filename"`  // matched against the base name
+	Extension []Rule   `yaml:"extension"` // e.g. "tmpl" or "pb.go"
+	Header    []Rule   `yaml:"header"`    // matched against the first 2KB of content
+	Disable   []string `yaml:"disable"`   // "path:/bin/", "filename:makefile" or a whole kind such as "header"
+}
+
+// Rule is a single custom classification rule
+type Rule struct {
+	Pattern string        `yaml:"pattern"`
+	Match   string        `yaml:"match"` // filename rules: suffix, prefix, contains (default) or exact
+	Regex   bool          `yaml:"regex"` // treat pattern as a regular expression
+	Role    model.Role    `yaml:"role"`
+	SubRole model.SubRole `yaml:"sub_role"` // one of the role's sub-roles: unit, e2e, ... for test, flyway, ... for migrations
+	Weight  float32       `yaml:"weight"`   // 0-1, comparable to built-in weights (0.5-0.95)
+}
+
+// Language adds a language, or overrides the set fields of a built-in
+// language with the same name (e.g. "Go", "YAML")
+type Language struct {
+	Extensions    []string   `yaml:"extensions"`     // without the leading dot
+	Filenames     []string   `yaml:"filenames"`      // exact names such as "Jenkinsfile"
+	LineComment   []string   `yaml:"line_comment"`   // e.g. ["#"]
+	BlockComments [][]string `yaml:"block_comments"` // start/end pairs, e.g. [["/*", "*/"]]
+	Category      string     `yaml:"category"`       // primary, web, infra, data, docs, other
 }
 
 type Options struct {
-	HeaderProbe  bool `yaml:"header_probe"`
-	Neighborhood bool `yaml:"neighborhood"`
+	HeaderProbe  bool   `yaml:"header_probe"`
+	Neighborhood bool   `yaml:"neighborhood"`
+	MaxFileSize  int64  `yaml:"max_file_size"` // bytes; 0 = no limit
+	GoPrecise    bool   `yaml:"go_precise"`    // parse Go files instead of relying on name heuristics
+	Weights      string `yaml:"weights"`       // rule weights file written by aloc learn, relative to the scanned root
+	ScanVendor   bool   `yaml:"scan_vendor"`   // count vendor/ directories as vendor instead of skipping them
+
+	NeighborhoodWeights NeighborhoodWeights `yaml:"neighborhood_weights"`
+}
+
+// NeighborhoodWeights tunes neighborhood inference; unset fields keep the
+// defaults (decay 0.5, levels 0, depth 1, min_support 2, dominance 0.7,
+// boost 0.4)
+type NeighborhoodWeights struct {
+	Decay      *float32 `yaml:"decay"`       // vote multiplier per directory step, up or down
+	Levels     *int     `yaml:"levels"`      // parent directories consulted
+	Depth      *int     `yaml:"depth"`       // subdirectory levels voting below each directory consulted
+	MinSupport *float32 `yaml:"min_support"` // decayed votes needed to reclassify
+	Dominance  *float32 `yaml:"dominance"`   // share of votes the winning role needs
+	Boost      *float32 `yaml:"boost"`       // confidence added to reclassified files
 }
 
 func DefaultConfig() *Config {
@@ -49,6 +125,14 @@ func Load(path string) (*Config, error) {
 }
 
 func LoadFromDir(dir string) (*Config, error) {
+	if path := Find(dir); path != "" {
+		return Load(path)
+	}
+	return DefaultConfig(), nil
+}
+
+// Find returns the path of the config file in dir, or "" if there is none
+func Find(dir string) string {
 	candidates := []string{
 		filepath.Join(dir, "aloc.yaml"),
 		filepath.Join(dir, "aloc.yml"),
@@ -58,9 +142,8 @@ func LoadFromDir(dir string) (*Config, error) {
 
 	for _, path := range candidates {
 		if _, err := os.Stat(path); err == nil {
-			return Load(path)
+			return path
 		}
 	}
-
-	return DefaultConfig(), nil
+	return ""
 }
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/modern-tooling/aloc/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+// Override pins the role of the files matching a glob. Entries are checked
+// by descending priority, then in file order; the first match wins.
+type Override struct {
+	Pattern    string        `yaml:"pattern"`
+	Role       model.Role    `yaml:"role"`
+	SubRole    model.SubRole `yaml:"sub_role,omitempty"`   // one of the role's sub-roles: unit, e2e, ... for test, flyway, ... for migrations
+	Language   string        `yaml:"language,omitempty"`   // count and report matching files as this language
+	Confidence float32       `yaml:"confidence,omitempty"` // 0-1; unset keeps the usual confidence
+	Priority   int           `yaml:"priority,omitempty"`   // higher is checked first
+}
+
+// Overrides is the ordered overrides list. The older form, a mapping from
+// role to patterns, is still accepted and keeps the order of the file.
+type Overrides []Override
+
+func (o *Overrides) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		var list []Override
+		if err := node.Decode(&list); err != nil {
+			return err
+		}
+		*o = list
+		return nil
+	}
+
+	var list Overrides
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		var patterns []string
+		if err := node.Content[i+1].Decode(&patterns); err != nil {
+			return err
+		}
+		for _, p := range patterns {
+			list = append(list, Override{Pattern: p, Role: model.Role(node.Content[i].Value)})
+		}
+	}
+	*o = list
+	return nil
+}
+
+// OverridesSnippet renders overrides as a ready-to-paste aloc.yaml section
+func OverridesSnippet(overrides []Override) string {
+	return string(marshal(&struct {
+		Overrides []Override `yaml:"overrides"`
+	}{overrides}))
+}
+
+// AddOverrides appends overrides to the config file at path, creating it if
+// needed. Existing settings and comments are kept, and patterns already
+// listed for the same role are not repeated. A file using the older
+// role-to-patterns form is extended in that form, unless an override sets a
+// sub-role, which that form cannot hold: the section is then rewritten as a
+// list.
+func AddOverrides(path string, overrides []Override) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var doc yaml.Node
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s: top level is not a mapping", path)
+	}
+
+	section := mappingValue(root, "overrides", yaml.SequenceNode)
+	var existing Overrides
+	if err := section.Decode(&existing); err != nil {
+		return fmt.Errorf("%s: overrides: %w", path, err)
+	}
+	if section.Kind == yaml.MappingNode && slices.ContainsFunc(overrides, func(o Override) bool { return o.SubRole != "" }) {
+		*section = yaml.Node{Kind: yaml.SequenceNode}
+		for _, o := range existing {
+			var entry yaml.Node
+			if err := entry.Encode(o); err != nil {
+				return err
+			}
+			section.Content = append(section.Content, &entry)
+		}
+	}
+	for _, o := range overrides {
+		if containsOverride(existing, o) {
+			continue
+		}
+		existing = append(existing, o)
+		if section.Kind == yaml.MappingNode {
+			list := mappingValue(section, string(o.Role), yaml.SequenceNode)
+			list.Content = append(list.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: o.Pattern})
+			continue
+		}
+		var entry yaml.Node
+		if err := entry.Encode(o); err != nil {
+			return err
+		}
+		section.Content = append(section.Content, &entry)
+	}
+
+	return os.WriteFile(path, marshal(&doc), 0644)
+}
+
+func containsOverride(list []Override, o Override) bool {
+	for _, e := range list {
+		if e.Pattern == o.Pattern && e.Role == o.Role {
+			return true
+		}
+	}
+	return false
+}
+
+// mappingValue returns the value for key in a mapping node, adding an empty
+// node of kind if the key is missing or null
+func mappingValue(m *yaml.Node, key string, kind yaml.Kind) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value != key {
+			continue
+		}
+		v := m.Content[i+1]
+		if v.Kind == yaml.ScalarNode && v.Tag == "!!null" {
+			*v = yaml.Node{Kind: kind}
+		}
+		return v
+	}
+	v := &yaml.Node{Kind: kind}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, v)
+	return v
+}
+
+func marshal(v any) []byte {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	enc.Encode(v)
+	enc.Close()
+	return buf.Bytes()
+}
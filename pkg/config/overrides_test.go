@@ -0,0 +1,180 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/modern-tooling/aloc/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+func TestOverrides_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want Overrides
+	}{
+		{
+			name: "list",
+			yaml: `overrides:
+  - pattern: e2e/**
+    role: test
+    sub_role: e2e
+    priority: 10
+  - pattern: docs/*
+    role: docs
+    confidence: 0.8
+`,
+			want: Overrides{
+				{Pattern: "e2e/**", Role: model.RoleTest, SubRole: model.TestE2E, Priority: 10},
+				{Pattern: "docs/*", Role: model.RoleDocs, Confidence: 0.8},
+			},
+		},
+		{
+			name: "legacy role to patterns",
+			yaml: `overrides:
+  test:
+    - a/**
+    - b/**
+  docs: [d/*]
+`,
+			want: Overrides{
+				{Pattern: "a/**", Role: model.RoleTest},
+				{Pattern: "b/**", Role: model.RoleTest},
+				{Pattern: "d/*", Role: model.RoleDocs},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Config
+			if err := yaml.Unmarshal([]byte(tt.yaml), &cfg); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(cfg.Overrides, tt.want) {
+				t.Errorf("Overrides = %+v, want %+v", cfg.Overrides, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddOverrides(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		add      []Override
+		want     Overrides
+		legacy   bool // the file keeps the role-to-patterns form
+	}{
+		{
+			name: "list",
+			existing: `# project settings
+exclude:
+  - build/** # generated
+overrides:
+  # keep fixtures out of test
+  - pattern: fixtures/**
+    role: core
+    priority: 5
+`,
+			add: []Override{
+				{Pattern: "fixtures/**", Role: model.RoleCore},
+				{Pattern: "e2e/**", Role: model.RoleTest, SubRole: model.TestE2E},
+			},
+			want: Overrides{
+				{Pattern: "fixtures/**", Role: model.RoleCore, Priority: 5},
+				{Pattern: "e2e/**", Role: model.RoleTest, SubRole: model.TestE2E},
+			},
+		},
+		{
+			name: "legacy extended in place",
+			existing: `# project settings
+overrides:
+  test:
+    - a/**
+`,
+			add: []Override{
+				{Pattern: "a/**", Role: model.RoleTest},
+				{Pattern: "d/*", Role: model.RoleDocs},
+			},
+			want: Overrides{
+				{Pattern: "a/**", Role: model.RoleTest},
+				{Pattern: "d/*", Role: model.RoleDocs},
+			},
+			legacy: true,
+		},
+		{
+			name: "legacy rewritten for a sub-role",
+			existing: `# project settings
+overrides:
+  test:
+    - a/**
+  docs: [d/*]
+`,
+			add: []Override{
+				{Pattern: "d/*", Role: model.RoleDocs},
+				{Pattern: "e2e/**", Role: model.RoleTest, SubRole: model.TestE2E},
+			},
+			want: Overrides{
+				{Pattern: "a/**", Role: model.RoleTest},
+				{Pattern: "d/*", Role: model.RoleDocs},
+				{Pattern: "e2e/**", Role: model.RoleTest, SubRole: model.TestE2E},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "aloc.yaml")
+			if err := os.WriteFile(path, []byte(tt.existing), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := AddOverrides(path, tt.add); err != nil {
+				t.Fatalf("AddOverrides: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load: %v\n%s", err, data)
+			}
+			if !slices.Equal(cfg.Overrides, tt.want) {
+				t.Errorf("Overrides = %+v, want %+v\n%s", cfg.Overrides, tt.want, data)
+			}
+			for _, line := range strings.Split(tt.existing, "\n") {
+				if i := strings.Index(line, "#"); i >= 0 && !strings.Contains(string(data), line[i:]) {
+					t.Errorf("comment %q lost:\n%s", line[i:], data)
+				}
+			}
+			var node yaml.Node
+			if err := yaml.Unmarshal(data, &node); err != nil {
+				t.Fatal(err)
+			}
+			if got := mappingValue(node.Content[0], "overrides", yaml.SequenceNode).Kind == yaml.MappingNode; got != tt.legacy {
+				t.Errorf("legacy form = %v, want %v:\n%s", got, tt.legacy, data)
+			}
+		})
+	}
+}
+
+func TestAddOverrides_NewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "aloc.yaml")
+	add := []Override{{Pattern: "e2e/**", Role: model.RoleTest, SubRole: model.TestE2E}}
+
+	if err := AddOverrides(path, add); err != nil {
+		t.Fatalf("AddOverrides: %v", err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(cfg.Overrides, Overrides(add)) {
+		t.Errorf("Overrides = %+v, want %+v", cfg.Overrides, add)
+	}
+}